@@ -24,6 +24,7 @@ import (
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/integrations/githubapp"
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/integrations/klipy"
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/docs"
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/previews"
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
 	"github.com/danielgtaylor/huma/v2"
@@ -80,6 +81,12 @@ func startAPI(cfg *config.Config) {
 		pluginCacheFile = filepath.Join(cfg.CacheDir, "plugins.json")
 		themeCacheFile = filepath.Join(cfg.CacheDir, "themes.json")
 	}
+	if cfg.WCAGRulesDir != "" {
+		if err := registry.LoadWCAGRules(cfg.WCAGRulesDir); err != nil {
+			log.Error("Failed to load WCAG rules; using built-in rules", "err", err)
+		}
+	}
+
 	pluginCache := registry.NewCache(cfg.GithubToken, pluginCacheFile)
 	themeCache := registry.NewThemeCache(cfg.GithubToken, themeCacheFile)
 
@@ -91,6 +98,8 @@ func startAPI(cfg *config.Config) {
 		} else {
 			previewGen = gen
 			pluginCache.SetPreviewSyncer(gen)
+			themeCache.SetPreviewSyncer(gen)
+			gen.Start(ctx)
 			log.Info("Preview generator initialized")
 		}
 	}
@@ -121,6 +130,7 @@ func startAPI(cfg *config.Config) {
 	}
 
 	gifRateLimiter := middleware.NewRateLimiter(100.0/60.0, 100)
+	themeGenerateRateLimiter := middleware.NewRateLimiter(10.0/60.0, 5)
 
 	r := chi.NewRouter()
 
@@ -162,10 +172,37 @@ func startAPI(cfg *config.Config) {
 
 	if previewGen != nil {
 		servePreview := func(w http.ResponseWriter, r *http.Request) {
-			previews_handler.ServePreview(previewGen.Store(), chi.URLParam(r, "pluginId"), w, r)
+			previews_handler.ServePreview(previewGen, pluginCache, themeCache, chi.URLParam(r, "pluginId"), w, r)
 		}
 		r.Get("/previews/{pluginId}", servePreview)
 		r.Head("/previews/{pluginId}", servePreview)
+
+		serveThemePreview := func(w http.ResponseWriter, r *http.Request) {
+			previews_handler.ServeThemePreview(previewGen.Store(), chi.URLParam(r, "themeId"), w, r)
+		}
+		r.Get("/previews/themes/{themeId}", serveThemePreview)
+		r.Head("/previews/themes/{themeId}", serveThemePreview)
+
+		serveThemeSocialCard := func(w http.ResponseWriter, r *http.Request) {
+			previews_handler.ServeThemeSocialCard(previewGen, themeCache, chi.URLParam(r, "themeId"), w, r)
+		}
+		r.Get("/og/themes/{themeId}", serveThemeSocialCard)
+		r.Head("/og/themes/{themeId}", serveThemeSocialCard)
+
+		serveAuthorSocialCard := func(w http.ResponseWriter, r *http.Request) {
+			previews_handler.ServeAuthorSocialCard(previewGen, pluginCache, themeCache, chi.URLParam(r, "handle"), w, r)
+		}
+		r.Get("/og/authors/{handle}", serveAuthorSocialCard)
+		r.Head("/og/authors/{handle}", serveAuthorSocialCard)
+
+		if cfg.DocsDir != "" {
+			docsSource := docs.NewSource(cfg.DocsDir)
+			serveDocsSocialCard := func(w http.ResponseWriter, r *http.Request) {
+				previews_handler.ServeDocsSocialCard(previewGen, docsSource, w, r)
+			}
+			r.Get("/og/docs", serveDocsSocialCard)
+			r.Head("/og/docs", serveDocsSocialCard)
+		}
 	}
 
 	r.Get("/ready", func(w http.ResponseWriter, r *http.Request) {
@@ -216,6 +253,14 @@ func startAPI(cfg *config.Config) {
 		})
 		themes_handler.RegisterHandlers(srvImpl, themesGroup)
 
+		themeGenerateGroup := huma.NewGroup(api, "/themes")
+		themeGenerateGroup.UseModifier(func(op *huma.Operation, next func(*huma.Operation)) {
+			op.Tags = []string{"Themes"}
+			next(op)
+		})
+		themeGenerateGroup.UseMiddleware(themeGenerateRateLimiter.HumaMiddleware)
+		themes_handler.RegisterGenerateHandlers(srvImpl, themeGenerateGroup)
+
 		gifsGroup := huma.NewGroup(api, "/gifs")
 		gifsGroup.UseSimpleModifier(func(op *huma.Operation) {
 			op.Tags = []string{"GIFs"}
@@ -236,6 +281,14 @@ func startAPI(cfg *config.Config) {
 		})
 		poeditor.RegisterHandlers(cfg.PoeditorCallbackSecret, cfg.DiscordWebhookURL, poeditorGroup)
 
+		if previewGen != nil {
+			adminPreviewsGroup := huma.NewGroup(api, "/admin/previews")
+			adminPreviewsGroup.UseSimpleModifier(func(op *huma.Operation) {
+				op.Tags = []string{"Admin"}
+			})
+			previews_handler.RegisterAdminHandlers(previewGen, pluginCache, cfg.AdminToken, adminPreviewsGroup)
+		}
+
 		uploadsGroup := huma.NewGroup(api, "/uploads")
 		uploadsGroup.UseSimpleModifier(func(op *huma.Operation) {
 			op.Tags = []string{"Uploads"}
@@ -269,6 +322,7 @@ func startAPI(cfg *config.Config) {
 			Cache:      pluginCache,
 			Moderator:  moderator,
 			Authors:    pluginCache,
+			Themes:     themeCache,
 		}, webhooksGroup)
 	})
 
@@ -293,6 +347,11 @@ func startAPI(cfg *config.Config) {
 				if err := pluginCache.Refresh(ctx); err != nil {
 					log.Error("Failed to refresh plugin cache", "err", err)
 				}
+				if cfg.WCAGRulesDir != "" {
+					if err := registry.LoadWCAGRules(cfg.WCAGRulesDir); err != nil {
+						log.Error("Failed to reload WCAG rules; keeping current rules", "err", err)
+					}
+				}
 				if err := themeCache.Refresh(ctx); err != nil {
 					log.Error("Failed to refresh theme cache", "err", err)
 				}
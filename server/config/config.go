@@ -20,9 +20,12 @@ type Config struct {
 	OwnersTeam             string
 	DiscordWebhookURL      string
 	UploadToken            string
+	AdminToken             string
 	UploadDir              string
 	CacheDir               string
 	PublicBaseURL          string
+	WCAGRulesDir           string
+	DocsDir                string
 }
 
 func NewConfig() *Config {
@@ -61,6 +64,7 @@ func NewConfig() *Config {
 
 	discordWebhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
 	uploadToken := os.Getenv("UPLOAD_TOKEN")
+	adminToken := os.Getenv("ADMIN_TOKEN")
 
 	uploadDir := os.Getenv("UPLOAD_DIR")
 	if uploadDir == "" {
@@ -74,6 +78,9 @@ func NewConfig() *Config {
 		publicBaseURL = "https://api.danklinux.com"
 	}
 
+	wcagRulesDir := os.Getenv("WCAG_RULES_DIR")
+	docsDir := os.Getenv("DOCS_DIR")
+
 	return &Config{
 		Port:                   port,
 		Environment:            env,
@@ -89,8 +96,11 @@ func NewConfig() *Config {
 		OwnersTeam:             ownersTeam,
 		DiscordWebhookURL:      discordWebhookURL,
 		UploadToken:            uploadToken,
+		AdminToken:             adminToken,
 		UploadDir:              uploadDir,
 		CacheDir:               cacheDir,
 		PublicBaseURL:          publicBaseURL,
+		WCAGRulesDir:           wcagRulesDir,
+		DocsDir:                docsDir,
 	}
 }
@@ -14,9 +14,9 @@ require (
 	github.com/google/go-github/v69 v69.2.0
 	github.com/joho/godotenv v1.5.1
 	github.com/latte-soft/discord-webhooks-go v0.1.4
-	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
 	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef
 	golang.org/x/image v0.44.0
+	golang.org/x/text v0.40.0
 )
 
 require (
@@ -42,5 +42,4 @@ require (
 	golang.org/x/exp v0.0.0-20260218203240-3dfff04db8fa // indirect
 	golang.org/x/net v0.50.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
-	golang.org/x/text v0.40.0 // indirect
 )
@@ -3,3 +3,5 @@ package plugins_handler
 import "github.com/danielgtaylor/huma/v2"
 
 var ErrCacheNotReady = huma.Error503ServiceUnavailable("plugin cache is warming up")
+
+var ErrPluginNotFound = huma.Error404NotFound("plugin not found")
@@ -0,0 +1,38 @@
+package plugins_handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+type GetPluginInput struct {
+	PluginID string `path:"pluginId" maxLength:"64" doc:"Plugin id; former ids redirect to the current one"`
+}
+
+type GetPluginResponse struct {
+	Status   int
+	Location string `header:"Location"`
+	Body     models.Plugin
+}
+
+func (self *HandlerGroup) GetPlugin(ctx context.Context, input *GetPluginInput) (*GetPluginResponse, error) {
+	if self.srv.PluginCache == nil || !self.srv.PluginCache.IsReady() {
+		return nil, ErrCacheNotReady
+	}
+
+	plugin, ok := self.srv.PluginCache.PluginByID(input.PluginID)
+	if !ok {
+		return nil, ErrPluginNotFound
+	}
+
+	resp := &GetPluginResponse{Status: http.StatusOK, Body: plugin}
+	// Renamed plugins answer on their old id with a permanent redirect, carrying the
+	// current entry as well so clients that don't follow redirects still get data.
+	if plugin.ID != input.PluginID {
+		resp.Status = http.StatusMovedPermanently
+		resp.Location = "/plugins/" + plugin.ID
+	}
+	return resp, nil
+}
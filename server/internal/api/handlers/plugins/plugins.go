@@ -30,4 +30,16 @@ func RegisterHandlers(server *server.Server, grp *huma.Group) {
 		},
 		handlers.GetPlugins,
 	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "get-plugin",
+			Summary:     "Get Plugin",
+			Description: "Get a single plugin by id. Former ids of renamed plugins redirect to the current one.",
+			Path:        "/{pluginId}",
+			Method:      http.MethodGet,
+		},
+		handlers.GetPlugin,
+	)
 }
@@ -0,0 +1,143 @@
+package previews_handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/previews"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// PluginLister lists every plugin in the registry, for invalidating them all.
+type PluginLister interface {
+	PluginByID(id string) (models.Plugin, bool)
+	GetPlugins() []models.Plugin
+}
+
+type AdminHandlerGroup struct {
+	gen     *previews.Generator
+	plugins PluginLister
+	token   string
+}
+
+// RegisterAdminHandlers adds the operations for inspecting the preview queue and for
+// listing and invalidating stored previews. Each requires Authorization: Bearer
+// <token>, and all of them answer 503 while no token is configured.
+func RegisterAdminHandlers(gen *previews.Generator, plugins PluginLister, token string, grp *huma.Group) {
+	h := &AdminHandlerGroup{
+		gen:     gen,
+		plugins: plugins,
+		token:   token,
+	}
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-stored-previews",
+		Summary:     "List Stored Previews",
+		Description: "List every entry in the preview store: previews, renditions, palette variants and kept source images. Requires Authorization: Bearer <token>.",
+		Path:        "",
+		Method:      http.MethodGet,
+	}, h.ListEntries)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "invalidate-previews",
+		Summary:     "Invalidate All Plugin Previews",
+		Description: "Drop every plugin's stored preview and queue it to be rendered again. Requires Authorization: Bearer <token>.",
+		Path:        "",
+		Method:      http.MethodDelete,
+	}, h.InvalidateAll)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "invalidate-preview",
+		Summary:     "Invalidate Plugin Preview",
+		Description: "Drop one plugin's stored preview and queue it to be rendered again. Requires Authorization: Bearer <token>.",
+		Path:        "/{pluginId}",
+		Method:      http.MethodDelete,
+	}, h.InvalidatePlugin)
+
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-preview-queue",
+		Summary:     "Get Preview Queue",
+		Description: "List plugin preview jobs waiting to run, running, or failed and waiting to be retried with backoff. Requires Authorization: Bearer <token>.",
+		Path:        "/queue/jobs",
+		Method:      http.MethodGet,
+	}, h.GetQueue)
+}
+
+type AdminInput struct {
+	Authorization string `header:"Authorization" required:"true" doc:"Bearer <token>"`
+}
+
+type InvalidatePluginInput struct {
+	AdminInput
+	PluginID string `path:"pluginId" maxLength:"64" doc:"Plugin id"`
+}
+
+type StoredPreviewsResponse struct {
+	Body struct {
+		Entries   []previews.EntryInfo `json:"entries"`
+		TotalSize int64                `json:"totalSize" doc:"Bytes used by the entries' files"`
+	}
+}
+
+type InvalidateResponse struct {
+	Body struct {
+		Queued  int `json:"queued" doc:"Plugins queued to be rendered again"`
+		Removed int `json:"removed" doc:"Plugins that had a stored preview"`
+	}
+}
+
+func (h *AdminHandlerGroup) authorize(header string) error {
+	if h.token == "" {
+		return huma.Error503ServiceUnavailable("admin endpoints not configured")
+	}
+	provided := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) != 1 {
+		return huma.Error401Unauthorized("unauthorized")
+	}
+	return nil
+}
+
+func (h *AdminHandlerGroup) ListEntries(_ context.Context, input *AdminInput) (*StoredPreviewsResponse, error) {
+	if err := h.authorize(input.Authorization); err != nil {
+		return nil, err
+	}
+
+	resp := &StoredPreviewsResponse{}
+	resp.Body.Entries = h.gen.Store().Entries()
+	for _, entry := range resp.Body.Entries {
+		resp.Body.TotalSize += max(entry.Size, 0)
+	}
+	return resp, nil
+}
+
+func (h *AdminHandlerGroup) InvalidateAll(_ context.Context, input *AdminInput) (*InvalidateResponse, error) {
+	if err := h.authorize(input.Authorization); err != nil {
+		return nil, err
+	}
+	return h.invalidate(h.plugins.GetPlugins())
+}
+
+func (h *AdminHandlerGroup) InvalidatePlugin(_ context.Context, input *InvalidatePluginInput) (*InvalidateResponse, error) {
+	if err := h.authorize(input.Authorization); err != nil {
+		return nil, err
+	}
+	plugin, ok := h.plugins.PluginByID(input.PluginID)
+	if !ok {
+		return nil, huma.Error404NotFound("plugin not found")
+	}
+	return h.invalidate([]models.Plugin{plugin})
+}
+
+func (h *AdminHandlerGroup) invalidate(plugins []models.Plugin) (*InvalidateResponse, error) {
+	removed, err := h.gen.Invalidate(plugins)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("failed to invalidate previews")
+	}
+	resp := &InvalidateResponse{}
+	resp.Body.Queued = len(plugins)
+	resp.Body.Removed = removed
+	return resp, nil
+}
@@ -0,0 +1,105 @@
+package previews_handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/docs"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/previews"
+)
+
+// handlePattern matches GitHub user and organization names.
+var handlePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]{0,38}$`)
+
+// PluginAuthorLookup finds the plugins published under a GitHub handle.
+type PluginAuthorLookup interface {
+	PluginsByAuthor(handle string) []models.Plugin
+}
+
+// ThemeAuthorLookup finds the themes submitted by a GitHub handle.
+type ThemeAuthorLookup interface {
+	ThemesByAuthor(handle string) []models.Theme
+}
+
+// DocsLookup reads a docs page's title and description.
+type DocsLookup interface {
+	Page(path string) (docs.Page, error)
+}
+
+func ServeThemeSocialCard(gen *previews.Generator, themes ThemeLookup, themeID string, w http.ResponseWriter, r *http.Request) {
+	if !previews.ThemeIDPattern.MatchString(themeID) || themes == nil {
+		http.NotFound(w, r)
+		return
+	}
+	theme, ok := themes.ThemeByID(themeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	store := gen.Store()
+	key, _ := gen.ThemeSocialCard(theme)
+	serveEntry(store, key, store.ThemePlaceholderPath(), w, r)
+}
+
+// ServeAuthorSocialCard draws the card for whoever publishes under handle, in any
+// case. Handles with nothing in the registry are not found, so only real authors get
+// a card stored.
+func ServeAuthorSocialCard(gen *previews.Generator, plugins PluginAuthorLookup, themes ThemeAuthorLookup, handle string, w http.ResponseWriter, r *http.Request) {
+	if !handlePattern.MatchString(handle) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var profile previews.AuthorProfile
+	if themes != nil {
+		for _, t := range themes.ThemesByAuthor(handle) {
+			if profile.Handle == "" {
+				profile.Handle = t.Author
+			}
+			profile.Themes = append(profile.Themes, t.Name)
+		}
+	}
+	if plugins != nil {
+		for _, p := range plugins.PluginsByAuthor(handle) {
+			if profile.Handle == "" && strings.EqualFold(p.Author, handle) {
+				profile.Handle = p.Author
+			}
+			profile.Plugins = append(profile.Plugins, p.Name)
+		}
+	}
+	if len(profile.Plugins) == 0 && len(profile.Themes) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	// A handle only known as a repo owner is drawn as the URL spelled it, lowercased
+	// so every spelling shares one card.
+	if profile.Handle == "" {
+		profile.Handle = strings.ToLower(handle)
+	}
+
+	store := gen.Store()
+	key, _ := gen.AuthorSocialCard(profile)
+	serveEntry(store, key, store.PlaceholderPath(), w, r)
+}
+
+// ServeDocsSocialCard draws the card for the docs page ?path= names, as the site links
+// it: "dankmaterialshell/overview", "/docs/dgop/usage" or "danksearch/index.mdx".
+func ServeDocsSocialCard(gen *previews.Generator, pages DocsLookup, w http.ResponseWriter, r *http.Request) {
+	page, err := pages.Page(r.URL.Query().Get("path"))
+	if errors.Is(err, docs.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "docs page could not be read", http.StatusInternalServerError)
+		return
+	}
+
+	store := gen.Store()
+	key, _ := gen.DocsSocialCard(page)
+	serveEntry(store, key, store.PlaceholderPath(), w, r)
+}
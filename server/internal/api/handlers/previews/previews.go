@@ -1,27 +1,166 @@
 package previews_handler
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/previews"
 )
 
 var pluginIDPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,63}$`)
 
-func ServePreview(store *previews.Store, pluginID string, w http.ResponseWriter, r *http.Request) {
+// IDResolver maps a former plugin id to the plugin's current one.
+type IDResolver interface {
+	ResolveID(id string) (string, bool)
+}
+
+// PluginLookup finds the plugin a palette variant is drawn for.
+type PluginLookup interface {
+	IDResolver
+	PluginByID(id string) (models.Plugin, bool)
+}
+
+// ThemeLookup finds the registry theme a ?theme= variant takes its colors from.
+type ThemeLookup interface {
+	ThemeByID(id string) (models.Theme, bool)
+}
+
+func ServePreview(gen *previews.Generator, plugins PluginLookup, themes ThemeLookup, pluginID string, w http.ResponseWriter, r *http.Request) {
 	if !pluginIDPattern.MatchString(pluginID) {
 		http.NotFound(w, r)
 		return
 	}
 
-	path, etag, ok := store.Lookup(pluginID)
+	if plugins != nil {
+		if current, ok := plugins.ResolveID(pluginID); ok && current != pluginID {
+			redirectToID(w, r, pluginID, current)
+			return
+		}
+	}
+
+	variant, ok, err := parseVariant(themes, r.URL.Query())
+	if errors.Is(err, errUnknownTheme) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	animated, err := parseAnimated(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	store := gen.Store()
+	if animated {
+		// A screenshot that does not move, or whose animation is still being made, is
+		// served as the static preview.
+		w.Header().Set("Vary", "Accept")
+		if path, etag, found := store.LookupAnimation(pluginID, acceptsWebP(r.Header.Get("Accept"))); found {
+			serveFile(path, etag, w, r)
+			return
+		}
+	}
+
+	key := pluginID
+	if ok && plugins != nil {
+		// A variant that cannot be drawn falls back to the stored preview rather than
+		// failing an embed over its colors.
+		if p, found := plugins.PluginByID(pluginID); found {
+			if variantKey, rendered := gen.PluginVariant(r.Context(), p, variant); rendered {
+				key = variantKey
+			}
+		}
+	}
+
+	serveEntry(store, key, store.PlaceholderPath(), w, r)
+}
+
+var errUnknownTheme = errors.New("theme not found")
+
+// parseAnimated reads ?animated=1. Animations are made once, at full width in the
+// default palette, so a width or palette alongside it is refused.
+func parseAnimated(query url.Values) (bool, error) {
+	raw := query.Get("animated")
+	if raw == "" {
+		return false, nil
+	}
+	animated, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New("animated must be 1 or 0")
+	}
+	if animated && (query.Has("w") || query.Has("mode") || query.Has("theme")) {
+		return false, errors.New("animated previews come at full width in the default palette")
+	}
+	return animated, nil
+}
+
+// parseVariant reads the palette a plugin preview is asked for: ?mode=light for the
+// docs site's light theme, ?theme= for a registry theme's colors in its default mode
+// or the one ?mode= names. Neither, or ?mode=dark alone, is the stored preview.
+func parseVariant(themes ThemeLookup, query url.Values) (previews.Variant, bool, error) {
+	mode := query.Get("mode")
+	if mode != "" && mode != "dark" && mode != "light" {
+		return previews.Variant{}, false, errors.New("mode must be dark or light")
+	}
+
+	themeID := query.Get("theme")
+	if themeID == "" {
+		if mode == "light" {
+			return previews.LightVariant(), true, nil
+		}
+		return previews.Variant{}, false, nil
+	}
+
+	if !previews.ThemeIDPattern.MatchString(themeID) || themes == nil {
+		return previews.Variant{}, false, errUnknownTheme
+	}
+	theme, ok := themes.ThemeByID(themeID)
+	if !ok {
+		return previews.Variant{}, false, errUnknownTheme
+	}
+	variant, err := previews.ThemeVariant(theme, mode)
+	if err != nil {
+		return previews.Variant{}, false, err
+	}
+	return variant, true, nil
+}
+
+func ServeThemePreview(store *previews.Store, themeID string, w http.ResponseWriter, r *http.Request) {
+	if !previews.ThemeIDPattern.MatchString(themeID) {
+		http.NotFound(w, r)
+		return
+	}
+
+	serveEntry(store, previews.ThemeKey(themeID), store.ThemePlaceholderPath(), w, r)
+}
+
+func serveEntry(store *previews.Store, key, placeholder string, w http.ResponseWriter, r *http.Request) {
+	rendition, ok := parseRendition(r)
+	if !ok {
+		http.Error(w, "w must be one of 320, 480 or 960", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Vary", "Accept")
+
+	path, etag, ok := store.LookupRendition(key, rendition)
 	if !ok {
-		servePlaceholder(store, w, r)
+		servePlaceholder(placeholder, w, r)
 		return
 	}
+	serveFile(path, etag, w, r)
+}
 
+func serveFile(path, etag string, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Cache-Control", "public, max-age=3600")
 	quoted := `"` + etag + `"`
@@ -34,10 +173,46 @@ func ServePreview(store *previews.Store, pluginID string, w http.ResponseWriter,
 	http.ServeFile(w, r, path)
 }
 
-func servePlaceholder(store *previews.Store, w http.ResponseWriter, r *http.Request) {
+// parseRendition reads the requested width from ?w= and whether the client takes WebP
+// from its Accept header.
+func parseRendition(r *http.Request) (previews.Rendition, bool) {
+	var rendition previews.Rendition
+	if raw := r.URL.Query().Get("w"); raw != "" {
+		width, err := strconv.Atoi(raw)
+		if err != nil || !slices.Contains(previews.RenditionWidths, width) {
+			return previews.Rendition{}, false
+		}
+		rendition.Width = width
+	}
+	rendition.WebP = acceptsWebP(r.Header.Get("Accept"))
+	return rendition, true
+}
+
+func acceptsWebP(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaType), "image/webp") {
+			continue
+		}
+		q := strings.TrimSpace(params)
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
+
+// redirectToID points a renamed plugin's old preview URL at the current one, keeping
+// the rest of the path and query intact.
+func redirectToID(w http.ResponseWriter, r *http.Request, oldID, newID string) {
+	target := *r.URL
+	target.Path = strings.TrimSuffix(r.URL.Path, oldID) + newID
+	target.RawPath = ""
+	http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+}
+
+func servePlaceholder(path string, w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Cache-Control", "public, max-age=60")
-	http.ServeFile(w, r, store.PlaceholderPath())
+	http.ServeFile(w, r, path)
 }
 
 func containsETag(header, quoted string) bool {
@@ -0,0 +1,20 @@
+package previews_handler
+
+import (
+	"context"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/previews"
+)
+
+type PreviewQueueResponse struct {
+	Body previews.QueueStatus
+}
+
+// GetQueue lists the preview queue. Jobs carry the raw error of their last attempt,
+// which can name internal hosts and paths, so the queue is only shown to admins.
+func (h *AdminHandlerGroup) GetQueue(_ context.Context, input *AdminInput) (*PreviewQueueResponse, error) {
+	if err := h.authorize(input.Authorization); err != nil {
+		return nil, err
+	}
+	return &PreviewQueueResponse{Body: h.gen.Queue()}, nil
+}
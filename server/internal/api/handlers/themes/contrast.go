@@ -0,0 +1,75 @@
+package themes_handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type ContrastMatrixInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+	Mode    string `query:"mode" enum:"dark,light" default:"dark" doc:"Color mode to resolve"`
+	Variant string `query:"variant" doc:"Variant option id for option themes; defaults to the theme's default"`
+	Flavor  string `query:"flavor" doc:"Flavor id for multi-variant themes; defaults to the mode's default flavor"`
+	Accent  string `query:"accent" doc:"Accent id for multi-variant themes; defaults to the mode's default accent"`
+	Rules   string `query:"rules" maxLength:"16" doc:"DMS version whose WCAG rule set grades the checked pairs; defaults to the newest"`
+}
+
+type ContrastMatrixResponse struct {
+	Body struct {
+		ID      string `json:"id"`
+		Mode    string `json:"mode"`
+		Variant string `json:"variant,omitempty"`
+		Flavor  string `json:"flavor,omitempty"`
+		Accent  string `json:"accent,omitempty"`
+		*models.ThemeContrastMatrix
+	}
+}
+
+func (h *HandlerGroup) ContrastMatrix(ctx context.Context, input *ContrastMatrixInput) (*ContrastMatrixResponse, error) {
+	rules, err := lookupRules(input.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := registry.ResolveScheme(&theme, registry.ThemeSelection{
+		Mode:    input.Mode,
+		Variant: input.Variant,
+		Flavor:  input.Flavor,
+		Accent:  input.Accent,
+	})
+	if errors.Is(err, registry.ErrInvalidSelection) {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ContrastMatrixResponse{}
+	resp.Body.ID = theme.ID
+	resp.Body.Mode = resolved.Mode
+	resp.Body.Variant = resolved.Variant
+	resp.Body.Flavor = resolved.Flavor
+	resp.Body.Accent = resolved.Accent
+	resp.Body.ThemeContrastMatrix = rules.ContrastMatrix(resolved.Colors)
+	return resp, nil
+}
+
+func lookupRules(version string) (*registry.WCAGRules, error) {
+	rules, ok := registry.WCAGRulesFor(version)
+	if !ok {
+		return nil, huma.Error400BadRequest(fmt.Sprintf("no WCAG rules for DMS %q; available: %s",
+			version, strings.Join(registry.WCAGRuleVersions(), ", ")))
+	}
+	return rules, nil
+}
@@ -0,0 +1,66 @@
+package themes_handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/themeimport"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const maxConvertBytes = 64 * 1024
+
+type ConvertThemeInput struct {
+	ID      string `query:"id" maxLength:"64" pattern:"^[a-z0-9][a-z0-9-]*$" doc:"Theme id; derived from the name when empty"`
+	Name    string `query:"name" maxLength:"100" doc:"Theme name; taken from the scheme when empty"`
+	Author  string `query:"author" maxLength:"100" doc:"Theme author; taken from the scheme when empty"`
+	Accent  string `query:"accent" enum:"red,green,yellow,blue,magenta,cyan" default:"blue" doc:"Terminal color to use as primary"`
+	RawBody []byte `contentType:"text/plain" doc:"base16/base24 YAML, or a kitty, foot, alacritty, ghostty or Xresources color config"`
+}
+
+type ConvertThemeResponse struct {
+	Body struct {
+		Theme        themeimport.Document `json:"theme"`
+		WCAG         *models.ThemeWCAG    `json:"wcag,omitempty"`
+		SourceFormat string               `json:"sourceFormat"`
+		SourceMode   string               `json:"sourceMode"`
+		Derived      []string             `json:"derived"`
+	}
+}
+
+func (h *HandlerGroup) ConvertTheme(ctx context.Context, input *ConvertThemeInput) (*ConvertThemeResponse, error) {
+	if len(input.RawBody) == 0 {
+		return nil, huma.Error400BadRequest("empty body")
+	}
+
+	src, err := themeimport.Parse(input.RawBody)
+	if errors.Is(err, themeimport.ErrUnrecognized) {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	result, err := themeimport.Convert(src, themeimport.Options{
+		ID:     input.ID,
+		Name:   input.Name,
+		Author: input.Author,
+		Accent: input.Accent,
+	})
+	if err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+
+	resp := &ConvertThemeResponse{}
+	resp.Body.Theme = result.Theme
+	resp.Body.WCAG = registry.ComputeThemeWCAG(&models.Theme{
+		Dark:  result.Theme.Dark,
+		Light: result.Theme.Light,
+	})
+	resp.Body.SourceFormat = src.Format
+	resp.Body.SourceMode = result.SourceMode
+	resp.Body.Derived = result.Derived
+	return resp, nil
+}
@@ -3,3 +3,5 @@ package themes_handler
 import "github.com/danielgtaylor/huma/v2"
 
 var ErrCacheNotReady = huma.Error503ServiceUnavailable("theme cache is warming up")
+
+var ErrThemeNotFound = huma.Error404NotFound("theme not found")
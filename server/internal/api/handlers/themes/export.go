@@ -0,0 +1,71 @@
+package themes_handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/themeexport"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type ExportThemeInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+	Format  string `query:"format" required:"true" enum:"kitty,foot,alacritty,gtk,qt,vscode,base16" doc:"Output format"`
+	Mode    string `query:"mode" enum:"dark,light" default:"dark" doc:"Color mode to export"`
+	Variant string `query:"variant" doc:"Variant option id for option themes; defaults to the theme's default"`
+	Flavor  string `query:"flavor" doc:"Flavor id for multi-variant themes; defaults to the mode's default flavor"`
+	Accent  string `query:"accent" doc:"Accent id for multi-variant themes; defaults to the mode's default accent"`
+}
+
+type ExportThemeResponse struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+func (h *HandlerGroup) ExportTheme(ctx context.Context, input *ExportThemeInput) (*ExportThemeResponse, error) {
+	format, ok := themeexport.LookupFormat(input.Format)
+	if !ok {
+		return nil, huma.Error400BadRequest(fmt.Sprintf("unknown format %q", input.Format))
+	}
+
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := registry.ResolveScheme(&theme, registry.ThemeSelection{
+		Mode:    input.Mode,
+		Variant: input.Variant,
+		Flavor:  input.Flavor,
+		Accent:  input.Accent,
+	})
+	if errors.Is(err, registry.ErrInvalidSelection) {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := theme.Name
+	if name == "" {
+		name = theme.ID
+	}
+	palette, err := themeexport.NewPalette(name, theme.Author, resolved.Mode, resolved.Colors)
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity(fmt.Sprintf("theme cannot be exported: %v", err))
+	}
+
+	body, err := format.Render(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportThemeResponse{
+		ContentType:        format.ContentType,
+		ContentDisposition: fmt.Sprintf("attachment; filename=%q", format.Filename(theme.ID, resolved.Mode)),
+		Body:               body,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package themes_handler
+
+import (
+	"context"
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/themeimport"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const (
+	maxWallpaperBytes = 10 << 20
+	maxDominantColors = 6
+)
+
+type GenerateThemeInput struct {
+	Seed    string `query:"seed" pattern:"^#[0-9a-fA-F]{6}$" doc:"Seed color as #RRGGBB; when set, the body is ignored"`
+	ID      string `query:"id" maxLength:"64" pattern:"^[a-z0-9][a-z0-9-]*$" doc:"Theme id; derived from the name when empty"`
+	Name    string `query:"name" maxLength:"100" doc:"Theme name"`
+	Author  string `query:"author" maxLength:"100" doc:"Theme author"`
+	RawBody []byte `contentType:"image/*" doc:"Wallpaper as PNG, JPEG, GIF or WebP, used when no seed is given"`
+}
+
+type DominantColor struct {
+	Color string  `json:"color"`
+	Share float64 `json:"share"`
+}
+
+type GenerateThemeResponse struct {
+	Body struct {
+		Theme    themeimport.Document `json:"theme"`
+		WCAG     *models.ThemeWCAG    `json:"wcag,omitempty"`
+		Seed     string               `json:"seed"`
+		Dominant []DominantColor      `json:"dominant,omitempty"`
+		Nudged   []string             `json:"nudged"`
+	}
+}
+
+func (h *HandlerGroup) GenerateTheme(ctx context.Context, input *GenerateThemeInput) (*GenerateThemeResponse, error) {
+	resp := &GenerateThemeResponse{}
+
+	var seed colors.RGB
+	switch {
+	case input.Seed != "":
+		seed, _ = colors.ParseHex(input.Seed)
+	case len(input.RawBody) > 0:
+		img, err := themeimport.DecodeWallpaper(input.RawBody)
+		if err != nil {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		dominant, err := themeimport.DominantColors(img)
+		if err != nil {
+			return nil, huma.Error422UnprocessableEntity(err.Error())
+		}
+		seed = dominant[0].Color
+		for _, c := range dominant[:min(len(dominant), maxDominantColors)] {
+			resp.Body.Dominant = append(resp.Body.Dominant, DominantColor{
+				Color: c.Color.Hex(),
+				Share: math.Round(c.Share*1000) / 1000,
+			})
+		}
+	default:
+		return nil, huma.Error400BadRequest("provide a seed color or an image body")
+	}
+
+	gen := themeimport.Generate(seed, themeimport.Options{
+		ID:     input.ID,
+		Name:   input.Name,
+		Author: input.Author,
+	})
+
+	resp.Body.Theme = gen.Theme
+	resp.Body.WCAG = registry.ComputeThemeWCAG(&models.Theme{
+		Dark:  gen.Theme.Dark,
+		Light: gen.Theme.Light,
+	})
+	resp.Body.Seed = gen.Seed.Hex()
+	resp.Body.Nudged = gen.Nudged
+	if resp.Body.Nudged == nil {
+		resp.Body.Nudged = []string{}
+	}
+	return resp, nil
+}
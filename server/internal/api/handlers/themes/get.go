@@ -0,0 +1,98 @@
+package themes_handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type GetThemeInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+}
+
+type GetThemeResponse struct {
+	Body models.Theme
+}
+
+func (h *HandlerGroup) GetTheme(ctx context.Context, input *GetThemeInput) (*GetThemeResponse, error) {
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+	return &GetThemeResponse{Body: theme}, nil
+}
+
+type ResolveThemeInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+	Mode    string `query:"mode" enum:"dark,light" default:"dark" doc:"Color mode to resolve"`
+	Variant string `query:"variant" doc:"Variant option id for option themes; defaults to the theme's default"`
+	Flavor  string `query:"flavor" doc:"Flavor id for multi-variant themes; defaults to the mode's default flavor"`
+	Accent  string `query:"accent" doc:"Accent id for multi-variant themes; defaults to the mode's default accent"`
+	FixTo   string `query:"fixTo" enum:"AA,AAA" default:"AA" doc:"Level the WCAG fix suggestions aim text pairs at"`
+	Rules   string `query:"rules" maxLength:"16" doc:"DMS version whose WCAG rule set the report uses; defaults to the newest"`
+}
+
+type ResolveThemeResponse struct {
+	Body struct {
+		ID      string                 `json:"id"`
+		Mode    string                 `json:"mode"`
+		Variant string                 `json:"variant,omitempty"`
+		Flavor  string                 `json:"flavor,omitempty"`
+		Accent  string                 `json:"accent,omitempty"`
+		Colors  map[string]interface{} `json:"colors"`
+		WCAG    *models.ThemeWCAGMode  `json:"wcag,omitempty"`
+	}
+}
+
+func (h *HandlerGroup) ResolveTheme(ctx context.Context, input *ResolveThemeInput) (*ResolveThemeResponse, error) {
+	rules, err := lookupRules(input.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := registry.ResolveScheme(&theme, registry.ThemeSelection{
+		Mode:    input.Mode,
+		Variant: input.Variant,
+		Flavor:  input.Flavor,
+		Accent:  input.Accent,
+	})
+	if errors.Is(err, registry.ErrInvalidSelection) {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ResolveThemeResponse{}
+	resp.Body.ID = theme.ID
+	resp.Body.Mode = resolved.Mode
+	resp.Body.Variant = resolved.Variant
+	resp.Body.Flavor = resolved.Flavor
+	resp.Body.Accent = resolved.Accent
+	resp.Body.Colors = resolved.Colors
+	resp.Body.WCAG = rules.SchemeWCAG(resolved.Colors)
+	if resp.Body.WCAG != nil {
+		resp.Body.WCAG.Suggestions = rules.SuggestWCAGFixes(resolved.Colors, input.FixTo)
+	}
+	return resp, nil
+}
+
+func (h *HandlerGroup) lookupTheme(id string) (models.Theme, error) {
+	if h.srv.ThemeCache == nil || !h.srv.ThemeCache.IsReady() {
+		return models.Theme{}, ErrCacheNotReady
+	}
+
+	theme, ok := h.srv.ThemeCache.ThemeByID(id)
+	if !ok {
+		return models.Theme{}, ErrThemeNotFound
+	}
+	return theme, nil
+}
@@ -0,0 +1,38 @@
+package themes_handler
+
+import (
+	"context"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+type ThemeHistoryInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+	Rules   string `query:"rules" maxLength:"16" doc:"DMS version whose WCAG rule set grades contrast regressions; defaults to the newest"`
+}
+
+type ThemeHistoryResponse struct {
+	Body struct {
+		ID        string                     `json:"id"`
+		Revisions []models.ThemeHistoryEntry `json:"revisions"`
+	}
+}
+
+func (h *HandlerGroup) ThemeHistory(ctx context.Context, input *ThemeHistoryInput) (*ThemeHistoryResponse, error) {
+	rules, err := lookupRules(input.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, _ := h.srv.ThemeCache.ThemeHistory(theme.ID)
+
+	resp := &ThemeHistoryResponse{}
+	resp.Body.ID = theme.ID
+	resp.Body.Revisions = rules.DiffRevisions(revisions)
+	return resp, nil
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
 	"github.com/danielgtaylor/huma/v2"
 )
 
@@ -18,6 +19,8 @@ const (
 	SortByOldest    ThemeSortBy = "oldest"
 	SortByName      ThemeSortBy = "name"
 	SortByRandom    ThemeSortBy = "random"
+	SortByAPCA      ThemeSortBy = "apca"
+	SortByUpvotes   ThemeSortBy = "upvotes"
 )
 
 func (u ThemeSortBy) Schema(r huma.Registry) *huma.Schema {
@@ -33,13 +36,24 @@ func (u ThemeSortBy) Schema(r huma.Registry) *huma.Schema {
 		string(SortByOldest),
 		string(SortByName),
 		string(SortByRandom),
+		string(SortByAPCA),
+		string(SortByUpvotes),
 	}...)
 	r.Map()["ThemeSortBy"] = schemaRef
 	return &huma.Schema{Ref: "#/components/schemas/ThemeSortBy"}
 }
 
 type ListThemesInput struct {
-	SortBy ThemeSortBy `query:"sortBy" doc:"Sort themes by field"`
+	MinLevel      string      `query:"minLevel" enum:"AA,AAA" doc:"Only show themes meeting at least this WCAG level"`
+	LevelMode     string      `query:"levelMode" enum:"overall,dark,light" doc:"Which level minLevel and minApca apply to; defaults to overall"`
+	MinAPCA       string      `query:"minApca" enum:"spot,large,content,body,preferred" doc:"Only show themes whose weakest text pair reaches at least this APCA level"`
+	BothModes     bool        `query:"bothModes" doc:"Only show themes with both dark and light modes"`
+	VariantType   string      `query:"variantType" enum:"none,options,multi" doc:"Filter by variant type"`
+	Author        string      `query:"author" doc:"Filter by author (case-insensitive)"`
+	Q             string      `query:"q" maxLength:"100" doc:"Search name, description and author"`
+	Status        []string    `query:"status" doc:"Only show themes with all of these status labels (e.g. reviewed)"`
+	ExcludeStatus []string    `query:"excludeStatus" doc:"Exclude themes with these status labels (e.g. broken, deprecated)"`
+	SortBy        ThemeSortBy `query:"sortBy" doc:"Sort themes by field; apca puts the highest minimum Lc first"`
 }
 
 type ListThemesResponse struct {
@@ -54,7 +68,17 @@ func (h *HandlerGroup) GetThemes(ctx context.Context, input *ListThemesInput) (*
 		return nil, ErrCacheNotReady
 	}
 
-	themes := h.srv.ThemeCache.GetThemes()
+	themes := h.srv.ThemeCache.FilterThemes(registry.ThemeFilterOptions{
+		MinLevel:      input.MinLevel,
+		LevelMode:     input.LevelMode,
+		MinAPCALevel:  input.MinAPCA,
+		BothModes:     input.BothModes,
+		VariantType:   input.VariantType,
+		Author:        input.Author,
+		Query:         input.Q,
+		Status:        input.Status,
+		ExcludeStatus: input.ExcludeStatus,
+	})
 
 	sortBy := input.SortBy
 	if sortBy == "" {
@@ -70,6 +94,17 @@ func (h *HandlerGroup) GetThemes(ctx context.Context, input *ListThemesInput) (*
 		rand.Shuffle(len(themes), func(i, j int) {
 			themes[i], themes[j] = themes[j], themes[i]
 		})
+	case SortByAPCA:
+		sort.SliceStable(themes, func(i, j int) bool {
+			return registry.ThemeAPCAScore(&themes[i]) > registry.ThemeAPCAScore(&themes[j])
+		})
+	case SortByUpvotes:
+		sort.SliceStable(themes, func(i, j int) bool {
+			if themes[i].Upvotes != themes[j].Upvotes {
+				return themes[i].Upvotes > themes[j].Upvotes
+			}
+			return themes[i].UpdatedAt.After(themes[j].UpdatedAt)
+		})
 	case SortByOldest:
 		sort.Slice(themes, func(i, j int) bool {
 			return themes[i].UpdatedAt.Before(themes[j].UpdatedAt)
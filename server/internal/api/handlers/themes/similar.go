@@ -0,0 +1,61 @@
+package themes_handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type SimilarThemesInput struct {
+	ThemeID string `path:"themeId" maxLength:"64" doc:"Theme id"`
+	Mode    string `query:"mode" enum:"dark,light" doc:"Compare only this mode; with no selection every mode, variant and flavor of the theme is compared"`
+	Variant string `query:"variant" doc:"Compare only this variant option"`
+	Flavor  string `query:"flavor" doc:"Compare only this flavor of a multi-variant theme"`
+	Accent  string `query:"accent" doc:"Compare only this accent of a multi-variant theme"`
+	Limit   int    `query:"limit" minimum:"1" maximum:"24" default:"6" doc:"Number of themes to return"`
+}
+
+type SimilarThemesResponse struct {
+	Body struct {
+		ID     string                `json:"id"`
+		Themes []models.SimilarTheme `json:"themes"`
+	}
+}
+
+func (h *HandlerGroup) SimilarThemes(ctx context.Context, input *SimilarThemesInput) (*SimilarThemesResponse, error) {
+	theme, err := h.lookupTheme(input.ThemeID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SimilarThemesResponse{}
+	resp.Body.ID = theme.ID
+
+	if input.Mode == "" && input.Variant == "" && input.Flavor == "" && input.Accent == "" {
+		similar, ok := h.srv.ThemeCache.SimilarThemes(theme.ID, input.Limit)
+		if !ok {
+			return nil, ErrCacheNotReady
+		}
+		resp.Body.Themes = similar
+		return resp, nil
+	}
+
+	resolved, err := registry.ResolveScheme(&theme, registry.ThemeSelection{
+		Mode:    input.Mode,
+		Variant: input.Variant,
+		Flavor:  input.Flavor,
+		Accent:  input.Accent,
+	})
+	if errors.Is(err, registry.ErrInvalidSelection) {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Themes = h.srv.ThemeCache.SimilarToScheme(resolved.Colors, theme.ID, input.Limit)
+	return resp, nil
+}
@@ -27,4 +27,110 @@ func RegisterHandlers(server *server.Server, grp *huma.Group) {
 		},
 		handlers.GetThemes,
 	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "get-theme",
+			Summary:     "Get Theme",
+			Description: "Get a single theme by id",
+			Path:        "/{themeId}",
+			Method:      http.MethodGet,
+		},
+		handlers.GetTheme,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "resolve-theme",
+			Summary:     "Resolve Theme Colors",
+			Description: "Get the flat, merged color token map for one mode and variant, flavor or accent of a theme",
+			Path:        "/{themeId}/resolve",
+			Method:      http.MethodGet,
+		},
+		handlers.ResolveTheme,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "get-theme-contrast",
+			Summary:     "Get Theme Contrast Matrix",
+			Description: "Get the WCAG ratio and APCA Lc of every token pairing in one mode and variant, flavor or accent of a theme, with the pairs the WCAG rule set grades",
+			Path:        "/{themeId}/contrast",
+			Method:      http.MethodGet,
+		},
+		handlers.ContrastMatrix,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "get-similar-themes",
+			Summary:     "Get Similar Themes",
+			Description: "Get the themes whose palettes are perceptually nearest this one, by OKLab distance of their key color tokens",
+			Path:        "/{themeId}/similar",
+			Method:      http.MethodGet,
+		},
+		handlers.SimilarThemes,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "get-theme-history",
+			Summary:     "Get Theme History",
+			Description: "Get the revisions of a theme seen across registry refreshes, newest first, with each one's token changes and the contrast pairs whose WCAG level dropped",
+			Path:        "/{themeId}/history",
+			Method:      http.MethodGet,
+		},
+		handlers.ThemeHistory,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID: "export-theme",
+			Summary:     "Export Theme",
+			Description: "Export one mode and variant, flavor or accent of a theme as a config file for a terminal, toolkit or editor",
+			Path:        "/{themeId}/export",
+			Method:      http.MethodGet,
+		},
+		handlers.ExportTheme,
+	)
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID:  "convert-theme",
+			Summary:      "Convert Color Scheme",
+			Description:  "Convert a base16/base24 scheme or terminal color config into a DMS theme.json with dark and light modes and its WCAG report",
+			Path:         "/convert",
+			Method:       http.MethodPost,
+			MaxBodyBytes: maxConvertBytes,
+		},
+		handlers.ConvertTheme,
+	)
+}
+
+// RegisterGenerateHandlers adds theme generation, which decodes and clusters an upload
+// on every call and so is registered on a group its caller rate limits.
+func RegisterGenerateHandlers(server *server.Server, grp *huma.Group) {
+	handlers := &HandlerGroup{
+		srv: server,
+	}
+
+	huma.Register(
+		grp,
+		huma.Operation{
+			OperationID:  "generate-theme",
+			Summary:      "Generate Theme",
+			Description:  "Generate a DMS theme with dark and light modes from a seed color or a wallpaper's dominant color, with its WCAG report",
+			Path:         "/generate",
+			Method:       http.MethodPost,
+			MaxBodyBytes: maxWallpaperBytes,
+		},
+		handlers.GenerateTheme,
+	)
 }
@@ -17,7 +17,10 @@ import (
 
 const refreshDebounce = 2 * time.Second
 
-const pluginLabel = "plugin"
+const (
+	pluginLabel = "plugin"
+	themeLabel  = "theme"
+)
 
 type FeedbackRefresher interface {
 	RefreshFeedback(ctx context.Context) error
@@ -35,6 +38,7 @@ type Config struct {
 	Cache      FeedbackRefresher
 	Moderator  Moderator
 	Authors    PluginLookup
+	Themes     ThemeLookup
 }
 
 type HandlerGroup struct {
@@ -47,9 +51,11 @@ type HandlerGroup struct {
 	cache      FeedbackRefresher
 	moderator  Moderator
 	authors    PluginLookup
+	themes     ThemeLookup
 
-	mu          sync.Mutex
-	lastRefresh time.Time
+	mu               sync.Mutex
+	lastRefresh      time.Time
+	lastThemeRefresh time.Time
 }
 
 type WebhookInput struct {
@@ -91,6 +97,7 @@ func RegisterHandlers(cfg Config, grp *huma.Group) {
 		cache:      cfg.Cache,
 		moderator:  cfg.Moderator,
 		authors:    cfg.Authors,
+		themes:     cfg.Themes,
 	}
 
 	huma.Register(grp, huma.Operation{
@@ -113,8 +120,13 @@ func (h *HandlerGroup) Handle(_ context.Context, input *WebhookInput) (*WebhookO
 
 	switch input.Event {
 	case "issues":
-		if relevantAction(payload.Action) {
-			h.triggerRefresh()
+		if !relevantAction(payload.Action) {
+			break
+		}
+		if hasLabel(payload.Issue.Labels, themeLabel) && h.themes != nil {
+			h.triggerRefresh(&h.lastThemeRefresh, h.themes.RefreshFeedback, "theme")
+		} else {
+			h.triggerRefresh(&h.lastRefresh, h.cache.RefreshFeedback, "plugin")
 		}
 	case "issue_comment":
 		if payload.Action == "created" {
@@ -136,18 +148,20 @@ func (h *HandlerGroup) validSignature(body []byte, signature string) bool {
 	return hmac.Equal([]byte(expected), []byte(signature))
 }
 
-func (h *HandlerGroup) triggerRefresh() {
+// triggerRefresh re-fetches one catalog's feedback, debounced per catalog so a burst of
+// plugin events does not hold back a theme refresh.
+func (h *HandlerGroup) triggerRefresh(last *time.Time, refresh func(context.Context) error, catalog string) {
 	h.mu.Lock()
-	if time.Since(h.lastRefresh) < refreshDebounce {
+	if time.Since(*last) < refreshDebounce {
 		h.mu.Unlock()
 		return
 	}
-	h.lastRefresh = time.Now()
+	*last = time.Now()
 	h.mu.Unlock()
 
 	go func() {
-		if err := h.cache.RefreshFeedback(context.Background()); err != nil {
-			log.Error("Webhook feedback refresh failed", "err", err)
+		if err := refresh(context.Background()); err != nil {
+			log.Error("Webhook feedback refresh failed", "catalog", catalog, "err", err)
 		}
 	}()
 }
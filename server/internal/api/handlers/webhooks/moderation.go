@@ -30,12 +30,21 @@ type PluginLookup interface {
 	PluginByIssue(number int) (models.Plugin, bool)
 }
 
+// ThemeLookup is the theme catalog's side of moderation: status commands update it in
+// place and whoever submitted a theme gets the same self-service flags as plugin authors.
+type ThemeLookup interface {
+	RefreshFeedback(ctx context.Context) error
+	ApplyStatus(themeID, status string, add bool)
+	ThemeSubmitter(themeID string) (string, bool)
+}
+
 var pluginIDMarker = regexp.MustCompile(`<!--\s*dms-plugin-id:\s*([A-Za-z0-9]+)\s*-->`)
+var themeIDMarker = regexp.MustCompile(`<!--\s*dms-theme-id:\s*([A-Za-z0-9_-]+)\s*-->`)
 
-// selfRestricted commands cannot be used by a plugin's own author (only an Owner can).
+// selfRestricted commands cannot be used by an entry's own author (only an Owner can).
 var selfRestrictedLabels = map[string]bool{"status:reviewed": true}
 
-// authorLabels are the status flags a plugin's own author may set on their own plugin,
+// authorLabels are the status flags an author may set on their own plugin or theme,
 // even without a moderator or owner role.
 var authorLabels = map[string]bool{
 	"status:deprecated":   true,
@@ -85,40 +94,89 @@ func parseCommands(body string) []command {
 	return actions
 }
 
-func hasPluginLabel(labels []label) bool {
+func hasLabel(labels []label, name string) bool {
 	for _, l := range labels {
-		if l.Name == pluginLabel {
+		if l.Name == name {
 			return true
 		}
 	}
 	return false
 }
 
+// moderationTarget is the catalog entry an issue tracks, a plugin or a theme, with the
+// hooks the commands need. Only plugins take /similar.
+type moderationTarget struct {
+	id          string
+	author      func(id string) (string, bool)
+	applyStatus func(id, status string, add bool)
+	similar     bool
+}
+
+func (t moderationTarget) isAuthor(user string) bool {
+	if t.author == nil || t.id == "" {
+		return false
+	}
+	owner, ok := t.author(t.id)
+	return ok && strings.EqualFold(owner, user)
+}
+
+func (h *HandlerGroup) pluginTarget(body string) moderationTarget {
+	target := moderationTarget{
+		id:          extractPluginID(body),
+		applyStatus: h.cache.ApplyStatus,
+		similar:     true,
+	}
+	if h.authors != nil {
+		target.author = h.authors.RepoOwner
+	}
+	return target
+}
+
+func (h *HandlerGroup) themeTarget(body string) moderationTarget {
+	return moderationTarget{
+		id:          extractThemeID(body),
+		author:      h.themes.ThemeSubmitter,
+		applyStatus: h.themes.ApplyStatus,
+	}
+}
+
 func (h *HandlerGroup) handleComment(p eventPayload) {
-	if h.moderator == nil || !hasPluginLabel(p.Issue.Labels) {
+	if h.moderator == nil {
+		return
+	}
+
+	var target moderationTarget
+	switch {
+	case hasLabel(p.Issue.Labels, pluginLabel):
+		target = h.pluginTarget(p.Issue.Body)
+	case hasLabel(p.Issue.Labels, themeLabel) && h.themes != nil:
+		target = h.themeTarget(p.Issue.Body)
+	default:
 		return
 	}
 
 	labelActions := parseCommands(p.Comment.Body)
-	similarActions := parseSimilarCommands(p.Comment.Body)
+	var similarActions []similarCommand
+	if target.similar {
+		similarActions = parseSimilarCommands(p.Comment.Body)
+	}
 	if len(labelActions) == 0 && len(similarActions) == 0 {
 		return
 	}
 
-	go h.processComment(p, labelActions, similarActions)
+	go h.processComment(p, target, labelActions, similarActions)
 }
 
-func (h *HandlerGroup) processComment(p eventPayload, labelActions []command, similarActions []similarCommand) {
+func (h *HandlerGroup) processComment(p eventPayload, target moderationTarget, labelActions []command, similarActions []similarCommand) {
 	ctx := context.Background()
 	user := p.Comment.User.Login
-	pluginID := extractPluginID(p.Issue.Body)
 
 	isOwner, isModerator, err := h.membership(ctx, user)
 	if err != nil {
 		log.Error("Failed to check team membership", "err", err)
 		return
 	}
-	isAuthor := h.isPluginAuthor(pluginID, user)
+	isAuthor := target.isAuthor(user)
 	if !isOwner && !isModerator && !isAuthor {
 		h.react(ctx, p.Comment.ID, "confused")
 		return
@@ -128,9 +186,9 @@ func (h *HandlerGroup) processComment(p eventPayload, labelActions []command, si
 	case isOwner:
 		// Owners have no restrictions.
 	case isModerator:
-		labelActions = h.filterSelfModeration(pluginID, user, labelActions)
+		labelActions = filterSelfModeration(isAuthor, user, labelActions)
 	default:
-		// Plugin author without a moderator role: limited to self-service status flags.
+		// Author without a moderator role: limited to self-service status flags.
 		labelActions = filterAuthorLabels(labelActions)
 		similarActions = nil
 	}
@@ -145,8 +203,8 @@ func (h *HandlerGroup) processComment(p eventPayload, labelActions []command, si
 
 	for _, action := range labelActions {
 		h.applyCommand(ctx, p.Issue.Number, action)
-		if pluginID != "" {
-			h.cache.ApplyStatus(pluginID, strings.TrimPrefix(action.label, "status:"), action.add)
+		if target.id != "" {
+			target.applyStatus(target.id, strings.TrimPrefix(action.label, "status:"), action.add)
 		}
 		verb := "added"
 		if !action.add {
@@ -156,7 +214,7 @@ func (h *HandlerGroup) processComment(p eventPayload, labelActions []command, si
 	}
 
 	for _, action := range similarActions {
-		if line := h.applySimilar(ctx, pluginID, user, action, timestamp); line != "" {
+		if line := h.applySimilar(ctx, target.id, user, action, timestamp); line != "" {
 			auditLines = append(auditLines, line)
 		}
 	}
@@ -195,19 +253,19 @@ func extractPluginID(body string) string {
 	return match[1]
 }
 
-func (h *HandlerGroup) isPluginAuthor(pluginID, user string) bool {
-	if h.authors == nil || pluginID == "" {
-		return false
+func extractThemeID(body string) string {
+	match := themeIDMarker.FindStringSubmatch(body)
+	if match == nil {
+		return ""
 	}
-	owner, ok := h.authors.RepoOwner(pluginID)
-	return ok && strings.EqualFold(owner, user)
+	return match[1]
 }
 
-// filterSelfModeration drops review/unreview actions when the commenter is the plugin's
-// own author, so a moderator can't mark their own plugin reviewed. Owners bypass this
-// entirely (checked earlier).
-func (h *HandlerGroup) filterSelfModeration(pluginID, user string, actions []command) []command {
-	if !h.isPluginAuthor(pluginID, user) {
+// filterSelfModeration drops review/unreview actions when the commenter is the entry's
+// own author, so a moderator can't mark their own plugin or theme reviewed. Owners
+// bypass this entirely (checked earlier).
+func filterSelfModeration(isAuthor bool, user string, actions []command) []command {
+	if !isAuthor {
 		return actions
 	}
 
@@ -222,8 +280,8 @@ func (h *HandlerGroup) filterSelfModeration(pluginID, user string, actions []com
 	return allowed
 }
 
-// filterAuthorLabels keeps only the status flags an unprivileged plugin author may set on
-// their own plugin (e.g. /unmaintained, /deprecated).
+// filterAuthorLabels keeps only the status flags an unprivileged author may set on their
+// own plugin or theme (e.g. /unmaintained, /deprecated).
 func filterAuthorLabels(actions []command) []command {
 	var allowed []command
 	for _, action := range actions {
@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeModerator struct {
+	moderators map[string]bool
+	added      []string
+	reactions  []string
+}
+
+func (m *fakeModerator) IsOrgTeamMember(_ context.Context, _, team, user string) (bool, error) {
+	return team == "moderators" && m.moderators[user], nil
+}
+
+func (m *fakeModerator) EnsureLabel(context.Context, string, string, string, string, string) error {
+	return nil
+}
+
+func (m *fakeModerator) AddLabel(_ context.Context, _, _ string, _ int, label string) error {
+	m.added = append(m.added, label)
+	return nil
+}
+
+func (m *fakeModerator) RemoveLabel(context.Context, string, string, int, string) error {
+	return nil
+}
+
+func (m *fakeModerator) CreateCommentReaction(_ context.Context, _, _ string, _ int64, content string) error {
+	m.reactions = append(m.reactions, content)
+	return nil
+}
+
+func (m *fakeModerator) AppendAudit(context.Context, string, string, int, string) error {
+	return nil
+}
+
+func (m *fakeModerator) GetIssueBody(context.Context, string, string, int) (string, error) {
+	return "", nil
+}
+
+func (m *fakeModerator) UpdateIssueBody(context.Context, string, string, int, string) error {
+	return nil
+}
+
+// fakeThemes holds one theme, added to the registry by submitter.
+type fakeThemes struct {
+	submitter string
+	applied   []string
+}
+
+func (t *fakeThemes) RefreshFeedback(context.Context) error { return nil }
+
+func (t *fakeThemes) ApplyStatus(_, status string, _ bool) {
+	t.applied = append(t.applied, status)
+}
+
+func (t *fakeThemes) ThemeSubmitter(string) (string, bool) {
+	return t.submitter, t.submitter != ""
+}
+
+func newThemeModeration(themes *fakeThemes, moderators ...string) (*HandlerGroup, *fakeModerator) {
+	mod := &fakeModerator{moderators: map[string]bool{}}
+	for _, m := range moderators {
+		mod.moderators[m] = true
+	}
+	h := &HandlerGroup{
+		owner:      "AvengeMedia",
+		repo:       "dms-plugin-registry",
+		org:        "AvengeMedia",
+		team:       "moderators",
+		ownersTeam: "owners",
+		moderator:  mod,
+		themes:     themes,
+	}
+	return h, mod
+}
+
+func themeComment(user, body string) eventPayload {
+	var p eventPayload
+	p.Issue.Number = 7
+	p.Issue.Body = "<!-- dms-theme-id: mocha -->"
+	p.Issue.Labels = []label{{Name: themeLabel}}
+	p.Comment.ID = 1
+	p.Comment.Body = body
+	p.Comment.User.Login = user
+	return p
+}
+
+func TestThemeAuthorFieldGrantsNoSelfService(t *testing.T) {
+	// theme.json claims mallory wrote it; alice is who added it to the registry.
+	themes := &fakeThemes{submitter: "alice"}
+	h, mod := newThemeModeration(themes)
+
+	p := themeComment("mallory", "/deprecated")
+	h.processComment(p, h.themeTarget(p.Issue.Body), parseCommands(p.Comment.Body), nil)
+
+	if len(mod.added) != 0 || len(themes.applied) != 0 {
+		t.Fatalf("spoofed author changed the theme: labels %v, statuses %v", mod.added, themes.applied)
+	}
+	if len(mod.reactions) != 1 || mod.reactions[0] != "confused" {
+		t.Fatalf("expected a confused reaction, got %v", mod.reactions)
+	}
+
+	p = themeComment("alice", "/deprecated")
+	h.processComment(p, h.themeTarget(p.Issue.Body), parseCommands(p.Comment.Body), nil)
+	if len(mod.added) != 1 || mod.added[0] != "status:deprecated" {
+		t.Fatalf("expected the submitter to deprecate the theme, got %v", mod.added)
+	}
+}
+
+func TestThemeAuthorFieldDoesNotDodgeSelfReview(t *testing.T) {
+	// A moderator who submitted a theme under someone else's name still can't review it.
+	themes := &fakeThemes{submitter: "mod"}
+	h, mod := newThemeModeration(themes, "mod")
+
+	p := themeComment("mod", "/review")
+	h.processComment(p, h.themeTarget(p.Issue.Body), parseCommands(p.Comment.Body), nil)
+
+	if len(mod.added) != 0 || len(themes.applied) != 0 {
+		t.Fatalf("moderator reviewed their own theme: labels %v, statuses %v", mod.added, themes.applied)
+	}
+}
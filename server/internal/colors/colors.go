@@ -0,0 +1,238 @@
+// Package colors holds the perceptual color math shared by the theme tooling:
+// sRGB parsing and formatting, OKLab/OKLCH conversion, and mixing.
+package colors
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RGB is a gamma-encoded sRGB color with channels in [0, 1].
+type RGB struct {
+	R, G, B float64
+}
+
+// OKLab is a color in Björn Ottosson's OKLab space.
+// https://bottosson.github.io/posts/oklab/
+type OKLab struct {
+	L, A, B float64
+}
+
+// OKLCH is OKLab in cylindrical form; H is in degrees.
+type OKLCH struct {
+	L, C, H float64
+}
+
+// ParseHex reads #RRGGBB or #RGB.
+func ParseHex(s string) (RGB, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "#") {
+		return RGB{}, false
+	}
+	hexPart := s[1:]
+	if len(hexPart) == 3 {
+		hexPart = string([]byte{hexPart[0], hexPart[0], hexPart[1], hexPart[1], hexPart[2], hexPart[2]})
+	}
+	if len(hexPart) != 6 {
+		return RGB{}, false
+	}
+	n, err := strconv.ParseUint(hexPart, 16, 32)
+	if err != nil {
+		return RGB{}, false
+	}
+	return bytesRGB(n), true
+}
+
+// Hex formats the color as uppercase #RRGGBB, clamping out-of-gamut channels.
+func (c RGB) Hex() string {
+	r, g, b := c.Bytes()
+	return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+}
+
+func (c RGB) Bytes() (uint8, uint8, uint8) {
+	return toByte(c.R), toByte(c.G), toByte(c.B)
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func (c RGB) Clamp() RGB {
+	return RGB{R: clamp01(c.R), G: clamp01(c.G), B: clamp01(c.B)}
+}
+
+func (c RGB) InGamut() bool {
+	const eps = 1e-4
+	return c.R >= -eps && c.R <= 1+eps && c.G >= -eps && c.G <= 1+eps && c.B >= -eps && c.B <= 1+eps
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// Linear returns the color's linear-light channels.
+func (c RGB) Linear() (float64, float64, float64) {
+	return srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)
+}
+
+// FromLinear builds a gamma-encoded color from linear-light channels.
+func FromLinear(r, g, b float64) RGB {
+	return RGB{R: linearToSRGB(r), G: linearToSRGB(g), B: linearToSRGB(b)}
+}
+
+func (c RGB) OKLab() OKLab {
+	r, g, b := c.Linear()
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// RGB converts back to sRGB without clamping; check InGamut or call Clamp.
+func (c OKLab) RGB() RGB {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return FromLinear(
+		+4.0767416621*l-3.3077115913*m+0.2309699292*s,
+		-1.2684380046*l+2.6097574011*m-0.3413193965*s,
+		-0.0041960863*l-0.7034186147*m+1.7076147010*s,
+	)
+}
+
+func (c OKLab) OKLCH() OKLCH {
+	h := math.Atan2(c.B, c.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: c.L, C: math.Hypot(c.A, c.B), H: h}
+}
+
+func (c OKLCH) OKLab() OKLab {
+	rad := c.H * math.Pi / 180
+	return OKLab{L: c.L, A: c.C * math.Cos(rad), B: c.C * math.Sin(rad)}
+}
+
+func (c RGB) OKLCH() OKLCH {
+	return c.OKLab().OKLCH()
+}
+
+// RGB maps the color into sRGB, reducing chroma at constant lightness and hue until it
+// fits, which keeps the color's identity where plain clamping would shift its hue.
+func (c OKLCH) RGB() RGB {
+	c.L = math.Max(0, math.Min(1, c.L))
+	if rgb := c.OKLab().RGB(); rgb.InGamut() {
+		return rgb.Clamp()
+	}
+
+	lo, hi := 0.0, c.C
+	for range 24 {
+		mid := (lo + hi) / 2
+		if (OKLCH{L: c.L, C: mid, H: c.H}).OKLab().RGB().InGamut() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return OKLCH{L: c.L, C: lo, H: c.H}.OKLab().RGB().Clamp()
+}
+
+// Mix interpolates from a to b in OKLab; t=0 is a, t=1 is b.
+func Mix(a, b RGB, t float64) RGB {
+	la, lb := a.OKLab(), b.OKLab()
+	return OKLab{
+		L: la.L + (lb.L-la.L)*t,
+		A: la.A + (lb.A-la.A)*t,
+		B: la.B + (lb.B-la.B)*t,
+	}.RGB().Clamp()
+}
+
+// DeltaE is the Euclidean distance in OKLab, roughly 0.02 for a just-noticeable step.
+func DeltaE(a, b RGB) float64 {
+	la, lb := a.OKLab(), b.OKLab()
+	return math.Sqrt((la.L-lb.L)*(la.L-lb.L) + (la.A-lb.A)*(la.A-lb.A) + (la.B-lb.B)*(la.B-lb.B))
+}
+
+// Luminance is the WCAG 2.x relative luminance.
+// https://www.w3.org/TR/WCAG22/#dfn-relative-luminance
+func (c RGB) Luminance() float64 {
+	r, g, b := c.Linear()
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// Contrast is the WCAG 2.x contrast ratio between two colors.
+func Contrast(a, b RGB) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if lb > la {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// ReadableOn picks black or white, whichever contrasts more with bg.
+func ReadableOn(bg RGB) RGB {
+	black, white := RGB{}, RGB{R: 1, G: 1, B: 1}
+	if Contrast(black, bg) >= Contrast(white, bg) {
+		return black
+	}
+	return white
+}
+
+// EnsureContrast returns fg with its OKLCH lightness moved the least distance, in
+// either direction, that brings its contrast against bg up to target. Hue and chroma
+// are kept except where the gamut forces chroma down. ok is false when no lightness
+// reaches target; fg is then pushed as far as it goes toward the better extreme.
+func EnsureContrast(fg, bg RGB, target float64) (RGB, bool) {
+	if Contrast(fg, bg) >= target {
+		return fg, true
+	}
+
+	const step = 0.002
+	lch := fg.OKLCH()
+	best, bestRatio := fg, Contrast(fg, bg)
+	for delta := step; delta <= 1; delta += step {
+		reachable := false
+		for _, l := range []float64{lch.L - delta, lch.L + delta} {
+			if l < 0 || l > 1 {
+				continue
+			}
+			reachable = true
+			candidate := OKLCH{L: l, C: lch.C, H: lch.H}.RGB()
+			ratio := Contrast(candidate, bg)
+			if ratio >= target {
+				return candidate, true
+			}
+			if ratio > bestRatio {
+				best, bestRatio = candidate, ratio
+			}
+		}
+		if !reachable {
+			break
+		}
+	}
+	return best, false
+}
@@ -0,0 +1,191 @@
+package colors
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseHex(t *testing.T) {
+	c, ok := ParseHex("#1e66F5")
+	if !ok || c.Hex() != "#1E66F5" {
+		t.Fatalf("round trip failed: %+v ok=%v", c, ok)
+	}
+	if short, ok := ParseHex("#fff"); !ok || short.Hex() != "#FFFFFF" {
+		t.Fatalf("expected #fff to expand, got %+v ok=%v", short, ok)
+	}
+	for _, bad := range []string{"", "fff", "#ff", "#GGGGGG", "#1234567"} {
+		if _, ok := ParseHex(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestOKLabReferenceValues(t *testing.T) {
+	// Reference values from https://bottosson.github.io/posts/oklab/
+	white, _ := ParseHex("#FFFFFF")
+	lab := white.OKLab()
+	if math.Abs(lab.L-1) > 1e-3 || math.Abs(lab.A) > 1e-3 || math.Abs(lab.B) > 1e-3 {
+		t.Fatalf("white: got %+v", lab)
+	}
+
+	red, _ := ParseHex("#FF0000")
+	lab = red.OKLab()
+	if math.Abs(lab.L-0.628) > 1e-3 || math.Abs(lab.A-0.2249) > 1e-3 || math.Abs(lab.B-0.1258) > 1e-3 {
+		t.Fatalf("red: got %+v", lab)
+	}
+}
+
+func TestOKLabRoundTrip(t *testing.T) {
+	for _, hex := range []string{"#000000", "#1E1E2E", "#89B4FA", "#F38BA8", "#EFF1F5"} {
+		c, _ := ParseHex(hex)
+		if got := c.OKLCH().RGB().Hex(); got != hex {
+			t.Fatalf("%s round-tripped to %s", hex, got)
+		}
+	}
+}
+
+func TestOKLCHGamutMappingKeepsHue(t *testing.T) {
+	// Far outside sRGB; mapping should land in gamut without drifting hue much.
+	out := OKLCH{L: 0.7, C: 0.4, H: 145}.RGB()
+	if !out.InGamut() {
+		t.Fatalf("expected in-gamut result, got %+v", out)
+	}
+	if h := out.OKLCH().H; math.Abs(h-145) > 2 {
+		t.Fatalf("hue drifted to %f", h)
+	}
+}
+
+func TestContrastMatchesWCAG(t *testing.T) {
+	gray, _ := ParseHex("#767676")
+	white, _ := ParseHex("#FFFFFF")
+	if ratio := Contrast(gray, white); math.Abs(ratio-4.54) > 0.01 {
+		t.Fatalf("expected 4.54, got %f", ratio)
+	}
+	if ReadableOn(white) != (RGB{}) {
+		t.Fatal("expected black to read on white")
+	}
+}
+
+func TestEnsureContrastMovesLightnessOnly(t *testing.T) {
+	bg, _ := ParseHex("#1E1E2E")
+	fg, _ := ParseHex("#45385A")
+
+	fixed, ok := EnsureContrast(fg, bg, 4.5)
+	if !ok || Contrast(fixed, bg) < 4.5 {
+		t.Fatalf("expected 4.5:1, got %f ok=%v", Contrast(fixed, bg), ok)
+	}
+	// A dark foreground on a dark surface has to get lighter, and only just enough.
+	if fixed.OKLCH().L <= fg.OKLCH().L || Contrast(fixed, bg) > 4.7 {
+		t.Fatalf("expected a minimal lightening, got %s (%f)", fixed.Hex(), Contrast(fixed, bg))
+	}
+	if h := fixed.OKLCH().H; math.Abs(h-fg.OKLCH().H) > 3 {
+		t.Fatalf("hue drifted from %f to %f", fg.OKLCH().H, h)
+	}
+
+	if same, ok := EnsureContrast(bg, fg, 1); !ok || same != bg {
+		t.Fatal("expected a passing pair to be returned unchanged")
+	}
+	gray, _ := ParseHex("#777777")
+	if _, ok := EnsureContrast(gray, gray, 22); ok {
+		t.Fatal("expected an unreachable target to report failure")
+	}
+}
+
+func TestTonalPaletteHitsTones(t *testing.T) {
+	palette := TonalPalette{Hue: 300, Chroma: 0.14}
+	for _, tone := range []float64{10, 30, 40, 80, 90, 98} {
+		if got := palette.Tone(tone).Tone(); math.Abs(got-tone) > 0.5 {
+			t.Fatalf("tone %v came out as %v", tone, got)
+		}
+	}
+	// Tones 50 apart are what Material 3 relies on for text contrast.
+	if ratio := Contrast(palette.Tone(40), palette.Tone(90)); ratio < 4.5 {
+		t.Fatalf("tones 40 and 90 only reach %.2f:1", ratio)
+	}
+}
+
+func TestSimulateDeficiencies(t *testing.T) {
+	gray, _ := ParseHex("#808080")
+	for _, d := range Deficiencies {
+		if got := Simulate(gray, d); DeltaE(got, gray) > 0.005 {
+			t.Fatalf("%s moved a neutral gray to %s", d, got.Hex())
+		}
+	}
+
+	// A red and a green of similar lightness are the classic red-green confusion pair.
+	red, _ := ParseHex("#D05A4A")
+	green, _ := ParseHex("#6E8F2E")
+	if DeltaE(red, green) < 0.15 {
+		t.Fatalf("test colors should be distinct to normal vision")
+	}
+	if dist := DeltaE(Simulate(red, Deuteranopia), Simulate(green, Deuteranopia)); dist > 0.03 {
+		t.Fatalf("deuteranopia: expected red and green to converge, distance %.3f", dist)
+	}
+	// Protanopes still see red as darker, so the pair only halves in distance.
+	if dist := DeltaE(Simulate(red, Protanopia), Simulate(green, Protanopia)); dist > DeltaE(red, green)/2 {
+		t.Fatalf("protanopia: expected red and green to move closer, distance %.3f", dist)
+	}
+	if dist := DeltaE(Simulate(red, Tritanopia), Simulate(green, Tritanopia)); dist < 0.1 {
+		t.Fatalf("tritanopia: expected red and green to stay apart, distance %.3f", dist)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		in    string
+		hex   string
+		alpha float64
+	}{
+		{"#1E66F5", "#1E66F5", 1},
+		{"#fff", "#FFFFFF", 1},
+		{"rgb(30, 102, 245)", "#1E66F5", 1},
+		{"rgba(30, 102, 245, 0.5)", "#1E66F5", 0.5},
+		{"rgb(30 102 245 / 50%)", "#1E66F5", 0.5},
+		{"rgb(100%, 0%, 0%)", "#FF0000", 1},
+		{"rgba(1e66f580)", "#1E66F5", 128.0 / 255},
+		{"rgb(1e66f5)", "#1E66F5", 1},
+	}
+	for _, tc := range cases {
+		c, ok := ParseColor(tc.in)
+		if !ok {
+			t.Fatalf("%q: rejected", tc.in)
+		}
+		if c.Hex() != tc.hex || math.Abs(c.A-tc.alpha) > 1e-9 {
+			t.Fatalf("%q: got %s alpha %v, want %s alpha %v", tc.in, c.Hex(), c.A, tc.hex, tc.alpha)
+		}
+	}
+
+	for _, bad := range []string{"", "red", "#12345", "#801E66F5", "rgb(1, 2)", "rgba(300, 0, 0, 1)", "rgba(0, 0, 0, 2)", "hsl(0, 0%, 0%)"} {
+		if _, ok := ParseColor(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestParseColorHexAlphaReadsTheOrderGiven(t *testing.T) {
+	first, ok := ParseColorHexAlpha("#801E66F5", HexAlphaFirst)
+	if !ok || first.Hex() != "#1E66F5" || math.Abs(first.A-128.0/255) > 1e-9 {
+		t.Fatalf("#AARRGGBB: got %s alpha %v, %v", first.Hex(), first.A, ok)
+	}
+	last, ok := ParseColorHexAlpha("#1e1e2ecc", HexAlphaLast)
+	if !ok || last.Hex() != "#1E1E2E" || math.Abs(last.A-0xCC/255.0) > 1e-9 {
+		t.Fatalf("#RRGGBBAA: got %s alpha %v, %v", last.Hex(), last.A, ok)
+	}
+	if _, ok := ParseColorHexAlpha("#1e1e2ecc", HexAlphaUnknown); ok {
+		t.Fatal("expected eight-digit hex of unknown order to be refused")
+	}
+	if c, ok := ParseColorHexAlpha("#1E66F5", HexAlphaLast); !ok || !c.Opaque() {
+		t.Fatalf("expected six-digit hex to read the same in any order, got %v %v", c, ok)
+	}
+}
+
+func TestRGBAOver(t *testing.T) {
+	white := RGBA{RGB: RGB{R: 1, G: 1, B: 1}, A: 0.5}
+	if got := white.Over(RGB{}).Hex(); got != "#808080" {
+		t.Fatalf("expected half white over black to be #808080, got %s", got)
+	}
+	opaque := RGBA{RGB: RGB{R: 1}, A: 1}
+	if got := opaque.Over(RGB{B: 1}).Hex(); got != "#FF0000" {
+		t.Fatalf("expected opaque color to cover the backdrop, got %s", got)
+	}
+}
@@ -0,0 +1,49 @@
+package colors
+
+// Deficiency is a kind of dichromatic color vision.
+type Deficiency string
+
+const (
+	Protanopia   Deficiency = "protanopia"
+	Deuteranopia Deficiency = "deuteranopia"
+	Tritanopia   Deficiency = "tritanopia"
+)
+
+// Deficiencies lists every simulated deficiency in a stable order.
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+
+// Machado, Oliveira and Fernandes (2009) matrices at severity 1.0, applied to linear
+// sRGB. They model full dichromacy, the worst case for telling two hues apart.
+// https://www.inf.ufrgs.br/~oliveira/pubs_files/CVD_Simulation/CVD_Simulation.html
+var cvdMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// Simulate returns the color as someone with the given deficiency sees it. Unknown
+// deficiencies return c unchanged.
+func Simulate(c RGB, d Deficiency) RGB {
+	m, ok := cvdMatrices[d]
+	if !ok {
+		return c
+	}
+	r, g, b := c.Linear()
+	return FromLinear(
+		m[0][0]*r+m[0][1]*g+m[0][2]*b,
+		m[1][0]*r+m[1][1]*g+m[1][2]*b,
+		m[2][0]*r+m[2][1]*g+m[2][2]*b,
+	).Clamp()
+}
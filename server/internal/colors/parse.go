@@ -0,0 +1,146 @@
+package colors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RGBA is a color with straight (non-premultiplied) alpha in [0, 1].
+type RGBA struct {
+	RGB
+	A float64
+}
+
+// Opaque reports whether the color covers whatever is beneath it.
+func (c RGBA) Opaque() bool {
+	return c.A >= 1
+}
+
+// Over composites the color onto an opaque backdrop. Blending is done on the
+// gamma-encoded channels, as Qt and every compositor DMS runs on do, so the result is
+// the color that actually reaches the screen.
+func (c RGBA) Over(bg RGB) RGB {
+	if c.Opaque() {
+		return c.RGB
+	}
+	blend := func(fg, bg float64) float64 { return fg*c.A + bg*(1-c.A) }
+	return RGB{R: blend(c.R, bg.R), G: blend(c.G, bg.G), B: blend(c.B, bg.B)}
+}
+
+// HexAlpha is where an eight-digit hex color keeps its alpha. Qt reads #AARRGGBB and
+// CSS #RRGGBBAA, and a value alone can't say which its author meant.
+type HexAlpha int
+
+const (
+	// HexAlphaUnknown refuses eight-digit hex rather than guess at it.
+	HexAlphaUnknown HexAlpha = iota
+	// HexAlphaFirst reads #AARRGGBB, as QML does.
+	HexAlphaFirst
+	// HexAlphaLast reads #RRGGBBAA, as CSS does.
+	HexAlphaLast
+)
+
+// ParseColor reads the color notations theme authors use:
+//
+//   - #RGB and #RRGGBB
+//   - rgb(r, g, b) and rgba(r, g, b, a), with comma or space separators, channels as
+//     0-255 or percentages and alpha as 0-1 or a percentage, optionally after a slash
+//   - rgb(RRGGBB) and rgba(RRGGBBAA), the Hyprland hex form
+//
+// Eight-digit hex is refused, as its alpha could be at either end; ParseColorHexAlpha
+// reads it when the order is known.
+func ParseColor(s string) (RGBA, bool) {
+	return ParseColorHexAlpha(s, HexAlphaUnknown)
+}
+
+// ParseColorHexAlpha is ParseColor reading eight-digit hex in the given order.
+func ParseColorHexAlpha(s string, order HexAlpha) (RGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if IsEightDigitHex(s) {
+		n, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return RGBA{}, false
+		}
+		switch order {
+		case HexAlphaFirst:
+			return RGBA{RGB: bytesRGB(n), A: float64(n>>24&0xff) / 255}, true
+		case HexAlphaLast:
+			return RGBA{RGB: bytesRGB(n >> 8), A: float64(n&0xff) / 255}, true
+		default:
+			return RGBA{}, false
+		}
+	}
+	if strings.HasPrefix(s, "#") {
+		c, ok := ParseHex(s)
+		return RGBA{RGB: c, A: 1}, ok
+	}
+
+	var args string
+	switch {
+	case strings.HasPrefix(s, "rgba(") && strings.HasSuffix(s, ")"):
+		args = s[len("rgba(") : len(s)-1]
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		args = s[len("rgb(") : len(s)-1]
+	default:
+		return RGBA{}, false
+	}
+	args = strings.TrimSpace(args)
+
+	if len(args) == 6 || len(args) == 8 {
+		if n, err := strconv.ParseUint(args, 16, 32); err == nil {
+			if len(args) == 6 {
+				return RGBA{RGB: bytesRGB(n), A: 1}, true
+			}
+			return RGBA{RGB: bytesRGB(n >> 8), A: float64(n&0xff) / 255}, true
+		}
+	}
+
+	fields := strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+	if len(fields) != 3 && len(fields) != 4 {
+		return RGBA{}, false
+	}
+	var channels [3]float64
+	for i := range channels {
+		v, ok := parseComponent(fields[i], 255)
+		if !ok {
+			return RGBA{}, false
+		}
+		channels[i] = v
+	}
+	c := RGBA{RGB: RGB{R: channels[0], G: channels[1], B: channels[2]}, A: 1}
+	if len(fields) == 4 {
+		a, ok := parseComponent(fields[3], 1)
+		if !ok {
+			return RGBA{}, false
+		}
+		c.A = a
+	}
+	return c, true
+}
+
+// IsEightDigitHex reports whether s is written as #XXXXXXXX, whose alpha position
+// ParseColor won't guess.
+func IsEightDigitHex(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) == 9 && s[0] == '#'
+}
+
+// parseComponent reads a number on a 0..scale range, or a percentage, into [0, 1].
+func parseComponent(s string, scale float64) (float64, bool) {
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		s, scale = pct, 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || v > scale {
+		return 0, false
+	}
+	return v / scale, true
+}
+
+func bytesRGB(n uint64) RGB {
+	return RGB{
+		R: float64(n>>16&0xff) / 255,
+		G: float64(n>>8&0xff) / 255,
+		B: float64(n&0xff) / 255,
+	}
+}
@@ -0,0 +1,55 @@
+package colors
+
+import "math"
+
+// Tone is the CIELAB L* of the color, 0 for black to 100 for white. It is the tone axis
+// of Material 3's tonal palettes: tones 50 apart clear 4.5:1 contrast whatever the hue.
+func (c RGB) Tone() float64 {
+	return lstarFromY(c.Luminance())
+}
+
+// https://en.wikipedia.org/wiki/CIELAB_color_space#From_CIEXYZ_to_CIELAB
+func lstarFromY(y float64) float64 {
+	const e = 216.0 / 24389
+	if y <= e {
+		return y * 24389 / 27
+	}
+	return 116*math.Cbrt(y) - 16
+}
+
+func yFromLstar(lstar float64) float64 {
+	if lstar > 8 {
+		return math.Pow((lstar+16)/116, 3)
+	}
+	return lstar * 27 / 24389
+}
+
+// TonalPalette is a hue and chroma from which any tone can be taken, the building
+// block of Material 3 color schemes.
+type TonalPalette struct {
+	Hue    float64
+	Chroma float64
+}
+
+// Tone returns the color at the given L*, keeping the palette's OKLCH hue and reducing
+// chroma where sRGB cannot reach it at that tone.
+func (p TonalPalette) Tone(tone float64) RGB {
+	if tone <= 0 {
+		return RGB{}
+	}
+	if tone >= 100 {
+		return RGB{R: 1, G: 1, B: 1}
+	}
+
+	target := yFromLstar(tone)
+	lo, hi := 0.0, 1.0
+	for range 32 {
+		mid := (lo + hi) / 2
+		if (OKLCH{L: mid, C: p.Chroma, H: p.Hue}).RGB().Luminance() < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return OKLCH{L: (lo + hi) / 2, C: p.Chroma, H: p.Hue}.RGB()
+}
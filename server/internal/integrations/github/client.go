@@ -3,12 +3,18 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+// ErrNotFound is returned when the API answers 404, which callers treat as "absent"
+// rather than as a failure (e.g. a repository without releases).
+var ErrNotFound = errors.New("not found")
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
@@ -52,6 +58,8 @@ func (c *Client) do(ctx context.Context, method, path string) ([]byte, error) {
 			lastErr = err
 		case status == http.StatusOK:
 			return body, nil
+		case status == http.StatusNotFound:
+			return nil, ErrNotFound
 		default:
 			lastErr = fmt.Errorf("unexpected status code: %d", status)
 			if !retryableStatus(status) {
@@ -112,7 +120,16 @@ type RepoContent struct {
 }
 
 func (c *Client) GetRepoContents(ctx context.Context, owner, repo, path string) ([]RepoContent, error) {
+	return c.GetRepoContentsAt(ctx, owner, repo, path, "")
+}
+
+// GetRepoContentsAt lists contents at a branch, tag or commit; an empty ref reads the
+// default branch.
+func (c *Client) GetRepoContentsAt(ctx context.Context, owner, repo, path, ref string) ([]RepoContent, error) {
 	apiPath := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, path)
+	if ref != "" {
+		apiPath += "?ref=" + url.QueryEscape(ref)
+	}
 
 	body, err := c.do(ctx, http.MethodGet, apiPath)
 	if err != nil {
@@ -160,11 +177,17 @@ func (c *Client) GetFileContents(ctx context.Context, url string) ([]byte, error
 }
 
 type Commit struct {
+	SHA    string `json:"sha"`
 	Commit struct {
 		Committer struct {
 			Date time.Time `json:"date"`
 		} `json:"committer"`
 	} `json:"commit"`
+	// Author is the GitHub account the commit's author email belongs to, nil when it
+	// belongs to none.
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
 }
 
 type Issue struct {
@@ -209,7 +232,16 @@ func (c *Client) ListIssues(ctx context.Context, owner, repo, label string) ([]I
 }
 
 func (c *Client) GetLastCommit(ctx context.Context, owner, repo, path string) (*Commit, error) {
+	return c.GetLastCommitAt(ctx, owner, repo, path, "")
+}
+
+// GetLastCommitAt returns the newest commit touching path reachable from ref; an empty
+// ref walks the default branch.
+func (c *Client) GetLastCommitAt(ctx context.Context, owner, repo, path, ref string) (*Commit, error) {
 	apiPath := fmt.Sprintf("/repos/%s/%s/commits?per_page=1", owner, repo)
+	if ref != "" {
+		apiPath += "&sha=" + url.QueryEscape(ref)
+	}
 	if path != "" {
 		apiPath += fmt.Sprintf("&path=%s", path)
 	}
@@ -230,3 +262,57 @@ func (c *Client) GetLastCommit(ctx context.Context, owner, repo, path string) (*
 
 	return &commits[0], nil
 }
+
+// GetFirstCommit returns the oldest commit touching path on the default branch, which
+// for a directory is the one that added it.
+func (c *Client) GetFirstCommit(ctx context.Context, owner, repo, path string) (*Commit, error) {
+	const perPage = 100
+	var oldest *Commit
+	for page := 1; ; page++ {
+		apiPath := fmt.Sprintf("/repos/%s/%s/commits?per_page=%d&page=%d&path=%s", owner, repo, perPage, page, path)
+		body, err := c.do(ctx, http.MethodGet, apiPath)
+		if err != nil {
+			return nil, err
+		}
+
+		var commits []Commit
+		if err := json.Unmarshal(body, &commits); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal commits: %w", err)
+		}
+		if len(commits) > 0 {
+			oldest = &commits[len(commits)-1]
+		}
+		if len(commits) < perPage {
+			break
+		}
+	}
+
+	if oldest == nil {
+		return nil, fmt.Errorf("no commits found")
+	}
+	return oldest, nil
+}
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// GetLatestRelease returns the newest non-draft, non-prerelease release. GitHub and
+// Forgejo/Gitea share this endpoint and payload shape. Returns ErrNotFound when the
+// repository has no releases.
+func (c *Client) GetLatestRelease(ctx context.Context, owner, repo string) (*Release, error) {
+	body, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/releases/latest", owner, repo))
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release: %w", err)
+	}
+	return &release, nil
+}
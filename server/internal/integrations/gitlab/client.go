@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,8 @@ import (
 	"time"
 )
 
+var ErrNotFound = errors.New("not found")
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
@@ -42,6 +45,9 @@ func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -60,29 +66,67 @@ func (c *Client) GetRawFile(ctx context.Context, project, filePath, ref string)
 	return c.get(ctx, path)
 }
 
-type commit struct {
+type Commit struct {
+	ID            string    `json:"id"`
 	CommittedDate time.Time `json:"committed_date"`
 }
 
-func (c *Client) GetLastCommitDate(ctx context.Context, project, path string) (time.Time, error) {
+// GetLastCommit returns the newest commit touching path reachable from ref; an empty
+// ref walks the default branch.
+func (c *Client) GetLastCommit(ctx context.Context, project, path, ref string) (Commit, error) {
 	apiPath := fmt.Sprintf("/projects/%s/repository/commits?per_page=1", url.PathEscape(project))
+	if ref != "" {
+		apiPath += "&ref_name=" + url.QueryEscape(ref)
+	}
 	if path != "" {
 		apiPath += "&path=" + url.QueryEscape(path)
 	}
 
 	body, err := c.get(ctx, apiPath)
 	if err != nil {
-		return time.Time{}, err
+		return Commit{}, err
 	}
 
-	var commits []commit
+	var commits []Commit
 	if err := json.Unmarshal(body, &commits); err != nil {
-		return time.Time{}, fmt.Errorf("failed to unmarshal commits: %w", err)
+		return Commit{}, fmt.Errorf("failed to unmarshal commits: %w", err)
 	}
 
 	if len(commits) == 0 {
-		return time.Time{}, fmt.Errorf("no commits found")
+		return Commit{}, fmt.Errorf("no commits found")
+	}
+
+	return commits[0], nil
+}
+
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// GetLatestRelease returns the most recently released entry, or ErrNotFound when the
+// project has none.
+func (c *Client) GetLatestRelease(ctx context.Context, project string) (*Release, error) {
+	apiPath := fmt.Sprintf("/projects/%s/releases?per_page=1&order_by=released_at&sort=desc", url.PathEscape(project))
+
+	body, err := c.get(ctx, apiPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal releases: %w", err)
+	}
+
+	if len(releases) == 0 {
+		return nil, ErrNotFound
 	}
 
-	return commits[0].CommittedDate, nil
+	return &releases[0], nil
 }
@@ -3,21 +3,24 @@ package models
 import "time"
 
 type RegistryPlugin struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Capabilities []string `json:"capabilities"`
-	Category     string   `json:"category"`
-	Repo         string   `json:"repo"`
-	Path         string   `json:"path,omitempty"`
-	Author       string   `json:"author"`
-	FirstParty   bool     `json:"firstParty,omitempty"`
-	Featured     bool     `json:"featured,omitempty"`
-	Description  string   `json:"description"`
-	Dependencies []string `json:"dependencies"`
-	Compositors  []string `json:"compositors"`
-	Distro       []string `json:"distro"`
-	Screenshot   string   `json:"screenshot,omitempty"`
-	RequiresDMS  string   `json:"requires_dms,omitempty"`
+	ID            string   `json:"id"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Name          string   `json:"name"`
+	Capabilities  []string `json:"capabilities"`
+	Category      string   `json:"category"`
+	Repo          string   `json:"repo"`
+	Path          string   `json:"path,omitempty"`
+	Ref           string   `json:"ref,omitempty"`
+	LatestRelease bool     `json:"latestRelease,omitempty"`
+	Author        string   `json:"author"`
+	FirstParty    bool     `json:"firstParty,omitempty"`
+	Featured      bool     `json:"featured,omitempty"`
+	Description   string   `json:"description"`
+	Dependencies  []string `json:"dependencies"`
+	Compositors   []string `json:"compositors"`
+	Distro        []string `json:"distro"`
+	Screenshot    string   `json:"screenshot,omitempty"`
+	RequiresDMS   string   `json:"requires_dms,omitempty"`
 }
 
 type PluginMetadata struct {
@@ -35,6 +38,7 @@ type PluginMetadata struct {
 
 type Plugin struct {
 	ID           string    `json:"id"`
+	Aliases      []string  `json:"aliases,omitempty"`
 	Name         string    `json:"name"`
 	Capabilities []string  `json:"capabilities"`
 	Category     string    `json:"category"`
@@ -52,6 +56,9 @@ type Plugin struct {
 	Version      string    `json:"version"`
 	Icon         string    `json:"icon,omitempty"`
 	Permissions  []string  `json:"permissions,omitempty"`
+	Ref          string    `json:"ref,omitempty"`
+	CommitSHA    string    `json:"commitSha,omitempty"`
+	Release      *Release  `json:"release,omitempty"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	Upvotes      int       `json:"upvotes"`
@@ -61,6 +68,14 @@ type Plugin struct {
 	Similar      []string  `json:"similar,omitempty"`
 }
 
+type Release struct {
+	Tag         string    `json:"tag"`
+	Name        string    `json:"name,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
 type ThemeVariantOption struct {
 	ID    string                 `json:"id"`
 	Name  string                 `json:"name"`
@@ -108,28 +123,112 @@ type ThemeWCAGBreakdown struct {
 	BodyLevel string `json:"bodyLevel"`
 }
 
+type ThemeWCAGPairRatio struct {
+	Pair  []string `json:"pair"`
+	Ratio float64  `json:"ratio"`
+}
+
+type ThemeWCAGSuggestion struct {
+	Pair      []string             `json:"pair"`
+	Token     string               `json:"token"`
+	Current   string               `json:"current"`
+	Suggested string               `json:"suggested"`
+	Ratio     float64              `json:"ratio"`
+	NewRatio  float64              `json:"newRatio"`
+	Target    float64              `json:"target"`
+	Reachable bool                 `json:"reachable"`
+	Breaks    []ThemeWCAGPairRatio `json:"breaks,omitempty"`
+}
+
+type ThemeAPCAGroup struct {
+	Level         string   `json:"level"`
+	MinLc         float64  `json:"minLc"`
+	WorstPair     []string `json:"worstPair,omitempty"`
+	MinFontPx     int      `json:"minFontPx,omitempty"`
+	MinBoldFontPx int      `json:"minBoldFontPx,omitempty"`
+}
+
+type ThemeAPCAMode struct {
+	Level     string          `json:"level"`
+	MinLc     float64         `json:"minLc"`
+	WorstPair []string        `json:"worstPair,omitempty"`
+	Body      *ThemeAPCAGroup `json:"body,omitempty"`
+	Accent    *ThemeAPCAGroup `json:"accent,omitempty"`
+}
+
+type ThemeAPCA struct {
+	Level string  `json:"level"`
+	MinLc float64 `json:"minLc"`
+}
+
+type ThemeWCAGUnevaluated struct {
+	Token  string `json:"token"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ThemeCVDPair struct {
+	Pair       []string `json:"pair"`
+	Deficiency string   `json:"deficiency"`
+	Distance   float64  `json:"distance"`
+	Configs    []string `json:"configs,omitempty"`
+}
+
+type ThemeCVDMode struct {
+	Distinguishable bool           `json:"distinguishable"`
+	MinDistance     float64        `json:"minDistance"`
+	WorstPair       []string       `json:"worstPair,omitempty"`
+	WorstDeficiency string         `json:"worstDeficiency,omitempty"`
+	Flagged         []ThemeCVDPair `json:"flagged,omitempty"`
+}
+
 type ThemeWCAGMode struct {
 	Level     string            `json:"level"`
+	Rules     string            `json:"rules,omitempty"`
 	MinRatio  float64           `json:"minRatio"`
 	WorstPair []string          `json:"worstPair,omitempty"`
 	Body      *ThemeWCAGGroup   `json:"body,omitempty"`
 	Accent    *ThemeWCAGGroup   `json:"accent,omitempty"`
 	NonText   *ThemeWCAGGroup   `json:"nonText,omitempty"`
 	Variants  map[string]string `json:"variants,omitempty"`
+	APCA      *ThemeAPCAMode    `json:"apca,omitempty"`
+	CVD       *ThemeCVDMode     `json:"cvd,omitempty"`
+
+	Breakdown   []ThemeWCAGBreakdown   `json:"breakdown,omitempty"`
+	Suggestions []ThemeWCAGSuggestion  `json:"suggestions,omitempty"`
+	Unevaluated []ThemeWCAGUnevaluated `json:"unevaluated,omitempty"`
+}
+
+type ThemeContrastCheck struct {
+	Pair  []string `json:"pair"`
+	Group string   `json:"group"`
+	Ratio float64  `json:"ratio"`
+	Lc    float64  `json:"lc"`
+	Level string   `json:"level"`
+}
 
-	Breakdown []ThemeWCAGBreakdown `json:"breakdown,omitempty"`
+type ThemeContrastMatrix struct {
+	Rules       string                 `json:"rules"`
+	Tokens      []string               `json:"tokens"`
+	Ratios      [][]*float64           `json:"ratios"`
+	Lc          [][]*float64           `json:"lc"`
+	Checked     []ThemeContrastCheck   `json:"checked"`
+	Unevaluated []ThemeWCAGUnevaluated `json:"unevaluated,omitempty"`
 }
 
 type ThemeWCAG struct {
 	Level string         `json:"level"`
+	Rules string         `json:"rules,omitempty"`
 	Dark  *ThemeWCAGMode `json:"dark,omitempty"`
 	Light *ThemeWCAGMode `json:"light,omitempty"`
+	APCA  *ThemeAPCA     `json:"apca,omitempty"`
 }
 
 type Theme struct {
 	ID          string                 `json:"id"`
 	Name        string                 `json:"name"`
 	Author      string                 `json:"author"`
+	Submitter   string                 `json:"submitter,omitempty"` // GitHub login that added the theme to the registry
 	Description string                 `json:"description"`
 	Version     string                 `json:"version"`
 	Dark        map[string]interface{} `json:"dark"`
@@ -137,5 +236,79 @@ type Theme struct {
 	Variants    *ThemeVariants         `json:"variants,omitempty"`
 	WCAG        *ThemeWCAG             `json:"wcag,omitempty"`
 	PreviewURL  string                 `json:"previewUrl"`
+	CommitSHA   string                 `json:"commitSha,omitempty"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+	Upvotes     int                    `json:"upvotes"`
+	IssueURL    string                 `json:"issueUrl,omitempty"`
+	IssueNumber int                    `json:"issueNumber,omitempty"`
+	Status      []string               `json:"status,omitempty"`
+}
+
+type SimilarTheme struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Author       string  `json:"author"`
+	PreviewURL   string  `json:"previewUrl"`
+	Distance     float64 `json:"distance"`
+	Config       string  `json:"config"`
+	SourceConfig string  `json:"sourceConfig,omitempty"`
+}
+
+type ThemeRevisionConfig struct {
+	Mode   string                 `json:"mode"`
+	Label  string                 `json:"label"`
+	Colors map[string]interface{} `json:"colors"`
+}
+
+type ThemeWCAGSummary struct {
+	Level    string  `json:"level"`
+	MinRatio float64 `json:"minRatio"`
+}
+
+type ThemeRevisionWCAG struct {
+	Level string            `json:"level"`
+	Rules string            `json:"rules,omitempty"`
+	Dark  *ThemeWCAGSummary `json:"dark,omitempty"`
+	Light *ThemeWCAGSummary `json:"light,omitempty"`
+}
+
+type ThemeRevision struct {
+	Version    string                `json:"version"`
+	CommitSHA  string                `json:"commitSha,omitempty"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+	RecordedAt time.Time             `json:"recorded_at"`
+	Configs    []ThemeRevisionConfig `json:"configs"`
+	WCAG       *ThemeRevisionWCAG    `json:"wcag,omitempty"`
+}
+
+type ThemeTokenChange struct {
+	Mode   string      `json:"mode"`
+	Config string      `json:"config"`
+	Token  string      `json:"token"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+type ThemeContrastRegression struct {
+	Mode      string   `json:"mode"`
+	Config    string   `json:"config"`
+	Pair      []string `json:"pair"`
+	Group     string   `json:"group"`
+	FromRatio float64  `json:"fromRatio"`
+	ToRatio   float64  `json:"toRatio"`
+	FromLevel string   `json:"fromLevel"`
+	ToLevel   string   `json:"toLevel"`
+}
+
+type ThemeHistoryEntry struct {
+	Version        string                    `json:"version"`
+	CommitSHA      string                    `json:"commitSha,omitempty"`
+	UpdatedAt      time.Time                 `json:"updated_at"`
+	RecordedAt     time.Time                 `json:"recorded_at"`
+	WCAG           *ThemeRevisionWCAG        `json:"wcag,omitempty"`
+	Regressed      bool                      `json:"regressed"`
+	ConfigsAdded   []string                  `json:"configsAdded,omitempty"`
+	ConfigsRemoved []string                  `json:"configsRemoved,omitempty"`
+	Changes        []ThemeTokenChange        `json:"changes"`
+	Regressions    []ThemeContrastRegression `json:"regressions"`
 }
@@ -0,0 +1,176 @@
+package docs
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned for a path with no page behind it, or one that could not
+// name a page at all.
+var ErrNotFound = errors.New("docs page not found")
+
+var segmentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// sectionNames labels sections whose directory has no index page to take a title from.
+var sectionNames = map[string]string{
+	"dankmaterialshell": "DankMaterialShell",
+}
+
+// siteName labels the pages outside any section.
+const siteName = "Dank Linux"
+
+// Page is what a docs page's frontmatter says about it.
+type Page struct {
+	Path        string // as served under /docs, "index" for the landing page
+	Title       string
+	Description string
+	SectionID   string // the directory the page is in or indexes, empty at the top level
+	Section     string // the section's display name
+}
+
+// Source reads pages from a checkout of the repo's docs directory.
+type Source struct {
+	root string
+}
+
+func NewSource(dir string) *Source {
+	return &Source{root: dir}
+}
+
+// Page finds the page at path, which may carry a leading "docs/" and a .md or .mdx
+// extension as links to it often do.
+func (s *Source) Page(path string) (Page, error) {
+	clean, ok := cleanPath(path)
+	if !ok {
+		return Page{}, ErrNotFound
+	}
+
+	root, err := os.OpenRoot(s.root)
+	if err != nil {
+		return Page{}, err
+	}
+	defer root.Close()
+
+	meta, err := readPage(root, clean)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{Path: clean, Title: meta["title"], Description: meta["description"], Section: siteName}
+	if dir, _, _ := strings.Cut(clean, "/"); isDir(root, dir) {
+		page.SectionID = dir
+		page.Section = sectionName(root, dir)
+	}
+	if page.Title == "" {
+		page.Title = page.Section
+	}
+	return page, nil
+}
+
+func cleanPath(path string) (string, bool) {
+	path = strings.Trim(path, "/")
+	path = strings.TrimPrefix(path, "docs/")
+	path = strings.TrimSuffix(path, ".mdx")
+	path = strings.TrimSuffix(path, ".md")
+	path = strings.TrimSuffix(path, "/index")
+	if path == "" || path == "docs" {
+		return "index", true
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if !segmentPattern.MatchString(seg) {
+			return "", false
+		}
+	}
+	return path, true
+}
+
+// readPage reads the frontmatter of the file behind a page path, trying it as a file
+// before as a directory's index.
+func readPage(root *os.Root, path string) (map[string]string, error) {
+	for _, name := range []string{path + ".mdx", path + ".md", path + "/index.mdx", path + "/index.md"} {
+		meta, err := readFrontmatter(root, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return meta, err
+	}
+	return nil, ErrNotFound
+}
+
+func isDir(root *os.Root, name string) bool {
+	info, err := root.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+func sectionName(root *os.Root, dir string) string {
+	if name, ok := sectionNames[dir]; ok {
+		return name
+	}
+	if meta, err := readPage(root, dir); err == nil && meta["title"] != "" {
+		return meta["title"]
+	}
+	return dir
+}
+
+// readFrontmatter reads the flat key: value pairs between a file's opening --- lines.
+// Nested and list values are skipped, as nothing here needs them. A file without a
+// title in its frontmatter takes its first top-level heading.
+func readFrontmatter(root *os.Root, name string) (map[string]string, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	meta := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	inFrontmatter := false
+	for line := 0; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == 0 && text == "---" {
+			inFrontmatter = true
+			continue
+		}
+		if inFrontmatter {
+			if text == "---" {
+				inFrontmatter = false
+				continue
+			}
+			key, value, ok := strings.Cut(text, ":")
+			if !ok || key != strings.TrimSpace(key) || key == "" {
+				continue
+			}
+			meta[key] = unquote(strings.TrimSpace(value))
+			continue
+		}
+		if meta["title"] != "" {
+			break
+		}
+		if heading, ok := strings.CutPrefix(text, "# "); ok {
+			meta["title"] = strings.TrimSpace(heading)
+			break
+		}
+	}
+	return meta, scanner.Err()
+}
+
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch value[0] {
+	case '"':
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	case '\'':
+		if value[len(value)-1] == '\'' {
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	return value
+}
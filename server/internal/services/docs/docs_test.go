@@ -0,0 +1,66 @@
+package docs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeDocs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPageReadsFrontmatter(t *testing.T) {
+	src := NewSource(writeDocs(t, map[string]string{
+		"index.mdx":                   "---\ntitle: Welcome\ndescription: Welcome to Dank Linux\n---\n\n# Not this\n",
+		"dgop/index.mdx":              "---\ntitle: DGOP\n---\n",
+		"dgop/usage.mdx":              "---\ntitle: \"Usage: the CLI\"\ndescription: 'Flags, and what''s shown'\nsidebar_position: 2\n---\n",
+		"dankmaterialshell/layers.md": "---\nsidebar_position: 3\n---\n\n# Layers\n\nBody.\n",
+	}))
+
+	cases := []struct {
+		path string
+		want Page
+	}{
+		{"", Page{Path: "index", Title: "Welcome", Description: "Welcome to Dank Linux", Section: "Dank Linux"}},
+		{"/docs/", Page{Path: "index", Title: "Welcome", Description: "Welcome to Dank Linux", Section: "Dank Linux"}},
+		{"dgop", Page{Path: "dgop", Title: "DGOP", SectionID: "dgop", Section: "DGOP"}},
+		{"docs/dgop/usage.mdx", Page{Path: "dgop/usage", Title: "Usage: the CLI", Description: "Flags, and what's shown", SectionID: "dgop", Section: "DGOP"}},
+		{"dankmaterialshell/layers", Page{Path: "dankmaterialshell/layers", Title: "Layers", SectionID: "dankmaterialshell", Section: "DankMaterialShell"}},
+	}
+	for _, c := range cases {
+		got, err := src.Page(c.path)
+		if err != nil {
+			t.Fatalf("Page(%q): %v", c.path, err)
+		}
+		if got != c.want {
+			t.Errorf("Page(%q) = %+v, want %+v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestPageRejectsUnknownAndEscapingPaths(t *testing.T) {
+	dir := writeDocs(t, map[string]string{"docs/index.mdx": "---\ntitle: Welcome\n---\n"})
+	if err := os.WriteFile(filepath.Join(dir, "secret.md"), []byte("# Secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := NewSource(filepath.Join(dir, "docs"))
+
+	for _, path := range []string{"missing", "../secret", "dgop/../../secret", "%2e%2e/secret", ".hidden"} {
+		if _, err := src.Page(path); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Page(%q): expected ErrNotFound, got %v", path, err)
+		}
+	}
+}
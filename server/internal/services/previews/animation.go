@@ -0,0 +1,329 @@
+package previews
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/webp"
+)
+
+const (
+	maxAnimationFrames = 100
+	// maxAnimationPixels bounds the canvas pixels decoded across all frames, some 100MB
+	// of NRGBA. A longer animation is cut short rather than refused.
+	maxAnimationPixels = 24 << 20
+	// maxAnimationBytes is the largest encoded animation kept; past it the static
+	// preview is all there is.
+	maxAnimationBytes = 16 << 20
+
+	// Browsers show frames meant for less than minFrameDuration milliseconds for
+	// defaultFrameDuration instead; previews play at the speed the screenshot does.
+	minFrameDuration     = 20
+	defaultFrameDuration = 100
+)
+
+var errGIFTruncated = errors.New("gif: truncated block")
+
+// animation is a screenshot's frames, each covering the whole canvas.
+type animation struct {
+	frames    []image.Image
+	durations []int // milliseconds
+	plays     int   // 0 plays forever
+}
+
+// decodeAnimation decodes the frames of an animated GIF or WebP, as many as the frame
+// and pixel limits allow, or returns nil for anything that does not move.
+func decodeAnimation(data []byte) (*animation, error) {
+	var (
+		anim *animation
+		err  error
+	)
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		anim, err = decodeGIFAnimation(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		anim, err = decodeWebPAnimation(data)
+	}
+	if err != nil || anim == nil || len(anim.frames) < 2 {
+		return nil, err
+	}
+	for i, d := range anim.durations {
+		if d < minFrameDuration {
+			anim.durations[i] = defaultFrameDuration
+		}
+	}
+	return anim, nil
+}
+
+// frameBudget is how many frames of a canvas fit the limits.
+func frameBudget(width, height int) int {
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return 0
+	}
+	return min(maxAnimationFrames, maxAnimationPixels/(width*height))
+}
+
+func decodeWebPAnimation(data []byte) (*animation, error) {
+	cfg, _, err := webp.AnimationConfig(data)
+	if errors.Is(err, webp.ErrNotAnimated) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	budget := frameBudget(cfg.Width, cfg.Height)
+	if budget < 2 {
+		return nil, nil
+	}
+	decoded, err := webp.DecodeAll(data, budget)
+	if err != nil {
+		return nil, err
+	}
+	return &animation{frames: decoded.Frames, durations: decoded.Durations, plays: decoded.LoopCount}, nil
+}
+
+func decodeGIFAnimation(data []byte) (*animation, error) {
+	cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	ends, err := gifFrameEnds(data)
+	if err != nil {
+		return nil, err
+	}
+	budget := frameBudget(cfg.Width, cfg.Height)
+	if len(ends) < 2 || budget < 2 {
+		return nil, nil
+	}
+	// Cut the file after the last frame within budget, so the rest is never decoded.
+	if len(ends) > budget {
+		cut := ends[budget-1]
+		data = append(data[:cut:cut], 0x3b)
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	plays := 0
+	switch {
+	case g.LoopCount < 0:
+		plays = 1
+	case g.LoopCount > 0:
+		plays = g.LoopCount + 1
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, cfg.Width, cfg.Height))
+	anim := &animation{plays: plays}
+	for i, frame := range g.Image {
+		var saved []byte
+		if g.Disposal[i] == gif.DisposalPrevious {
+			saved = bytes.Clone(canvas.Pix)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		snapshot := image.NewNRGBA(canvas.Rect)
+		copy(snapshot.Pix, canvas.Pix)
+		anim.frames = append(anim.frames, snapshot)
+		anim.durations = append(anim.durations, g.Delay[i]*10)
+
+		switch g.Disposal[i] {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, saved)
+		}
+	}
+	return anim, nil
+}
+
+// gifFrameEnds walks a GIF's blocks without decompressing any, returning the offset
+// just past each frame's image data.
+func gifFrameEnds(data []byte) ([]int, error) {
+	if len(data) < 13 {
+		return nil, errGIFTruncated
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1)
+	}
+	skipSubBlocks := func() bool {
+		for pos < len(data) {
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ends []int
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension: label, then sub-blocks
+			pos += 2
+			if !skipSubBlocks() {
+				return nil, errGIFTruncated
+			}
+		case 0x2c: // image descriptor, optional local color table, LZW code size
+			if pos+10 > len(data) {
+				return nil, errGIFTruncated
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1)
+			}
+			pos++
+			if !skipSubBlocks() {
+				return nil, errGIFTruncated
+			}
+			ends = append(ends, pos)
+		case 0x3b:
+			return ends, nil
+		default:
+			return nil, fmt.Errorf("gif: unknown block 0x%02x", data[pos])
+		}
+	}
+	return ends, nil
+}
+
+func animationKey(id, ext string) string {
+	return id + "@animated-" + ext
+}
+
+// LookupAnimation serves the animated preview kept for id, as WebP when the client
+// takes it and GIF otherwise. It reports false for a preview whose screenshot does not
+// move, or whose animation has yet to be made.
+func (s *Store) LookupAnimation(id string, asWebP bool) (string, string, bool) {
+	parent, ok := s.entry(id)
+	if !ok {
+		return "", "", false
+	}
+	ext := "gif"
+	if asWebP {
+		ext = "webp"
+	}
+	return s.renditionEntry(animationKey(id, ext), parent.SourceKey)
+}
+
+// keepAnimation composes every frame of an animated screenshot into the card and keeps
+// the result as WebP and GIF beside the static preview, which shows the first frame.
+// Anything that does not move is left at that. One animation is made at a time, as
+// its frames take far more memory than a still.
+func (g *Generator) keepAnimation(p models.Plugin, sourceKey string, data []byte) {
+	g.animationMu.Lock()
+	defer g.animationMu.Unlock()
+
+	anim, err := decodeAnimation(data)
+	if err != nil {
+		log.Warnf("Animated screenshot for %s could not be decoded: %v", p.ID, err)
+		return
+	}
+	if anim == nil {
+		return
+	}
+	frames, err := composeScreenshotFrames(anim.frames, p, DarkPalette)
+	if err != nil {
+		log.Warnf("Animated preview composition failed for %s: %v", p.ID, err)
+		return
+	}
+	anim.frames = frames
+
+	encoders := map[string]func(*animation) ([]byte, error){
+		"webp": encodeAnimatedWebP,
+		"gif":  encodeAnimatedGIF,
+	}
+	for ext, encode := range encoders {
+		out, err := encode(anim)
+		if err == nil && len(out) > maxAnimationBytes {
+			err = fmt.Errorf("%d bytes exceeds the %d byte limit", len(out), maxAnimationBytes)
+		}
+		if err == nil {
+			err = g.store.putDerived(animationKey(p.ID, ext), p.ID, "animation", sourceKey, ext, out)
+		}
+		if err != nil {
+			log.Warnf("Animated %s preview for %s not kept: %v", ext, p.ID, err)
+		}
+	}
+}
+
+func encodeAnimatedWebP(anim *animation) ([]byte, error) {
+	var buf bytes.Buffer
+	err := webp.EncodeAll(&buf, &webp.Animation{Frames: anim.frames, Durations: anim.durations, LoopCount: anim.plays})
+	return buf.Bytes(), err
+}
+
+// encodeAnimatedGIF quantizes each frame to the Plan 9 palette with dithering. Past the
+// first, a frame covers only what changed, and one that changed nothing lengthens the
+// frame before it.
+func encodeAnimatedGIF(anim *animation) ([]byte, error) {
+	out := &gif.GIF{}
+	switch anim.plays {
+	case 0:
+		out.LoopCount = 0
+	case 1:
+		out.LoopCount = -1
+	default:
+		out.LoopCount = anim.plays - 1
+	}
+
+	var prev *image.RGBA
+	for i, frame := range anim.frames {
+		cur := image.NewRGBA(frame.Bounds())
+		draw.Draw(cur, cur.Rect, frame, frame.Bounds().Min, draw.Src)
+		rect := cur.Rect
+		if prev != nil {
+			rect = changedBounds(prev, cur)
+		}
+		delay := int(math.Round(float64(anim.durations[i]) / 10))
+		if rect.Empty() {
+			out.Delay[len(out.Delay)-1] += delay
+			continue
+		}
+		prev = cur
+
+		paletted := image.NewPaletted(rect, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, rect, cur, rect.Min)
+		out.Image = append(out.Image, paletted)
+		out.Delay = append(out.Delay, delay)
+		out.Disposal = append(out.Disposal, gif.DisposalNone)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// changedBounds bounds the pixels that differ between two frames of the same size.
+func changedBounds(prev, cur *image.RGBA) image.Rectangle {
+	b := cur.Rect
+	var r image.Rectangle
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := cur.Pix[cur.PixOffset(b.Min.X, y):cur.PixOffset(b.Max.X, y)]
+		prevRow := prev.Pix[prev.PixOffset(b.Min.X, y):prev.PixOffset(b.Max.X, y)]
+		if bytes.Equal(row, prevRow) {
+			continue
+		}
+		first, last := 0, b.Dx()-1
+		for bytes.Equal(row[4*first:4*first+4], prevRow[4*first:4*first+4]) {
+			first++
+		}
+		for bytes.Equal(row[4*last:4*last+4], prevRow[4*last:4*last+4]) {
+			last--
+		}
+		r = r.Union(image.Rect(b.Min.X+first, y, b.Min.X+last+1, y+1))
+	}
+	return r
+}
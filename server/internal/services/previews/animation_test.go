@@ -0,0 +1,139 @@
+package previews
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/webp"
+)
+
+// gifBytes draws a square stepping right across a gray canvas, one frame per step.
+func gifBytes(t *testing.T, w, h, frames int) []byte {
+	t.Helper()
+	g := &gif.GIF{}
+	for i := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+		for j := range img.Pix {
+			img.Pix[j] = uint8(img.Palette.Index(color.Gray{0x80}))
+		}
+		for y := 4; y < 12; y++ {
+			for x := 4 + 8*i; x < 12+8*i && x < w; x++ {
+				img.Set(x, y, color.White)
+			}
+		}
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 1)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeAnimationCompositesGIFFrames(t *testing.T) {
+	anim, err := decodeAnimation(gifBytes(t, 64, 16, 3))
+	if err != nil || anim == nil {
+		t.Fatalf("expected an animation, got %v", err)
+	}
+	if len(anim.frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(anim.frames))
+	}
+	// A 10ms delay plays at the 100ms browsers use.
+	if anim.durations[0] != defaultFrameDuration {
+		t.Fatalf("expected a too-short delay to be raised, got %d", anim.durations[0])
+	}
+	if got := color.GrayModel.Convert(anim.frames[2].At(22, 6)).(color.Gray); got.Y != 0xff {
+		t.Fatalf("expected the third frame to show the square at its third step, got %v", got)
+	}
+
+	if still, err := decodeAnimation(gifBytes(t, 64, 16, 1)); still != nil || err != nil {
+		t.Fatalf("expected a single-frame GIF not to animate, got %v %v", still, err)
+	}
+	if still, err := decodeAnimation(pngBytes(t, 8, 8)); still != nil || err != nil {
+		t.Fatalf("expected a PNG not to animate, got %v %v", still, err)
+	}
+}
+
+func TestDecodeAnimationCutsLongAnimationsShort(t *testing.T) {
+	anim, err := decodeAnimation(gifBytes(t, 16, 16, maxAnimationFrames+5))
+	if err != nil || anim == nil {
+		t.Fatalf("expected an animation, got %v", err)
+	}
+	if len(anim.frames) != maxAnimationFrames {
+		t.Fatalf("expected %d frames, got %d", maxAnimationFrames, len(anim.frames))
+	}
+
+	// Larger canvases fit fewer frames, down to none worth animating.
+	if got := frameBudget(2048, 2048); got != 6 {
+		t.Fatalf("expected 6 frames of 2048x2048, got %d", got)
+	}
+	if got := frameBudget(maxDimension, maxDimension); got >= 2 {
+		t.Fatalf("expected no room to animate the largest canvas, got %d", got)
+	}
+}
+
+func TestSyncKeepsAnimatedScreenshots(t *testing.T) {
+	data := gifBytes(t, 96, 48, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	g, err := NewGenerator(t.TempDir(), "https://example.com")
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	g.fetcher = allowAllFetcher()
+	p := models.Plugin{ID: "anim", Name: "Anim", Screenshot: server.URL + "/anim.gif"}
+	if err := g.runJob(context.Background(), p); err != nil {
+		t.Fatalf("runJob: %v", err)
+	}
+
+	path, _, ok := g.store.LookupAnimation("anim", true)
+	if !ok || filepath.Ext(path) != ".webp" {
+		t.Fatalf("expected an animated WebP, got %q", path)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, frames, err := webp.AnimationConfig(raw)
+	if err != nil || config.Width != cardWidth || frames != 4 {
+		t.Fatalf("expected 4 card-sized frames, got %+v %d %v", config, frames, err)
+	}
+
+	path, _, ok = g.store.LookupAnimation("anim", false)
+	if !ok {
+		t.Fatal("expected an animated GIF")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := gif.DecodeAll(f)
+	f.Close()
+	if err != nil || len(decoded.Image) != 4 || decoded.Config.Width != cardWidth {
+		t.Fatalf("expected 4 card-sized GIF frames, got %v", err)
+	}
+	// Only the screenshot moves, so later frames cover part of the card.
+	if b := decoded.Image[1].Bounds(); b.Dx() >= cardWidth || b.Dy() >= cardHeight {
+		t.Fatalf("expected the second frame to cover only what changed, got %v", b)
+	}
+
+	if _, _, ok := g.store.LookupAnimation("plain", true); ok {
+		t.Fatal("expected no animation for a plugin without a preview")
+	}
+}
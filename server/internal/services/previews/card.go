@@ -9,34 +9,22 @@ import (
 	"github.com/fogleman/gg"
 )
 
-func ComposeCard(p models.Plugin) (image.Image, error) {
-	if err := loadFonts(); err != nil {
-		return nil, err
-	}
-
-	dc := newCanvas()
-	descLines, regionHeight, err := footerLayout(dc, p)
-	if err != nil {
-		return nil, err
-	}
-
-	dc.SetColor(colSurfaceContainer)
-	dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
-	dc.Fill()
+func ComposeCard(p models.Plugin, pal Palette) (image.Image, error) {
+	return pluginTemplate(p, pal).compose()
+}
 
-	if err := drawCardRegion(dc, p, regionHeight); err != nil {
-		return nil, err
-	}
-	if err := drawStatusChips(dc, p.Status); err != nil {
-		return nil, err
-	}
-	if err := drawFooter(dc, p, descLines, regionHeight); err != nil {
-		return nil, err
-	}
-	return dc.Image(), nil
+// emblem fills a card's region with an icon in a tinted circle, or the initial of
+// name where there is no icon, over a headline and an optional byline. mark, if set,
+// sits small in the region's corner.
+type emblem struct {
+	icon     string
+	name     string
+	headline string
+	byline   string
+	mark     string
 }
 
-func drawCardRegion(dc *gg.Context, p models.Plugin, regionHeight float64) error {
+func (e emblem) draw(dc *gg.Context, pal Palette, regionHeight float64) error {
 	const (
 		centerX  = cardWidth / 2.0
 		circleR  = 76.0
@@ -45,43 +33,52 @@ func drawCardRegion(dc *gg.Context, p models.Plugin, regionHeight float64) error
 	circleY := regionInset + regionHeight*0.36
 	nameY := regionInset + regionHeight*0.72
 
-	dc.SetColor(withAlpha(colPrimary, 0.20))
+	dc.SetColor(withAlpha(pal.Primary, 0.20))
 	dc.DrawCircle(centerX, circleY, circleR)
 	dc.Fill()
 
-	letterFace, err := newFace(boldFont, 96)
+	dc.SetColor(pal.Primary)
+	drawn, err := drawIcon(dc, loadIcons(), e.icon, 96, centerX, circleY)
 	if err != nil {
 		return err
 	}
-	dc.SetFontFace(letterFace)
-	dc.SetColor(colPrimary)
-	dc.DrawStringAnchored(initialLetter(p.Name), centerX, circleY, 0.5, 0.36)
+	if !drawn {
+		letterFace, err := newTextFace(boldFont, 96)
+		if err != nil {
+			return err
+		}
+		dc.SetFontFace(letterFace)
+		drawText(dc, initialLetter(e.name), centerX, circleY, 0.5, 0.36)
+	}
 
-	nameFace, err := newFace(boldFont, 44)
+	nameFace, err := newTextFace(boldFont, 44)
 	if err != nil {
 		return err
 	}
 	dc.SetFontFace(nameFace)
-	dc.SetColor(colSurfaceText)
-	dc.DrawStringAnchored(ellipsize(dc, p.Name, textMaxW), centerX, nameY, 0.5, 0.36)
+	dc.SetColor(pal.SurfaceText)
+	drawText(dc, ellipsize(dc, e.headline, textMaxW), centerX, nameY, 0.5, 0.36)
 
-	if p.Author != "" {
-		authorFace, err := newFace(regularFont, 16)
+	if e.byline != "" {
+		bylineFace, err := newTextFace(regularFont, 16)
 		if err != nil {
 			return err
 		}
-		dc.SetFontFace(authorFace)
-		dc.SetColor(colOutline)
-		dc.DrawStringAnchored("by "+p.Author, centerX, nameY+40, 0.5, 0.36)
+		dc.SetFontFace(bylineFace)
+		dc.SetColor(pal.Outline)
+		drawText(dc, e.byline, centerX, nameY+40, 0.5, 0.36)
 	}
 
+	if e.mark == "" {
+		return nil
+	}
 	markFace, err := newFace(boldFont, 14)
 	if err != nil {
 		return err
 	}
 	dc.SetFontFace(markFace)
-	dc.SetColor(withAlpha(colPrimary, 0.75))
-	dc.DrawStringAnchored("DMS", regionInset+regionWidth-16, regionInset+regionHeight-18, 1, 0.36)
+	dc.SetColor(withAlpha(pal.Primary, 0.75))
+	dc.DrawStringAnchored(e.mark, regionInset+regionWidth-16, regionInset+regionHeight-18, 1, 0.36)
 	return nil
 }
 
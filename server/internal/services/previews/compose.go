@@ -1,6 +1,7 @@
 package previews
 
 import (
+	"embed"
 	"fmt"
 	"image"
 	"image/color"
@@ -37,14 +38,63 @@ const (
 	chipGap          = 8.0
 )
 
-var (
-	colPrimary          = color.NRGBA{R: 0xD0, G: 0xBC, B: 0xFF, A: 0xFF}
-	colSurface          = color.NRGBA{R: 0x14, G: 0x12, B: 0x18, A: 0xFF}
-	colSurfaceText      = color.NRGBA{R: 0xE6, G: 0xE0, B: 0xE9, A: 0xFF}
-	colSurfaceContainer = color.NRGBA{R: 0x21, G: 0x1F, B: 0x24, A: 0xFF}
-	colOutline          = color.NRGBA{R: 0x94, G: 0x8F, B: 0x99, A: 0xFF}
-	colDescription      = color.NRGBA{R: 0xC4, G: 0xC7, B: 0xC5, A: 0xFF}
-)
+// Palette is the set of colors a plugin card is drawn with.
+type Palette struct {
+	Primary          color.NRGBA
+	Surface          color.NRGBA
+	SurfaceText      color.NRGBA
+	SurfaceContainer color.NRGBA
+	Outline          color.NRGBA
+	Description      color.NRGBA
+}
+
+// DarkPalette matches the docs site's dark theme. Stored previews are drawn with it;
+// other palettes are variants rendered on request.
+var DarkPalette = Palette{
+	Primary:          color.NRGBA{R: 0xD0, G: 0xBC, B: 0xFF, A: 0xFF},
+	Surface:          color.NRGBA{R: 0x14, G: 0x12, B: 0x18, A: 0xFF},
+	SurfaceText:      color.NRGBA{R: 0xE6, G: 0xE0, B: 0xE9, A: 0xFF},
+	SurfaceContainer: color.NRGBA{R: 0x21, G: 0x1F, B: 0x24, A: 0xFF},
+	Outline:          color.NRGBA{R: 0x94, G: 0x8F, B: 0x99, A: 0xFF},
+	Description:      color.NRGBA{R: 0xC4, G: 0xC7, B: 0xC5, A: 0xFF},
+}
+
+// LightPalette matches the docs site's light theme.
+var LightPalette = Palette{
+	Primary:          color.NRGBA{R: 0x67, G: 0x50, B: 0xA4, A: 0xFF},
+	Surface:          color.NRGBA{R: 0xFE, G: 0xF7, B: 0xFF, A: 0xFF},
+	SurfaceText:      color.NRGBA{R: 0x1D, G: 0x1B, B: 0x20, A: 0xFF},
+	SurfaceContainer: color.NRGBA{R: 0xF3, G: 0xED, B: 0xF7, A: 0xFF},
+	Outline:          color.NRGBA{R: 0x79, G: 0x74, B: 0x7E, A: 0xFF},
+	Description:      color.NRGBA{R: 0x49, G: 0x45, B: 0x4F, A: 0xFF},
+}
+
+// PaletteFromScheme reads a card palette from a resolved theme token map. Tokens the
+// scheme leaves out come from fallback.
+func PaletteFromScheme(scheme map[string]interface{}, fallback Palette) Palette {
+	pick := func(token string, fallback color.NRGBA) color.NRGBA {
+		return opaqueTokenColor(scheme, token, fallback)
+	}
+
+	p := Palette{
+		Primary:          pick("primary", fallback.Primary),
+		Surface:          pick("surface", fallback.Surface),
+		SurfaceText:      pick("surfaceText", fallback.SurfaceText),
+		SurfaceContainer: pick("surfaceContainer", fallback.SurfaceContainer),
+		Description:      pick("surfaceVariantText", fallback.Description),
+	}
+	p.Outline = pick("outline", p.Description)
+	return p
+}
+
+// key identifies the palette's colors for cache keys.
+func (p Palette) key() string {
+	var b strings.Builder
+	for _, c := range []color.NRGBA{p.Primary, p.Surface, p.SurfaceText, p.SurfaceContainer, p.Outline, p.Description} {
+		b.WriteString(rgbOf(c).Hex())
+	}
+	return b.String()
+}
 
 var statusChipColors = map[string]color.NRGBA{
 	"broken":       {R: 0xFF, G: 0xB4, B: 0xAB, A: 0xFF},
@@ -53,11 +103,27 @@ var statusChipColors = map[string]color.NRGBA{
 	"reviewed":     {R: 0xA9, G: 0xD8, B: 0x9B, A: 0xFF},
 }
 
+//go:generate sh fonts/subset.sh
+//go:generate sh fonts/noto.sh
+
+// fontAssets holds the fonts the scripts in fonts/ build: the Material Symbols subset
+// for plugin icons and the Noto subsets for scripts the Go fonts lack. A tree without
+// them still builds; cards draw initial letters in place of icons and boxes in place
+// of the missing glyphs.
+//
+//go:embed fonts
+var fontAssets embed.FS
+
 var (
 	fontOnce    sync.Once
 	fontErr     error
 	regularFont *opentype.Font
 	boldFont    *opentype.Font
+
+	// fallbackChains maps each primary font to the fonts tried after it, and
+	// fallbackFiles names every fallback that loaded.
+	fallbackChains map[*opentype.Font][]*opentype.Font
+	fallbackFiles  []string
 )
 
 func loadFonts() error {
@@ -67,6 +133,13 @@ func loadFonts() error {
 			return
 		}
 		boldFont, fontErr = opentype.Parse(gobold.TTF)
+		if fontErr != nil {
+			return
+		}
+		fallbackChains = map[*opentype.Font][]*opentype.Font{
+			regularFont: loadFallbacks(regularFallbackFiles),
+			boldFont:    loadFallbacks(boldFallbackFiles),
+		}
 	})
 	if fontErr != nil {
 		return fmt.Errorf("failed to parse embedded fonts: %w", fontErr)
@@ -100,26 +173,28 @@ func lightenChannel(v uint8, frac float64) uint8 {
 	return uint8(math.Round(float64(v) + (255-float64(v))*frac))
 }
 
-func newCanvas() *gg.Context {
+func newCanvasWith(surface color.NRGBA) *gg.Context {
 	dc := gg.NewContext(cardWidth, cardHeight)
 	grad := gg.NewLinearGradient(0, 0, 0, cardHeight)
-	grad.AddColorStop(0, colSurface)
-	grad.AddColorStop(1, lighten(colSurface, 0.06))
+	grad.AddColorStop(0, surface)
+	grad.AddColorStop(1, lighten(surface, 0.06))
 	dc.SetFillStyle(grad)
 	dc.DrawRectangle(0, 0, cardWidth, cardHeight)
 	dc.Fill()
 	return dc
 }
 
+// ellipsize shortens s at its logical end, which is the left edge of right-to-left
+// text, until it fits in maxW as drawn.
 func ellipsize(dc *gg.Context, s string, maxW float64) string {
-	if w, _ := dc.MeasureString(s); w <= maxW {
+	if measureText(dc, s) <= maxW {
 		return s
 	}
 	runes := []rune(s)
 	for len(runes) > 0 {
 		runes = runes[:len(runes)-1]
 		candidate := strings.TrimRight(string(runes), " …") + "…"
-		if w, _ := dc.MeasureString(candidate); w <= maxW {
+		if measureText(dc, candidate) <= maxW {
 			return candidate
 		}
 	}
@@ -133,7 +208,7 @@ type chipSpec struct {
 }
 
 func drawChipRow(dc *gg.Context, chips []chipSpec, right, y float64) (float64, error) {
-	face, err := newFace(boldFont, 14)
+	face, err := newTextFace(boldFont, 14)
 	if err != nil {
 		return 0, err
 	}
@@ -142,7 +217,7 @@ func drawChipRow(dc *gg.Context, chips []chipSpec, right, y float64) (float64, e
 	x := right
 	for i := len(chips) - 1; i >= 0; i-- {
 		chip := chips[i]
-		textW, _ := dc.MeasureString(chip.label)
+		textW := measureText(dc, chip.label)
 		chipW := textW + 2*chipPadX
 		x -= chipW
 
@@ -151,32 +226,47 @@ func drawChipRow(dc *gg.Context, chips []chipSpec, right, y float64) (float64, e
 		dc.Fill()
 
 		dc.SetColor(chip.text)
-		dc.DrawStringAnchored(chip.label, x+chipW/2, y+chipHeight/2, 0.5, 0.35)
+		drawText(dc, chip.label, x+chipW/2, y+chipHeight/2, 0.5, 0.35)
 		x -= chipGap
 	}
 	return x + chipGap, nil
 }
 
-func footerChips(p models.Plugin) []chipSpec {
+func footerChips(p models.Plugin, pal Palette) []chipSpec {
 	var chips []chipSpec
 	if p.Version != "" {
 		label := p.Version
 		if !strings.HasPrefix(label, "v") {
 			label = "v" + label
 		}
-		chips = append(chips, chipSpec{label: label, text: colSurfaceText, fill: withAlpha(colSurfaceText, 0.10)})
+		chips = append(chips, chipSpec{label: label, text: pal.SurfaceText, fill: withAlpha(pal.SurfaceText, 0.10)})
 	}
 	if p.Category != "" {
-		chips = append(chips, chipSpec{label: strings.ToUpper(p.Category), text: colPrimary, fill: withAlpha(colPrimary, 0.15)})
+		chips = append(chips, chipSpec{label: strings.ToUpper(p.Category), text: pal.Primary, fill: withAlpha(pal.Primary, 0.15)})
 	}
 	return chips
 }
 
+// wrapLines breaks s at spaces, and between CJK characters, into lines that fit maxW
+// as drawn. A word too long for a line gets one of its own.
 func wrapLines(dc *gg.Context, s string, maxW float64, maxLines int) []string {
 	if s == "" {
 		return nil
 	}
-	lines := dc.WordWrap(s, maxW)
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, seg := range wrapSegments(para) {
+			if line != "" && measureText(dc, strings.TrimSpace(line+seg)) > maxW {
+				lines = append(lines, strings.TrimSpace(line))
+				line = ""
+			}
+			line += seg
+		}
+		if line != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
 	if len(lines) <= maxLines {
 		return lines
 	}
@@ -185,18 +275,20 @@ func wrapLines(dc *gg.Context, s string, maxW float64, maxLines int) []string {
 	return lines
 }
 
-func footerLayout(dc *gg.Context, p models.Plugin) ([]string, float64, error) {
-	descFace, err := newFace(regularFont, 22)
+func footerLayout(dc *gg.Context, description string) ([]string, float64, error) {
+	descFace, err := newTextFace(regularFont, 22)
 	if err != nil {
 		return nil, 0, err
 	}
 	dc.SetFontFace(descFace)
 
-	lines := wrapLines(dc, p.Description, cardWidth-2*regionInset, maxDescLines)
+	lines := wrapLines(dc, description, cardWidth-2*regionInset, maxDescLines)
 	extra := max(len(lines), 1) - 1
 	return lines, baseRegionHeight - float64(extra)*descLineHeight, nil
 }
 
+// drawStatusChips keeps the dark scrim in every palette: the chips sit over the
+// screenshot, and their tints are picked to read against it.
 func drawStatusChips(dc *gg.Context, statuses []string) error {
 	var chips []chipSpec
 	for _, status := range statuses {
@@ -204,7 +296,7 @@ func drawStatusChips(dc *gg.Context, statuses []string) error {
 		if !ok {
 			continue
 		}
-		chips = append(chips, chipSpec{label: strings.ToUpper(status), text: tint, fill: withAlpha(colSurface, 0.78)})
+		chips = append(chips, chipSpec{label: strings.ToUpper(status), text: tint, fill: withAlpha(DarkPalette.Surface, 0.78)})
 	}
 	if len(chips) == 0 {
 		return nil
@@ -213,81 +305,98 @@ func drawStatusChips(dc *gg.Context, statuses []string) error {
 	return err
 }
 
-func drawFooter(dc *gg.Context, p models.Plugin, descLines []string, regionHeight float64) error {
-	dc.SetColor(colPrimary)
+func drawFooter(dc *gg.Context, t cardTemplate, descLines []string, regionHeight float64) error {
+	pal := t.palette
+	dc.SetColor(pal.Primary)
 	dc.DrawRectangle(0, cardHeight-accentHeight, cardWidth, accentHeight)
 	dc.Fill()
 
 	regionBottom := regionInset + regionHeight
+	if t.footer != nil {
+		return t.footer(dc, regionBottom+regionInset)
+	}
 	chipLeft := float64(cardWidth - regionInset)
-	chips := footerChips(p)
-	if len(chips) > 0 {
-		left, err := drawChipRow(dc, chips, cardWidth-regionInset, regionBottom+19)
+	if len(t.chips) > 0 {
+		left, err := drawChipRow(dc, t.chips, cardWidth-regionInset, regionBottom+19)
 		if err != nil {
 			return err
 		}
 		chipLeft = left
 	}
 
-	nameFace, err := newFace(boldFont, 40)
+	nameFace, err := newTextFace(boldFont, 40)
 	if err != nil {
 		return err
 	}
 	dc.SetFontFace(nameFace)
-	dc.SetColor(colSurfaceText)
+	dc.SetColor(pal.SurfaceText)
 	nameMax := chipLeft - 16 - regionInset
-	dc.DrawString(ellipsize(dc, p.Name, nameMax), regionInset, regionBottom+48)
+	drawText(dc, ellipsize(dc, t.title, nameMax), regionInset, regionBottom+48, 0, 0)
 
 	if len(descLines) == 0 {
 		return nil
 	}
 
-	descFace, err := newFace(regularFont, 22)
+	descFace, err := newTextFace(regularFont, 22)
 	if err != nil {
 		return err
 	}
 	dc.SetFontFace(descFace)
-	dc.SetColor(colDescription)
+	dc.SetColor(pal.Description)
 	for i, line := range descLines {
-		dc.DrawString(line, regionInset, regionBottom+88+float64(i)*descLineHeight)
+		drawText(dc, line, regionInset, regionBottom+88+float64(i)*descLineHeight, 0, 0)
 	}
 	return nil
 }
 
-func ComposeScreenshot(src image.Image, p models.Plugin) (image.Image, error) {
-	if err := loadFonts(); err != nil {
-		return nil, err
-	}
-
-	dc := newCanvas()
-	descLines, regionHeight, err := footerLayout(dc, p)
+func ComposeScreenshot(src image.Image, p models.Plugin, pal Palette) (image.Image, error) {
+	frames, err := composeScreenshotFrames([]image.Image{src}, p, pal)
 	if err != nil {
 		return nil, err
 	}
+	return frames[0], nil
+}
 
-	dc.SetColor(colSurfaceContainer)
-	dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
-	dc.Fill()
-
-	scaled, x, y := fitRegionImage(src, regionHeight)
-	dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
-	dc.Clip()
-	if scaled.Bounds().Dx() < int(regionWidth) || scaled.Bounds().Dy() < int(regionHeight) {
-		dc.DrawImage(blurFill(src, regionHeight), int(regionInset), int(regionInset))
-		dc.SetColor(withAlpha(colSurface, blurOverlayAlpha))
+// composeScreenshotFrames lays out each frame of an animated screenshot as
+// ComposeScreenshot does. Everything outside the image region is drawn once, and the
+// letterbox behind a fitted image is blurred from the first frame so that it holds
+// still while the animation plays.
+func composeScreenshotFrames(frames []image.Image, p models.Plugin, pal Palette) ([]image.Image, error) {
+	t := pluginTemplate(p, pal)
+
+	// The first frame is fitted once, for the letterbox test and to draw it.
+	var first image.Image
+	var firstX, firstY int
+	t.region = func(dc *gg.Context, regionHeight float64) error {
+		first, firstX, firstY = fitRegionImage(frames[0], regionHeight)
+		if first.Bounds().Dx() >= int(regionWidth) && first.Bounds().Dy() >= int(regionHeight) {
+			return nil
+		}
+		dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
+		dc.Clip()
+		dc.DrawImage(blurFill(frames[0], regionHeight), int(regionInset), int(regionInset))
+		dc.SetColor(withAlpha(pal.Surface, blurOverlayAlpha))
 		dc.DrawRectangle(regionInset, regionInset, regionWidth, regionHeight)
 		dc.Fill()
+		dc.ResetClip()
+		return nil
 	}
-	dc.DrawImage(scaled, x, y)
-	dc.ResetClip()
 
-	if err := drawStatusChips(dc, p.Status); err != nil {
-		return nil, err
-	}
-	if err := drawFooter(dc, p, descLines, regionHeight); err != nil {
-		return nil, err
+	draws := make([]regionFrame, len(frames))
+	for i, src := range frames {
+		draws[i] = func(dc *gg.Context, regionHeight float64) error {
+			scaled, x, y := first, firstX, firstY
+			if i > 0 {
+				scaled, x, y = fitRegionImage(src, regionHeight)
+			}
+			dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
+			dc.Clip()
+			dc.DrawImage(scaled, x, y)
+			dc.ResetClip()
+			return nil
+		}
 	}
-	return dc.Image(), nil
+	return t.composeFrames(draws)
 }
 
 func blurFill(src image.Image, regionHeight float64) image.Image {
@@ -65,7 +65,7 @@ func TestComposeScreenshotDimensions(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			img, err := ComposeScreenshot(solidImage(tc.w, tc.h, sourceRed), testPlugin)
+			img, err := ComposeScreenshot(solidImage(tc.w, tc.h, sourceRed), testPlugin, DarkPalette)
 			if err != nil {
 				t.Fatalf("ComposeScreenshot: %v", err)
 			}
@@ -78,7 +78,7 @@ func TestComposeScreenshotDimensions(t *testing.T) {
 }
 
 func TestComposeScreenshotContainBlurredLetterbox(t *testing.T) {
-	img, err := ComposeScreenshot(solidImage(800, 600, sourceRed), testPlugin)
+	img, err := ComposeScreenshot(solidImage(800, 600, sourceRed), testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeScreenshot: %v", err)
 	}
@@ -88,7 +88,7 @@ func TestComposeScreenshotContainBlurredLetterbox(t *testing.T) {
 }
 
 func TestComposeScreenshotPortraitBlurredLetterbox(t *testing.T) {
-	img, err := ComposeScreenshot(solidImage(540, 960, sourceRed), testPlugin)
+	img, err := ComposeScreenshot(solidImage(540, 960, sourceRed), testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeScreenshot: %v", err)
 	}
@@ -97,7 +97,7 @@ func TestComposeScreenshotPortraitBlurredLetterbox(t *testing.T) {
 }
 
 func TestComposeScreenshotCoverNearRegionRatio(t *testing.T) {
-	img, err := ComposeScreenshot(solidImage(2100, 900, sourceRed), testPlugin)
+	img, err := ComposeScreenshot(solidImage(2100, 900, sourceRed), testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeScreenshot: %v", err)
 	}
@@ -106,15 +106,15 @@ func TestComposeScreenshotCoverNearRegionRatio(t *testing.T) {
 }
 
 func TestComposeScreenshotAccentBar(t *testing.T) {
-	img, err := ComposeScreenshot(solidImage(1600, 900, sourceRed), testPlugin)
+	img, err := ComposeScreenshot(solidImage(1600, 900, sourceRed), testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeScreenshot: %v", err)
 	}
-	assertPixel(t, img, 480, 538, colPrimary)
+	assertPixel(t, img, 480, 538, DarkPalette.Primary)
 }
 
 func TestComposeScreenshotUpscaleCapped(t *testing.T) {
-	img, err := ComposeScreenshot(solidImage(120, 50, sourceRed), testPlugin)
+	img, err := ComposeScreenshot(solidImage(120, 50, sourceRed), testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeScreenshot: %v", err)
 	}
@@ -127,11 +127,11 @@ func TestFooterLayoutShowsFullDescription(t *testing.T) {
 	if err := loadFonts(); err != nil {
 		t.Fatal(err)
 	}
-	dc := newCanvas()
+	dc := newCanvasWith(DarkPalette.Surface)
 	p := testPlugin
 	p.Description = strings.TrimSpace(strings.Repeat("wide words flow across the card footer band ", 4))
 
-	lines, regionH, err := footerLayout(dc, p)
+	lines, regionH, err := footerLayout(dc, p.Description)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -148,7 +148,7 @@ func TestFooterLayoutShowsFullDescription(t *testing.T) {
 }
 
 func TestComposeCardDimensions(t *testing.T) {
-	img, err := ComposeCard(testPlugin)
+	img, err := ComposeCard(testPlugin, DarkPalette)
 	if err != nil {
 		t.Fatalf("ComposeCard: %v", err)
 	}
@@ -156,5 +156,5 @@ func TestComposeCardDimensions(t *testing.T) {
 	if b.Dx() != 960 || b.Dy() != 540 {
 		t.Fatalf("output size %dx%d, want 960x540", b.Dx(), b.Dy())
 	}
-	assertPixel(t, img, 480, 538, colPrimary)
+	assertPixel(t, img, 480, 538, DarkPalette.Primary)
 }
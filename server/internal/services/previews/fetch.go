@@ -16,10 +16,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/fogleman/gg"
-	"github.com/srwiley/oksvg"
 	"github.com/srwiley/rasterx"
 	_ "golang.org/x/image/webp"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/webp"
 )
 
 const (
@@ -105,54 +105,79 @@ func normalizeImageURL(u *url.URL) *url.URL {
 }
 
 func (f *imageFetcher) fetch(ctx context.Context, rawURL string) (image.Image, error) {
+	data, contentType, err := f.fetchData(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(contentType, data)
+}
+
+// fetchData downloads the image at rawURL undecoded, with its Content-Type.
+func (f *imageFetcher) fetchData(ctx context.Context, rawURL string) ([]byte, string, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid image url: %w", err)
+		return nil, "", fmt.Errorf("invalid image url: %w", err)
 	}
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+		return nil, "", fmt.Errorf("unsupported scheme %q", u.Scheme)
 	}
 	u = normalizeImageURL(u)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, "", fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	contentType := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Type")))
 	if !strings.HasPrefix(contentType, "image/") {
-		return nil, fmt.Errorf("unexpected content type %q", contentType)
+		return nil, "", fmt.Errorf("unexpected content type %q", contentType)
 	}
 
 	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read image body: %w", err)
+		return nil, "", fmt.Errorf("failed to read image body: %w", err)
 	}
 	if len(data) > maxImageBytes {
-		return nil, fmt.Errorf("image exceeds %d byte limit", maxImageBytes)
+		return nil, "", fmt.Errorf("image exceeds %d byte limit", maxImageBytes)
 	}
 
+	return data, contentType, nil
+}
+
+// decodeImage decodes a fetched image, rasterizing it if it is an SVG.
+func decodeImage(contentType string, data []byte) (image.Image, error) {
 	if isSVG(contentType, data) {
 		return rasterizeSVG(data)
 	}
+	return decodeRaster(data)
+}
 
-	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+// decodeRaster decodes a PNG, JPEG, GIF or WebP image, checking its dimensions before
+// allocating any pixels. An animation decodes to its first frame.
+func decodeRaster(data []byte) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image config: %w", err)
 	}
 	if cfg.Width > maxDimension || cfg.Height > maxDimension {
 		return nil, fmt.Errorf("image dimensions %dx%d exceed limit", cfg.Width, cfg.Height)
 	}
+	// x/image/webp reads still images only.
+	if format == "webp" {
+		if anim, err := webp.DecodeAll(data, 1); err == nil {
+			return anim.Frames[0], nil
+		}
+	}
 
 	img, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
@@ -169,18 +194,29 @@ func isSVG(contentType string, data []byte) bool {
 	return strings.HasPrefix(head, "<svg") || (strings.HasPrefix(head, "<?xml") && strings.Contains(head, "<svg"))
 }
 
+// rasterizeSVG renders an SVG at a fixed 1200px width, its height following the
+// document's aspect ratio. Parsing refuses anything that would reach outside the
+// document, so rendering never makes a request of its own.
 func rasterizeSVG(data []byte) (image.Image, error) {
-	texts, err := parseSVGTexts(data)
+	doc, err := parseSVGDocument(data)
 	if err != nil {
 		return nil, err
 	}
 
-	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse svg: %w", err)
+	root := doc.root
+	vb, hasViewBox := parseViewBox(root.attrs["viewBox"])
+	w := intrinsicLength(root.attrs["width"])
+	h := intrinsicLength(root.attrs["height"])
+	switch {
+	case hasViewBox && w <= 0 && h <= 0:
+		w, h = vb.w, vb.h
+	case hasViewBox && w <= 0:
+		w = h * vb.w / vb.h
+	case hasViewBox && h <= 0:
+		h = w * vb.h / vb.w
+	case !hasViewBox:
+		vb = svgBox{w: w, h: h}
 	}
-
-	w, h := icon.ViewBox.W, icon.ViewBox.H
 	if w <= 0 || h <= 0 {
 		return nil, fmt.Errorf("svg has no usable dimensions")
 	}
@@ -193,16 +229,19 @@ func rasterizeSVG(data []byte) (image.Image, error) {
 		return nil, fmt.Errorf("svg raster size %dx%d out of bounds", dw, dh)
 	}
 
-	img := image.NewRGBA(image.Rect(0, 0, dw, dh))
-	icon.SetTarget(0, 0, float64(dw), float64(dh))
-	icon.Draw(rasterx.NewDasher(dw, dh, rasterx.NewScannerGV(dw, dh, img, img.Bounds())), 1.0)
+	ctm := rasterx.Identity.Scale(float64(dw)/w, float64(dh)/h).Mult(viewBoxTransform(vb, w, h, root.attrs["preserveAspectRatio"]))
+	return renderSVG(doc, dw, dh, ctm, svgViewport{w: vb.w, h: vb.h})
+}
 
-	if len(texts) == 0 {
-		return img, nil
+// intrinsicLength reads the root's width or height, where percentages have nothing to
+// resolve against and count as missing.
+func intrinsicLength(v string) float64 {
+	if strings.HasSuffix(strings.TrimSpace(v), "%") {
+		return 0
 	}
-	dc := gg.NewContextForRGBA(img)
-	if err := drawSVGTexts(dc, texts, icon.ViewBox.X, icon.ViewBox.Y, scale); err != nil {
-		return nil, err
+	f, ok := parseSVGLength(v, 0, 16)
+	if !ok {
+		return 0
 	}
-	return dc.Image(), nil
+	return f
 }
@@ -207,14 +207,27 @@ func TestRasterizeSVGRendersText(t *testing.T) {
 	}
 }
 
-func TestRasterizeSVGRejectsUnsupportedTextTransform(t *testing.T) {
-	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 50"><g transform="rotate(45)"><text x="10" y="20">hi</text></g></svg>`
-	_, err := rasterizeSVG([]byte(svg))
-	if err == nil {
-		t.Fatal("expected error for text under unsupported transform")
+func TestRasterizeSVGRendersRotatedText(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100"><rect width="100" height="100" fill="#141218"/><g transform="rotate(90 50 50)"><text x="10" y="55" font-size="14" fill="#ffffff">HELLO WORLD</text></g></svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+
+	// Turned a quarter around the center, the line of text runs down a narrow column
+	// rather than across.
+	b := img.Bounds()
+	minX, maxX, minY, maxY := b.Max.X, b.Min.X, b.Max.Y, b.Min.Y
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r>>8 > 0xC0 {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
 	}
-	if !strings.Contains(err.Error(), "unsupported transform") {
-		t.Fatalf("expected unsupported-transform error, got: %v", err)
+	if maxX < minX || maxY-minY < 3*(maxX-minX) {
+		t.Fatalf("expected vertical text, got bright pixels spanning x %d-%d and y %d-%d", minX, maxX, minY, maxY)
 	}
 }
 
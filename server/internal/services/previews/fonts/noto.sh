@@ -0,0 +1,55 @@
+#!/bin/sh
+# Builds the Noto subsets cards fall back to for text the Go fonts cannot draw: Hebrew
+# and Arabic, with the Arabic presentation forms the shaper maps letters onto; the
+# common CJK characters, namely GB 2312 level 1 hanzi, JIS X 0208 level 1 kanji, the
+# KS X 1001 hangul syllables, kana and CJK punctuation; and monochrome emoji. Regular
+# and bold instances are cut from the variable releases. Needs curl, python3 and
+# fonttools.
+set -eu
+
+cd "$(dirname "$0")"
+
+upstream='https://raw.githubusercontent.com/google/fonts/main/ofl'
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+curl -fsSL -o "$tmp/hebrew.ttf" "$upstream/notosanshebrew/NotoSansHebrew%5Bwdth%2Cwght%5D.ttf"
+curl -fsSL -o "$tmp/arabic.ttf" "$upstream/notosansarabic/NotoSansArabic%5Bwdth%2Cwght%5D.ttf"
+curl -fsSL -o "$tmp/cjk.ttf" "$upstream/notosanssc/NotoSansSC%5Bwght%5D.ttf"
+curl -fsSL -o "$tmp/emoji.ttf" "$upstream/notoemoji/NotoEmoji%5Bwght%5D.ttf"
+
+python3 - > "$tmp/cjk.txt" <<'EOF'
+# Rows 16 and up of each double-byte set hold its level 1 ideographs or, for KS X
+# 1001, its hangul syllables.
+sets = [("gb2312", 0xB0, 0xD8), ("euc_jp", 0xB0, 0xD0), ("euc_kr", 0xB0, 0xC9)]
+cps = set(range(0x3000, 0x3100)) | set(range(0xFF00, 0xFFF0))
+for codec, first, last in sets:
+    for hi in range(first, last):
+        for lo in range(0xA1, 0xFF):
+            try:
+                cps.add(ord(bytes([hi, lo]).decode(codec)))
+            except (UnicodeDecodeError, TypeError):
+                pass
+print(",".join("U+%04X" % cp for cp in sorted(cps)))
+EOF
+
+subset() {
+	src=$1 out=$2 unicodes=$3
+	shift 3
+	fonttools varLib.instancer "$src" "$@" -o "$tmp/static.ttf"
+	fonttools subset "$tmp/static.ttf" --unicodes="$unicodes" --layout-features='' \
+		--no-hinting --output-file="$out"
+}
+
+hebrew='U+0590-05FF,U+FB1D-FB4F,U+20AA'
+arabic='U+0600-06FF,U+0750-077F,U+FB50-FDFF,U+FE70-FEFF'
+cjk=$(cat "$tmp/cjk.txt")
+emoji='U+00A9,U+00AE,U+203C,U+2049,U+2122,U+2139,U+2194-21AA,U+231A-23FF,U+24C2,U+25AA-27BF,U+2934-2935,U+2B05-2B55,U+3030,U+303D,U+3297,U+3299,U+1F000-1FAFF'
+
+for weight in Regular:400 Bold:700; do
+	name=${weight%:*} wght=${weight#*:}
+	subset "$tmp/hebrew.ttf" "NotoSansHebrew-$name.ttf" "$hebrew" wdth=100 wght="$wght"
+	subset "$tmp/arabic.ttf" "NotoSansArabic-$name.ttf" "$arabic" wdth=100 wght="$wght"
+	subset "$tmp/cjk.ttf" "NotoSansCJK-$name.ttf" "$cjk" wght="$wght"
+done
+subset "$tmp/emoji.ttf" NotoEmoji-Regular.ttf "$emoji" wght=400
@@ -0,0 +1,28 @@
+#!/bin/sh
+# Builds the Material Symbols subset preview cards draw plugin icons with: Outlined,
+# unfilled at regular weight and the 48px optical size, cut down to the names in
+# icons.txt, plus the matching codepoints file. Needs curl and fonttools.
+set -eu
+
+cd "$(dirname "$0")"
+
+upstream='https://raw.githubusercontent.com/google/material-design-icons/master/variablefont/MaterialSymbolsOutlined%5BFILL%2CGRAD%2Copsz%2Cwght%5D'
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+curl -fsSL -o "$tmp/full.ttf" "$upstream.ttf"
+curl -fsSL -o "$tmp/full.codepoints" "$upstream.codepoints"
+
+grep -v '^#' icons.txt | grep . | sort -u > "$tmp/names"
+awk 'NR == FNR { want[$1] = 1; next } ($1 in want)' "$tmp/names" "$tmp/full.codepoints" \
+	| sort > MaterialSymbolsOutlined.codepoints
+
+missing=$(cut -d' ' -f1 MaterialSymbolsOutlined.codepoints | comm -23 "$tmp/names" -)
+if [ -n "$missing" ]; then
+	echo "not in Material Symbols, skipped:" $missing >&2
+fi
+
+fonttools varLib.instancer "$tmp/full.ttf" FILL=0 GRAD=0 opsz=48 wght=400 -o "$tmp/static.ttf"
+unicodes=$(awk '{ printf "%sU+%s", sep, $2; sep = "," }' MaterialSymbolsOutlined.codepoints)
+fonttools subset "$tmp/static.ttf" --unicodes="$unicodes" --layout-features='' \
+	--no-hinting --output-file=MaterialSymbolsOutlined.ttf
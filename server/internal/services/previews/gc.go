@@ -0,0 +1,151 @@
+package previews
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// strayFileAge is how old a file no entry refers to must be before Collect removes it.
+// Put writes a preview's file before adding its entry, so a younger one may be a
+// preview that is still being stored.
+const strayFileAge = time.Hour
+
+// EntryInfo describes one stored preview, rendition, variant or kept source image.
+type EntryInfo struct {
+	ID          string    `json:"id"`
+	Parent      string    `json:"parent,omitempty" doc:"Entry this one was derived from"`
+	Kind        string    `json:"kind" doc:"What the preview was drawn from: screenshot, card, theme, animation, social or source"`
+	SourceKey   string    `json:"sourceKey"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Size        int64     `json:"size" doc:"File size in bytes; -1 if the file is missing"`
+}
+
+// Entries lists the manifest, ordered by id.
+func (s *Store) Entries() []EntryInfo {
+	s.mu.Lock()
+	out := make([]EntryInfo, 0, len(s.manifest))
+	for id, entry := range s.manifest {
+		out = append(out, EntryInfo{
+			ID:          id,
+			Parent:      entry.Parent,
+			Kind:        entry.SourceKind,
+			SourceKey:   entry.SourceKey,
+			GeneratedAt: entry.GeneratedAt,
+			Size:        -1,
+		})
+	}
+	files := make(map[string]string, len(s.manifest))
+	for id, entry := range s.manifest {
+		files[id] = entry.File
+	}
+	s.mu.Unlock()
+
+	for i := range out {
+		if info, err := os.Stat(filepath.Join(s.dir, files[out[i].ID])); err == nil {
+			out[i].Size = info.Size()
+		}
+	}
+	slices.SortFunc(out, func(a, b EntryInfo) int { return strings.Compare(a.ID, b.ID) })
+	return out
+}
+
+// Remove deletes the preview stored under id and everything derived from it, so the
+// next sync renders it again. It reports whether there was anything to remove.
+func (s *Store) Remove(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.manifest[id]
+	if !ok {
+		return false, nil
+	}
+	_ = os.Remove(filepath.Join(s.dir, entry.File))
+	delete(s.manifest, id)
+	s.dropRenditionsLocked(id)
+	return true, s.saveManifestLocked()
+}
+
+// Collect removes every stored preview whose id orphaned reports true, with everything
+// derived from it, along with entries whose parent is gone and files no entry refers
+// to. It returns how many entries and files were removed.
+func (s *Store) Collect(orphaned func(id string) bool) (int, error) {
+	s.mu.Lock()
+	removed := 0
+	for id, entry := range s.manifest {
+		if entry.Parent != "" || !orphaned(id) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(s.dir, entry.File))
+		delete(s.manifest, id)
+		removed++
+	}
+	for {
+		dangling := 0
+		for id, entry := range s.manifest {
+			if _, ok := s.manifest[entry.Parent]; entry.Parent == "" || ok {
+				continue
+			}
+			_ = os.Remove(filepath.Join(s.dir, entry.File))
+			delete(s.manifest, id)
+			dangling++
+		}
+		if dangling == 0 {
+			break
+		}
+		removed += dangling
+	}
+	var err error
+	if removed > 0 {
+		err = s.saveManifestLocked()
+	}
+
+	referenced := map[string]bool{}
+	for _, entry := range s.manifest {
+		referenced[filepath.FromSlash(entry.File)] = true
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return removed, err
+	}
+
+	reserved := map[string]bool{
+		filepath.Base(s.manifestPath()):         true,
+		filepath.Base(s.PlaceholderPath()):      true,
+		filepath.Base(s.ThemePlaceholderPath()): true,
+		jobsFile:                                true,
+	}
+	cutoff := time.Now().Add(-strayFileAge)
+	walkErr := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil || referenced[rel] || reserved[rel] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			removed++
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return removed, fmt.Errorf("failed to scan previews directory: %w", walkErr)
+	}
+	return removed, nil
+}
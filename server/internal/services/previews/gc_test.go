@@ -0,0 +1,99 @@
+package previews
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreCollectRemovesOrphansAndStrayFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	data := pngBytes(t, 64, 36)
+	for _, id := range []string{"keep", "gone", ThemeKey("theme")} {
+		if err := s.Put(id, "card", "k", "png", data); err != nil {
+			t.Fatalf("Put %s: %v", id, err)
+		}
+	}
+	gonePath, _, _ := s.LookupRendition("gone", Rendition{Width: 32})
+	keepPath, _, _ := s.LookupRendition("keep", Rendition{Width: 32})
+
+	previewsDir := filepath.Join(dir, "previews")
+	old := time.Now().Add(-2 * strayFileAge)
+	stray := filepath.Join(previewsDir, "stray.png")
+	young := filepath.Join(previewsDir, "young.png")
+	placeholder := s.PlaceholderPath()
+	for _, path := range []string{stray, young, placeholder} {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{stray, placeholder} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := s.Collect(func(id string) bool { return id == "gone" })
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	// gone, its rendition and the old stray file.
+	if removed != 3 {
+		t.Fatalf("expected 3 removals, got %d", removed)
+	}
+	if _, _, ok := s.Lookup("gone"); ok {
+		t.Fatal("expected the orphaned preview to be removed")
+	}
+	for _, path := range []string{gonePath, stray} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be deleted", filepath.Base(path))
+		}
+	}
+	for _, path := range []string{keepPath, young, placeholder} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be kept: %v", filepath.Base(path), err)
+		}
+	}
+	if _, _, ok := s.Lookup(ThemeKey("theme")); !ok {
+		t.Fatal("expected an entry the callback keeps to survive")
+	}
+}
+
+func TestStoreRemoveDropsDerivedEntries(t *testing.T) {
+	s, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if err := s.Put("foo", "card", "k", "png", pngBytes(t, 64, 36)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, _, ok := s.LookupRendition("foo", Rendition{Width: 32}); !ok {
+		t.Fatal("expected rendition to render")
+	}
+
+	entries := s.Entries()
+	if len(entries) != 2 || entries[0].ID != "foo" || entries[1].Parent != "foo" {
+		t.Fatalf("expected the preview and its rendition, got %+v", entries)
+	}
+	if entries[0].Size <= 0 || entries[1].Size <= 0 {
+		t.Fatalf("expected file sizes, got %+v", entries)
+	}
+
+	if ok, err := s.Remove("foo"); !ok || err != nil {
+		t.Fatalf("Remove: %v %v", ok, err)
+	}
+	if got := s.Entries(); len(got) != 0 {
+		t.Fatalf("expected the rendition to go with its source, got %+v", got)
+	}
+	if !s.NeedsUpdate("foo", "k") {
+		t.Fatal("expected a removed preview to need rendering again")
+	}
+	if ok, _ := s.Remove("foo"); ok {
+		t.Fatal("expected a second Remove to find nothing")
+	}
+}
@@ -0,0 +1,137 @@
+package previews
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+)
+
+const (
+	iconFontFile       = "fonts/MaterialSymbolsOutlined.ttf"
+	iconCodepointsFile = "fonts/MaterialSymbolsOutlined.codepoints"
+)
+
+// iconSet maps Material Symbols names to their glyphs. The font draws icons through
+// ligatures, which the rasterizer does not apply, so names go through the codepoints
+// file instead.
+type iconSet struct {
+	font       *opentype.Font
+	codepoints map[string]rune
+}
+
+var (
+	iconOnce sync.Once
+	icons    *iconSet
+)
+
+func loadIcons() *iconSet {
+	iconOnce.Do(func() {
+		fontData, err := fontAssets.ReadFile(iconFontFile)
+		if err != nil {
+			log.Warnf("Icon font is not embedded, cards draw initials instead: %v", err)
+			return
+		}
+		codepoints, err := fontAssets.ReadFile(iconCodepointsFile)
+		if err != nil {
+			log.Warnf("Icon font has no codepoints file: %v", err)
+			return
+		}
+		set, err := newIconSet(fontData, codepoints)
+		if err != nil {
+			log.Warnf("Failed to load icon font: %v", err)
+			return
+		}
+		icons = set
+	})
+	return icons
+}
+
+func newIconSet(fontData, codepoints []byte) (*iconSet, error) {
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse icon font: %w", err)
+	}
+
+	// Names whose codepoint the subset left out are dropped here so a lookup never
+	// hands back a rune that draws as an empty box.
+	var buf sfnt.Buffer
+	set := &iconSet{font: f, codepoints: map[string]rune{}}
+	for name, r := range parseCodepoints(codepoints) {
+		if glyph, err := f.GlyphIndex(&buf, r); err == nil && glyph != 0 {
+			set.codepoints[name] = r
+		}
+	}
+	return set, nil
+}
+
+// parseCodepoints reads "name hex" lines as Material Symbols publishes them.
+func parseCodepoints(data []byte) map[string]rune {
+	out := map[string]rune{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 16, 32)
+		if err != nil {
+			continue
+		}
+		out[fields[0]] = rune(v)
+	}
+	return out
+}
+
+func normalizeIconName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (s *iconSet) lookup(name string) (rune, bool) {
+	if s == nil {
+		return 0, false
+	}
+	r, ok := s.codepoints[normalizeIconName(name)]
+	return r, ok
+}
+
+// iconSourceKey is the part of a card's source key its icon accounts for: the name,
+// and the glyph it resolved to, so a card drawn with the fallback letter is drawn again
+// once the name resolves.
+func iconSourceKey(name string) string {
+	if name == "" {
+		return ""
+	}
+	if r, ok := loadIcons().lookup(name); ok {
+		return name + "=" + strconv.FormatInt(int64(r), 16)
+	}
+	return name
+}
+
+// drawIcon centers the named icon on (cx, cy) from its glyph bounds, since icon fonts
+// place glyphs on the baseline in ways text anchoring does not account for.
+func drawIcon(dc *gg.Context, set *iconSet, name string, size, cx, cy float64) (bool, error) {
+	r, ok := set.lookup(name)
+	if !ok {
+		return false, nil
+	}
+	face, err := newFace(set.font, size)
+	if err != nil {
+		return false, err
+	}
+	bounds, _ := font.BoundString(face, string(r))
+	midX := float64(bounds.Min.X+bounds.Max.X) / 128
+	midY := float64(bounds.Min.Y+bounds.Max.Y) / 128
+	dc.SetFontFace(face)
+	dc.DrawString(string(r), cx-midX, cy-midY)
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package previews
+
+import (
+	"image/color"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// testIconSet stands in for the Material Symbols subset with a text font, mapping
+// icon names onto letters it has.
+func testIconSet(t *testing.T) *iconSet {
+	t.Helper()
+	set, err := newIconSet(goregular.TTF, []byte("square_a 41\nsquare_b 42\nmissing 10FFFD\nbroken zz\n"))
+	if err != nil {
+		t.Fatalf("newIconSet: %v", err)
+	}
+	return set
+}
+
+func TestIconSetDropsNamesWithoutGlyphs(t *testing.T) {
+	set := testIconSet(t)
+	if r, ok := set.lookup(" Square_A "); !ok || r != 'A' {
+		t.Fatalf("expected a normalized name to resolve, got %q %v", r, ok)
+	}
+	if _, ok := set.lookup("missing"); ok {
+		t.Fatal("expected a codepoint the font lacks to be dropped")
+	}
+	if _, ok := set.lookup("broken"); ok {
+		t.Fatal("expected an unparsable line to be skipped")
+	}
+
+	var none *iconSet
+	if _, ok := none.lookup("square_a"); ok {
+		t.Fatal("expected no icons without a font")
+	}
+}
+
+func TestEmbeddedIconFontHasDocsIcons(t *testing.T) {
+	set := loadIcons()
+	if set == nil {
+		t.Fatalf("%s is not embedded; run go generate ./internal/services/previews and commit its output", iconFontFile)
+	}
+	for _, name := range append(slices.Collect(maps.Values(docsSectionIcons)), "description") {
+		if _, ok := set.lookup(name); !ok {
+			t.Errorf("embedded icon font has no %q; add it to fonts/icons.txt and run fonts/subset.sh", name)
+		}
+	}
+}
+
+func TestDrawIconCentersTheGlyph(t *testing.T) {
+	if err := loadFonts(); err != nil {
+		t.Fatal(err)
+	}
+	dc := gg.NewContext(200, 200)
+	dc.SetColor(color.Black)
+	drawn, err := drawIcon(dc, testIconSet(t), "square_b", 96, 100, 100)
+	if err != nil || !drawn {
+		t.Fatalf("expected the icon to draw, got %v %v", drawn, err)
+	}
+
+	img := dc.Image()
+	minX, minY, maxX, maxY := 200, 200, -1, -1
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0x8000 {
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	if maxX < 0 {
+		t.Fatal("expected the glyph to be drawn")
+	}
+	if cx, cy := (minX+maxX)/2, (minY+maxY)/2; cx < 97 || cx > 103 || cy < 97 || cy > 103 {
+		t.Fatalf("expected the glyph centered on (100,100), got (%d,%d)", cx, cy)
+	}
+
+	if drawn, _ := drawIcon(dc, testIconSet(t), "unknown", 96, 100, 100); drawn {
+		t.Fatal("expected an unknown name to be left to the fallback")
+	}
+}
@@ -0,0 +1,265 @@
+package previews
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/docs"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/fogleman/gg"
+)
+
+// socialCardVersion is bumped when a social card's layout changes, so every stored
+// card is drawn again.
+const socialCardVersion = "s2"
+
+// docsSectionIcons is the icon a docs page's card shows for its section.
+var docsSectionIcons = map[string]string{
+	"dankmaterialshell": "dashboard",
+	"danksearch":        "search",
+	"dgop":              "memory",
+	"dankgreeter":       "lock",
+	"dankcalendar":      "calendar_month",
+	"danklinux":         "terminal",
+}
+
+// AuthorProfile is what an author's card lists: the names of their plugins and themes.
+type AuthorProfile struct {
+	Handle  string
+	Plugins []string
+	Themes  []string
+}
+
+// ComposeThemeSocialCard renders a theme's card for link previews: its headline
+// configuration's tokens as a row of swatches, drawn in that configuration's colors.
+func ComposeThemeSocialCard(t models.Theme) (image.Image, error) {
+	configs := registry.ThemeConfigs(&t)
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("theme %s has no colors to preview", t.ID)
+	}
+
+	headline := configs[0].Colors
+	if resolved, err := registry.ResolveScheme(&t, registry.ThemeSelection{Mode: configs[0].Mode}); err == nil {
+		headline = resolved.Colors
+	}
+	fallback := DarkPalette
+	if configs[0].Mode == "light" {
+		fallback = LightPalette
+	}
+	pal := PaletteFromScheme(headline, fallback)
+
+	name := t.Name
+	if name == "" {
+		name = t.ID
+	}
+	var chips []chipSpec
+	if len(configs) > 1 {
+		chips = append(chips, chipSpec{label: fmt.Sprintf("%d VARIANTS", len(configs)), text: pal.SurfaceText, fill: withAlpha(pal.SurfaceText, 0.10)})
+	}
+	chips = append(chips, chipSpec{label: "THEME", text: pal.Primary, fill: withAlpha(pal.Primary, 0.15)})
+
+	labels := make([]string, len(configs))
+	for i, config := range configs {
+		labels[i] = config.Label
+	}
+	byline := ""
+	if t.Author != "" {
+		byline = "by " + t.Author
+	}
+
+	return cardTemplate{
+		title:       name,
+		description: t.Description,
+		chips:       chips,
+		statuses:    t.Status,
+		palette:     pal,
+		region: func(dc *gg.Context, regionHeight float64) error {
+			return drawThemeSwatchRow(dc, headline, pal, strings.Join(labels, " · "), byline, regionHeight)
+		},
+	}.compose()
+}
+
+// drawThemeSwatchRow draws one tile per swatch token across the top of the region,
+// with the theme's configurations and author beneath.
+func drawThemeSwatchRow(dc *gg.Context, scheme map[string]interface{}, pal Palette, labels, byline string, regionHeight float64) error {
+	const (
+		padX    = 32.0
+		tileGap = 8.0
+	)
+	n := float64(len(themeSwatchTokens))
+	tileW := (regionWidth - 2*padX - (n-1)*tileGap) / n
+	tileH := regionHeight * 0.46
+	tileY := regionInset + padX
+	left := regionInset + padX
+
+	for i, token := range themeSwatchTokens {
+		x := left + float64(i)*(tileW+tileGap)
+		if c, ok := parseTokenColor(scheme, token); ok {
+			dc.SetColor(c)
+			dc.DrawRoundedRectangle(x, tileY, tileW, tileH, 12)
+			dc.Fill()
+		}
+		dc.SetColor(withAlpha(pal.SurfaceText, 0.15))
+		dc.SetLineWidth(1)
+		dc.DrawRoundedRectangle(x+0.5, tileY+0.5, tileW-1, tileH-1, 12)
+		dc.Stroke()
+	}
+
+	tileBottom := tileY + tileH
+	below := regionInset + regionHeight - tileBottom
+	textMax := regionWidth - 2*padX
+
+	labelFace, err := newTextFace(boldFont, 24)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(labelFace)
+	dc.SetColor(pal.SurfaceText)
+	drawText(dc, ellipsize(dc, labels, textMax), left, tileBottom+below*0.38, 0, 0.35)
+
+	if byline == "" {
+		return nil
+	}
+	bylineFace, err := newTextFace(regularFont, 18)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(bylineFace)
+	dc.SetColor(pal.Outline)
+	drawText(dc, ellipsize(dc, byline, textMax), left, tileBottom+below*0.68, 0, 0.35)
+	return nil
+}
+
+// ComposeAuthorCard renders an author's card for link previews, listing what they
+// have published to the registry.
+func ComposeAuthorCard(a AuthorProfile) (image.Image, error) {
+	pal := DarkPalette
+	title := "Plugins and themes by @" + a.Handle
+	switch {
+	case len(a.Themes) == 0:
+		title = "Plugins by @" + a.Handle
+	case len(a.Plugins) == 0:
+		title = "Themes by @" + a.Handle
+	}
+	em := emblem{
+		name:     a.Handle,
+		headline: "@" + a.Handle,
+		byline:   countLabel(len(a.Plugins), "plugin") + " · " + countLabel(len(a.Themes), "theme"),
+		mark:     "DMS",
+	}
+	names := append(slices.Clone(a.Plugins), a.Themes...)
+
+	return cardTemplate{
+		title:       title,
+		description: strings.Join(names, ", "),
+		chips:       []chipSpec{{label: "AUTHOR", text: pal.Primary, fill: withAlpha(pal.Primary, 0.15)}},
+		palette:     pal,
+		region: func(dc *gg.Context, regionHeight float64) error {
+			return em.draw(dc, pal, regionHeight)
+		},
+	}.compose()
+}
+
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// ComposeDocsCard renders a docs page's card for link previews: its section's icon and
+// name above the page's title and description.
+func ComposeDocsCard(p docs.Page) (image.Image, error) {
+	pal := DarkPalette
+	icon, ok := docsSectionIcons[p.SectionID]
+	if !ok {
+		icon = "description"
+	}
+	url := "danklinux.com/docs"
+	if p.Path != "index" {
+		url += "/" + p.Path
+	}
+	em := emblem{icon: icon, name: p.Section, headline: p.Section, byline: url}
+
+	return cardTemplate{
+		title:       p.Title,
+		description: p.Description,
+		chips:       []chipSpec{{label: "DOCS", text: pal.Primary, fill: withAlpha(pal.Primary, 0.15)}},
+		palette:     pal,
+		region: func(dc *gg.Context, regionHeight float64) error {
+			return em.draw(dc, pal, regionHeight)
+		},
+	}.compose()
+}
+
+// ThemeSocialCard returns the store key of t's social card, rendering it when the
+// theme changed since it was last drawn.
+func (g *Generator) ThemeSocialCard(t models.Theme) (string, bool) {
+	if !ThemeIDPattern.MatchString(t.ID) {
+		return "", false
+	}
+	statuses := slices.Clone(t.Status)
+	slices.Sort(statuses)
+	content := strings.Join([]string{ThemeSourceKey(t), t.ID, t.Description, strings.Join(statuses, ",")}, "\x00")
+	return g.socialCard(socialKey("themes", t.ID), content, func() (image.Image, error) {
+		return ComposeThemeSocialCard(t)
+	})
+}
+
+// AuthorSocialCard returns the store key of a's social card, rendering it when what
+// they have published changed since it was last drawn.
+func (g *Generator) AuthorSocialCard(a AuthorProfile) (string, bool) {
+	parts := append([]string{a.Handle}, a.Plugins...)
+	parts = append(append(parts, ""), a.Themes...)
+	return g.socialCard(socialKey("authors", strings.ToLower(a.Handle)), strings.Join(parts, "\x00"), func() (image.Image, error) {
+		return ComposeAuthorCard(a)
+	})
+}
+
+// DocsSocialCard returns the store key of p's social card, rendering it when the
+// page's frontmatter changed since it was last drawn.
+func (g *Generator) DocsSocialCard(p docs.Page) (string, bool) {
+	parts := []string{p.Path, p.Title, p.Description, p.SectionID, p.Section}
+	return g.socialCard(socialKey("docs", p.Path), strings.Join(parts, "\x00"), func() (image.Image, error) {
+		return ComposeDocsCard(p)
+	})
+}
+
+// socialCard keeps the card compose draws under key, drawing it again when content,
+// which identifies everything the card shows, differs from what it was drawn from.
+// Cards are stored as PNG, with renditions made on request as for previews.
+func (g *Generator) socialCard(key, content string, compose func() (image.Image, error)) (string, bool) {
+	h := sha256.Sum256([]byte(strings.Join([]string{socialCardVersion, content, fontSourceKey()}, "\x00")))
+	sourceKey := hex.EncodeToString(h[:])
+	if !g.store.NeedsUpdate(key, sourceKey) {
+		return key, true
+	}
+
+	g.socialMu.Lock()
+	defer g.socialMu.Unlock()
+	if !g.store.NeedsUpdate(key, sourceKey) {
+		return key, true
+	}
+
+	img, err := compose()
+	if err != nil {
+		log.Warnf("Social card render failed for %s: %v", key, err)
+		return "", false
+	}
+	data, err := encodePNG(img)
+	if err != nil {
+		log.Warnf("Social card encoding failed for %s: %v", key, err)
+		return "", false
+	}
+	if err := g.store.Put(key, "social", sourceKey, "png", data); err != nil {
+		log.Warnf("Preview store failed for %s: %v", key, err)
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,110 @@
+package previews
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/docs"
+)
+
+func TestComposeSocialCardsShareTheCardLayout(t *testing.T) {
+	theme, err := ComposeThemeSocialCard(testTheme)
+	if err != nil {
+		t.Fatalf("ComposeThemeSocialCard: %v", err)
+	}
+	author, err := ComposeAuthorCard(AuthorProfile{Handle: "tester", Plugins: []string{"Test Plugin"}, Themes: []string{"Test Theme"}})
+	if err != nil {
+		t.Fatalf("ComposeAuthorCard: %v", err)
+	}
+	page, err := ComposeDocsCard(docs.Page{Path: "dgop/usage", Title: "Usage", Description: "Running dgop", SectionID: "dgop", Section: "DGOP"})
+	if err != nil {
+		t.Fatalf("ComposeDocsCard: %v", err)
+	}
+
+	for name, img := range map[string]image.Image{"theme": theme, "author": author, "docs": page} {
+		if b := img.Bounds(); b.Dx() != cardWidth || b.Dy() != cardHeight {
+			t.Fatalf("%s card is %dx%d, want %dx%d", name, b.Dx(), b.Dy(), cardWidth, cardHeight)
+		}
+	}
+	// The theme card is drawn in the theme's own colors: its container, first swatch
+	// and accent bar.
+	assertPixel(t, theme, 30, 200, color.NRGBA{R: 0x20, G: 0x20, B: 0x20})
+	assertPixel(t, theme, 80, 100, color.NRGBA{R: 0x10, G: 0x10, B: 0x10})
+	assertPixel(t, theme, 480, 538, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+	assertPixel(t, author, 480, 538, DarkPalette.Primary)
+	assertPixel(t, page, 480, 538, DarkPalette.Primary)
+}
+
+func TestSocialCardsAreCachedUntilTheirContentChanges(t *testing.T) {
+	g, err := NewGenerator(t.TempDir(), "https://example.com")
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+
+	page := docs.Page{Path: "dgop/usage", Title: "Usage", SectionID: "dgop", Section: "DGOP"}
+	key, ok := g.DocsSocialCard(page)
+	if !ok || key != "og/docs/dgop/usage" {
+		t.Fatalf("expected the docs card under og/docs/dgop/usage, got %q %v", key, ok)
+	}
+	path, etag, ok := g.store.Lookup(key)
+	if !ok {
+		t.Fatal("expected the docs card to be stored")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := g.DocsSocialCard(page); !ok {
+		t.Fatal("expected the cached docs card")
+	}
+	if again, err := os.Stat(path); err != nil || !again.ModTime().Equal(info.ModTime()) {
+		t.Fatal("expected an unchanged page not to be drawn again")
+	}
+
+	page.Description = "Running dgop from the command line"
+	g.DocsSocialCard(page)
+	if _, changed, _ := g.store.Lookup(key); changed == etag {
+		t.Fatal("expected a changed description to draw the card again")
+	}
+
+	if key, ok := g.AuthorSocialCard(AuthorProfile{Handle: "Tester", Plugins: []string{"Test Plugin"}}); !ok || key != "og/authors/tester" {
+		t.Fatalf("expected the author card under its lowercased handle, got %q %v", key, ok)
+	}
+	if key, ok := g.ThemeSocialCard(testTheme); !ok || key != "og/themes/test-theme" {
+		t.Fatalf("expected the theme card under og/themes/test-theme, got %q %v", key, ok)
+	}
+	if _, ok := g.ThemeSocialCard(models.Theme{ID: "colorless"}); ok {
+		t.Fatal("expected a theme without colors to have no card")
+	}
+}
+
+func TestSyncThemesDropsSocialCardsOfRemovedThemes(t *testing.T) {
+	g, err := NewGenerator(t.TempDir(), "https://example.com")
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	other := testTheme
+	other.ID = "other-theme"
+
+	g.SyncThemes(context.Background(), []models.Theme{testTheme, other})
+	g.ThemeSocialCard(testTheme)
+	g.ThemeSocialCard(other)
+	g.DocsSocialCard(docs.Page{Path: "index", Title: "Welcome", Section: "Dank Linux"})
+	g.Sync(context.Background(), []models.Plugin{testPlugin})
+
+	g.SyncThemes(context.Background(), []models.Theme{testTheme})
+	for key, want := range map[string]bool{
+		"og/themes/test-theme":  true,
+		"og/themes/other-theme": false,
+		"og/docs/index":         true,
+	} {
+		if _, _, ok := g.store.Lookup(key); ok != want {
+			t.Errorf("%s stored = %v, want %v", key, ok, want)
+		}
+	}
+}
@@ -3,9 +3,13 @@ package previews
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -15,10 +19,18 @@ import (
 
 const syncWorkers = 4
 
+// ThemeIDPattern keeps registry theme ids safe to use as preview file names. Ids it
+// rejects get no preview, and are not served one.
+var ThemeIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
 type Generator struct {
 	store         *Store
 	fetcher       *imageFetcher
 	publicBaseURL string
+	variantMu     sync.Mutex
+	animationMu   sync.Mutex
+	socialMu      sync.Mutex
+	jobs          *jobQueue
 }
 
 func NewGenerator(cacheDir, publicBaseURL string) (*Generator, error) {
@@ -30,11 +42,19 @@ func NewGenerator(cacheDir, publicBaseURL string) (*Generator, error) {
 	if err := store.EnsurePlaceholder(renderPlaceholder); err != nil {
 		return nil, err
 	}
+	if err := store.EnsureThemePlaceholder(renderThemePlaceholder); err != nil {
+		return nil, err
+	}
+	jobs, err := newJobQueue(filepath.Join(store.dir, jobsFile))
+	if err != nil {
+		return nil, err
+	}
 
 	return &Generator{
 		store:         store,
 		fetcher:       newImageFetcher(),
 		publicBaseURL: strings.TrimSuffix(publicBaseURL, "/"),
+		jobs:          jobs,
 	}, nil
 }
 
@@ -42,94 +62,234 @@ func (g *Generator) Store() *Store {
 	return g.store
 }
 
+// Sync points every plugin's PreviewURL at its served preview and queues a job for
+// each preview that is out of date, returning without waiting for them. Until a job
+// finishes, the URL serves the preview stored before it, or the placeholder. Jobs and
+// stored previews for plugins no longer listed are dropped.
 func (g *Generator) Sync(ctx context.Context, plugins []models.Plugin) []models.Plugin {
 	out := make([]models.Plugin, len(plugins))
 	copy(out, plugins)
 
-	jobs := make(chan int)
-	var wg sync.WaitGroup
-	for range syncWorkers {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for i := range jobs {
-				g.syncPlugin(ctx, &out[i])
-			}
-		}()
-	}
+	ids := make(map[string]bool, len(out))
 	for i := range out {
-		jobs <- i
+		p := &out[i]
+		p.PreviewURL = g.publicBaseURL + "/previews/" + p.ID
+		ids[p.ID] = true
+
+		key := SourceKey(p.Screenshot, *p)
+		if g.store.NeedsUpdate(p.ID, key) {
+			g.jobs.enqueue(*p, key)
+		}
 	}
-	close(jobs)
-	wg.Wait()
+	g.jobs.retain(ids)
+	g.saveJobs()
+	g.collect("plugin", len(ids), func(id string) bool { return !isThemeKey(id) && !isSocialKey(id) && !ids[id] })
 	return out
 }
 
-func (g *Generator) syncPlugin(ctx context.Context, p *models.Plugin) {
-	p.PreviewURL = g.publicBaseURL + "/previews/" + p.ID
-
-	if p.Screenshot != "" && g.syncImageSource(ctx, *p, "screenshot", p.Screenshot) {
+// collect removes stored previews the last sync no longer listed. A sync that listed
+// nothing at all is more likely a registry that failed to parse than an empty one, so
+// it removes nothing.
+func (g *Generator) collect(kind string, listed int, orphaned func(id string) bool) {
+	if listed == 0 {
 		return
 	}
-	g.syncCard(*p)
+	removed, err := g.store.Collect(orphaned)
+	if err != nil {
+		log.Warnf("Preview store cleanup after %s sync failed: %v", kind, err)
+	}
+	if removed > 0 {
+		log.Infof("Removed %d orphaned %s preview entries and files", removed, kind)
+	}
 }
 
-func (g *Generator) syncImageSource(ctx context.Context, p models.Plugin, kind, sourceURL string) bool {
+// Invalidate drops the stored previews of plugins and queues them to be rendered
+// again, ahead of any backoff. It returns how many had a stored preview.
+func (g *Generator) Invalidate(plugins []models.Plugin) (int, error) {
+	removed := 0
+	for _, p := range plugins {
+		ok, err := g.store.Remove(p.ID)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			removed++
+		}
+		g.jobs.reset(p, SourceKey(p.Screenshot, p))
+	}
+	g.saveJobs()
+	return removed, nil
+}
+
+// Start runs the workers that drain the job queue until ctx is done. At most
+// syncWorkers previews are fetched and composed at once.
+func (g *Generator) Start(ctx context.Context) {
+	for range syncWorkers {
+		go g.work(ctx)
+	}
+}
+
+// Queue reports the preview jobs waiting to run or to be retried.
+func (g *Generator) Queue() QueueStatus {
+	return g.jobs.status()
+}
+
+func (g *Generator) work(ctx context.Context) {
+	for {
+		job, wait, changed := g.jobs.claim()
+		if job == nil {
+			if !waitForJob(ctx, wait, changed) {
+				return
+			}
+			continue
+		}
+
+		err := g.runJob(ctx, job.Plugin)
+		if err != nil && ctx.Err() != nil {
+			g.jobs.release(job)
+			return
+		}
+		if err != nil {
+			log.Warnf("Preview for %s failed (attempt %d): %v", job.Plugin.ID, job.Attempts+1, err)
+		}
+		g.jobs.finish(job, err)
+		g.saveJobs()
+	}
+}
+
+func (g *Generator) saveJobs() {
+	if err := g.jobs.save(); err != nil {
+		log.Warnf("Failed to persist preview jobs: %v", err)
+	}
+}
+
+// runJob brings p's preview up to date. While a screenshot cannot be fetched the card
+// stands in for it, and the job fails so the screenshot is tried again.
+func (g *Generator) runJob(ctx context.Context, p models.Plugin) error {
+	if p.Screenshot == "" {
+		return g.syncCard(p)
+	}
+	err := g.syncImageSource(ctx, p, "screenshot", p.Screenshot)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if cardErr := g.syncCard(p); cardErr != nil {
+		return errors.Join(err, cardErr)
+	}
+	return err
+}
+
+func (g *Generator) syncImageSource(ctx context.Context, p models.Plugin, kind, sourceURL string) error {
 	key := SourceKey(sourceURL, p)
 	if !g.store.NeedsUpdate(p.ID, key) {
-		return true
+		return nil
 	}
 
-	src, err := g.fetcher.fetch(ctx, sourceURL)
+	raw, contentType, err := g.fetcher.fetchData(ctx, sourceURL)
 	if err != nil {
-		log.Warnf("Preview %s fetch failed for %s: %v", kind, p.ID, err)
-		return false
+		return fmt.Errorf("%s fetch failed: %w", kind, err)
+	}
+	src, err := decodeImage(contentType, raw)
+	if err != nil {
+		return fmt.Errorf("%s fetch failed: %w", kind, err)
 	}
 
-	card, err := ComposeScreenshot(src, p)
+	card, err := ComposeScreenshot(src, p, DarkPalette)
 	if err != nil {
-		log.Warnf("Preview composition failed for %s: %v", p.ID, err)
-		return false
+		return fmt.Errorf("composition failed: %w", err)
 	}
 
 	data, err := encodeJPEG(card)
 	if err != nil {
-		log.Warnf("Preview encoding failed for %s: %v", p.ID, err)
-		return false
+		return fmt.Errorf("encoding failed: %w", err)
 	}
 
 	if err := g.store.Put(p.ID, kind, key, "jpg", data); err != nil {
-		log.Warnf("Preview store failed for %s: %v", p.ID, err)
-		return false
+		return err
 	}
-	return true
+	g.keepSource(p.ID, key, src)
+	g.keepAnimation(p, key, raw)
+	return nil
 }
 
-func (g *Generator) syncCard(p models.Plugin) {
+func (g *Generator) syncCard(p models.Plugin) error {
 	key := SourceKey("", p)
 	if !g.store.NeedsUpdate(p.ID, key) {
-		return
+		return nil
 	}
 
-	card, err := ComposeCard(p)
+	card, err := ComposeCard(p, DarkPalette)
 	if err != nil {
-		log.Warnf("Preview card render failed for %s: %v", p.ID, err)
-		return
+		return fmt.Errorf("card render failed: %w", err)
 	}
 
 	data, err := encodePNG(card)
 	if err != nil {
-		log.Warnf("Preview card encoding failed for %s: %v", p.ID, err)
-		return
+		return fmt.Errorf("card encoding failed: %w", err)
+	}
+
+	return g.store.Put(p.ID, "card", key, "png", data)
+}
+
+// SyncThemes renders a preview card for every theme whose colors changed since the
+// last pass and points PreviewURL at the served card. Cards are drawn locally from the
+// tokens, so unlike plugin screenshots there is nothing to fetch.
+func (g *Generator) SyncThemes(ctx context.Context, themes []models.Theme) []models.Theme {
+	out := make([]models.Theme, len(themes))
+	copy(out, themes)
+
+	ids := make(map[string]bool, len(out))
+	for i := range out {
+		if ctx.Err() != nil {
+			return out
+		}
+		if !ThemeIDPattern.MatchString(out[i].ID) {
+			continue
+		}
+		ids[ThemeKey(out[i].ID)] = true
+		if g.syncThemeCard(out[i]) {
+			out[i].PreviewURL = g.publicBaseURL + "/previews/themes/" + out[i].ID
+		}
 	}
+	// A theme's social card goes with it. Author and docs cards are only drawn for
+	// handles and pages that exist, so they are left to be overwritten.
+	g.collect("theme", len(ids), func(id string) bool {
+		if themeID, ok := strings.CutPrefix(id, socialKey("themes", "")); ok {
+			return !ids[ThemeKey(themeID)]
+		}
+		return isThemeKey(id) && !ids[id]
+	})
+	return out
+}
 
-	if err := g.store.Put(p.ID, "card", key, "png", data); err != nil {
-		log.Warnf("Preview store failed for %s: %v", p.ID, err)
+func (g *Generator) syncThemeCard(t models.Theme) bool {
+	id := ThemeKey(t.ID)
+	key := ThemeSourceKey(t)
+	if !g.store.NeedsUpdate(id, key) {
+		return true
 	}
+
+	card, err := ComposeThemeCard(t)
+	if err != nil {
+		log.Warnf("Theme preview render failed for %s: %v", t.ID, err)
+		return false
+	}
+
+	data, err := encodePNG(card)
+	if err != nil {
+		log.Warnf("Theme preview encoding failed for %s: %v", t.ID, err)
+		return false
+	}
+
+	if err := g.store.Put(id, "theme", key, "png", data); err != nil {
+		log.Warnf("Preview store failed for theme %s: %v", t.ID, err)
+		return false
+	}
+	return true
 }
 
 func renderPlaceholder() ([]byte, error) {
-	img, err := ComposeCard(models.Plugin{Name: "DMS Plugin"})
+	img, err := ComposeCard(models.Plugin{Name: "DMS Plugin"}, DarkPalette)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,286 @@
+package previews
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// jobsFile is where the queue is kept, beside the store's manifest.
+const jobsFile = "jobs.json"
+
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// Job states as reported by QueueStatus.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobFailed  = "failed"
+)
+
+// previewJob is a plugin whose preview is out of date. SourceKey is the key the
+// finished preview is stored under, so a job is replaced, not retried, when the
+// plugin changes while it waits.
+type previewJob struct {
+	Plugin      models.Plugin `json:"plugin"`
+	SourceKey   string        `json:"sourceKey"`
+	Attempts    int           `json:"attempts"`
+	NextAttempt time.Time     `json:"nextAttempt"`
+	LastError   string        `json:"lastError,omitempty"`
+	EnqueuedAt  time.Time     `json:"enqueuedAt"`
+
+	running bool
+}
+
+// jobQueue holds pending preview jobs, one per plugin, persisted so a restart resumes
+// them with their backoff intact.
+type jobQueue struct {
+	path    string
+	saveMu  sync.Mutex
+	mu      sync.Mutex
+	jobs    map[string]*previewJob
+	changed chan struct{}
+	now     func() time.Time
+}
+
+func newJobQueue(path string) (*jobQueue, error) {
+	q := &jobQueue{path: path, jobs: map[string]*previewJob{}, changed: make(chan struct{}), now: time.Now}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preview jobs: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse preview jobs: %w", err)
+	}
+	return q, nil
+}
+
+// retryDelay doubles with each failed attempt, up to retryMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// enqueue schedules p's preview to be brought up to sourceKey. A job already waiting
+// for the same key keeps its backoff, so a refresh does not retry a failing screenshot
+// early.
+func (q *jobQueue) enqueue(p models.Plugin, sourceKey string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if job, ok := q.jobs[p.ID]; ok && job.SourceKey == sourceKey {
+		job.Plugin = p
+		return
+	}
+	q.replaceLocked(p, sourceKey)
+}
+
+// reset queues p as a new job, due at once, whatever was queued for it before.
+func (q *jobQueue) reset(p models.Plugin, sourceKey string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.replaceLocked(p, sourceKey)
+}
+
+// replaceLocked puts a fresh job in place of p's. A job being worked on stays marked
+// running, so it is not handed out again until that run finishes.
+func (q *jobQueue) replaceLocked(p models.Plugin, sourceKey string) {
+	now := q.now().UTC()
+	job := &previewJob{Plugin: p, SourceKey: sourceKey, NextAttempt: now, EnqueuedAt: now}
+	if prev, ok := q.jobs[p.ID]; ok {
+		job.running = prev.running
+	}
+	q.jobs[p.ID] = job
+	q.notifyLocked()
+}
+
+// retain drops jobs for plugins no longer in the registry.
+func (q *jobQueue) retain(ids map[string]bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, job := range q.jobs {
+		if !ids[id] && !job.running {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// claim hands out the job due soonest, or nil with how long until one is due and a
+// channel closed when the queue changes before then.
+func (q *jobQueue) claim() (*previewJob, time.Duration, <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next *previewJob
+	for _, job := range q.jobs {
+		if job.running {
+			continue
+		}
+		if next == nil || job.NextAttempt.Before(next.NextAttempt) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, -1, q.changed
+	}
+	if wait := next.NextAttempt.Sub(q.now()); wait > 0 {
+		return nil, wait, q.changed
+	}
+	next.running = true
+	job := *next
+	return &job, 0, q.changed
+}
+
+// waitForJob sleeps until a job may be due: wait has passed or the queue changed. A
+// negative wait, with nothing queued, waits on changes alone. It reports false once
+// ctx is done.
+func waitForJob(ctx context.Context, wait time.Duration, changed <-chan struct{}) bool {
+	var due <-chan time.Time
+	if wait >= 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		due = timer.C
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-changed:
+	case <-due:
+	}
+	return true
+}
+
+// finish records the outcome of a claimed job. A job that succeeded is dropped unless
+// the plugin changed while it ran; one that failed waits out its backoff.
+func (q *jobQueue) finish(job *previewJob, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer q.notifyLocked()
+
+	current, ok := q.jobs[job.Plugin.ID]
+	if !ok {
+		return
+	}
+	current.running = false
+	if current.SourceKey != job.SourceKey {
+		return
+	}
+	if err == nil {
+		delete(q.jobs, job.Plugin.ID)
+		return
+	}
+	current.Attempts++
+	current.LastError = err.Error()
+	current.NextAttempt = q.now().UTC().Add(retryDelay(current.Attempts))
+}
+
+// release returns a claimed job untouched, as when the server shuts down mid-fetch.
+func (q *jobQueue) release(job *previewJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if current, ok := q.jobs[job.Plugin.ID]; ok {
+		current.running = false
+	}
+	q.notifyLocked()
+}
+
+func (q *jobQueue) notifyLocked() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+func (q *jobQueue) save() error {
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+
+	q.mu.Lock()
+	data, err := json.Marshal(q.jobs)
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal preview jobs: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create preview jobs directory: %w", err)
+	}
+	if err := atomicWrite(q.path, data); err != nil {
+		return fmt.Errorf("failed to write preview jobs: %w", err)
+	}
+	return nil
+}
+
+// JobStatus is one queued preview job as reported by Generator.Queue.
+type JobStatus struct {
+	PluginID    string    `json:"pluginId"`
+	State       string    `json:"state" enum:"pending,running,failed"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+	EnqueuedAt  time.Time `json:"enqueuedAt"`
+}
+
+// QueueStatus counts queued preview jobs by state and lists them, failed ones first.
+type QueueStatus struct {
+	Pending int         `json:"pending"`
+	Running int         `json:"running"`
+	Failed  int         `json:"failed"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+func (q *jobQueue) status() QueueStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	out := QueueStatus{Jobs: make([]JobStatus, 0, len(q.jobs))}
+	for id, job := range q.jobs {
+		st := JobStatus{
+			PluginID:    id,
+			State:       JobPending,
+			Attempts:    job.Attempts,
+			NextAttempt: job.NextAttempt,
+			LastError:   job.LastError,
+			EnqueuedAt:  job.EnqueuedAt,
+		}
+		switch {
+		case job.running:
+			st.State = JobRunning
+			out.Running++
+		case job.Attempts > 0:
+			st.State = JobFailed
+			out.Failed++
+		default:
+			out.Pending++
+		}
+		out.Jobs = append(out.Jobs, st)
+	}
+
+	rank := map[string]int{JobFailed: 0, JobRunning: 1, JobPending: 2}
+	slices.SortFunc(out.Jobs, func(a, b JobStatus) int {
+		if a.State != b.State {
+			return rank[a.State] - rank[b.State]
+		}
+		if c := a.NextAttempt.Compare(b.NextAttempt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.PluginID, b.PluginID)
+	})
+	return out
+}
@@ -0,0 +1,174 @@
+package previews
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestRetryDelayDoublesUpToTheCap(t *testing.T) {
+	cases := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 40: retryMaxDelay}
+	for attempts, want := range cases {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestJobQueueBacksOffPerPlugin(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	q, err := newJobQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.now = func() time.Time { return now }
+
+	p := models.Plugin{ID: "foo", Screenshot: "https://example.com/a.png"}
+	q.enqueue(p, "k1")
+	job, _, _ := q.claim()
+	if job == nil {
+		t.Fatal("expected a new job to be due at once")
+	}
+	if again, _, _ := q.claim(); again != nil {
+		t.Fatal("expected a running job not to be handed out twice")
+	}
+	q.finish(job, errors.New("fetch failed"))
+
+	if job, wait, _ := q.claim(); job != nil || wait != retryBaseDelay {
+		t.Fatalf("expected the failed job to wait %v, got %v %v", retryBaseDelay, job, wait)
+	}
+	status := q.status()
+	if status.Failed != 1 || status.Jobs[0].LastError != "fetch failed" || status.Jobs[0].Attempts != 1 {
+		t.Fatalf("expected the failure to be visible, got %+v", status)
+	}
+
+	// A refresh with the plugin unchanged keeps the backoff; a change starts over.
+	q.enqueue(p, "k1")
+	if job, _, _ := q.claim(); job != nil {
+		t.Fatal("expected a refresh not to reset the backoff")
+	}
+	if err := q.save(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := newJobQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.status(); got.Failed != 1 || got.Jobs[0].Attempts != 1 {
+		t.Fatalf("expected the job and its attempts to persist, got %+v", got)
+	}
+
+	q.enqueue(p, "k2")
+	job, _, _ = q.claim()
+	if job == nil || job.Attempts != 0 {
+		t.Fatalf("expected a changed plugin to be due at once, got %+v", job)
+	}
+	q.finish(job, nil)
+	if got := q.status(); len(got.Jobs) != 0 {
+		t.Fatalf("expected a finished job to be dropped, got %+v", got)
+	}
+}
+
+func TestJobQueueKeepsJobsChangedWhileRunning(t *testing.T) {
+	q, err := newJobQueue(filepath.Join(t.TempDir(), "jobs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := models.Plugin{ID: "foo"}
+	q.enqueue(p, "k1")
+	job, _, _ := q.claim()
+	q.enqueue(p, "k2")
+	q.finish(job, nil)
+
+	next, _, _ := q.claim()
+	if next == nil || next.SourceKey != "k2" {
+		t.Fatalf("expected the newer job to run after the stale one, got %+v", next)
+	}
+
+	q.retain(map[string]bool{})
+	if got := q.status(); got.Running != 1 {
+		t.Fatalf("expected a running job to outlive a prune, got %+v", got)
+	}
+}
+
+func TestSyncPublishesBeforePreviewsAreGenerated(t *testing.T) {
+	data := pngBytes(t, 64, 64)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		<-release
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(data)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	g, err := NewGenerator(t.TempDir(), "https://example.com")
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	g.fetcher = allowAllFetcher()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g.Start(ctx)
+
+	plugins := []models.Plugin{
+		{ID: "shot", Name: "Shot", Screenshot: server.URL + "/shot.png"},
+		{ID: "broken", Name: "Broken", Screenshot: server.URL + "/missing.png"},
+		{ID: "plain", Name: "Plain"},
+	}
+	out := g.Sync(ctx, plugins)
+	if out[0].PreviewURL != "https://example.com/previews/shot" {
+		t.Fatalf("expected PreviewURL to be set at once, got %q", out[0].PreviewURL)
+	}
+	if _, _, ok := g.store.Lookup("shot"); ok {
+		t.Fatal("expected Sync to return before the screenshot was fetched")
+	}
+
+	waitFor(t, func() bool {
+		st := g.Queue()
+		return st.Failed == 1 && st.Running == 1
+	})
+	// The unreachable screenshot falls back to a card while it waits to be retried.
+	if entry, ok := g.store.entry("broken"); !ok || entry.SourceKind != "card" {
+		t.Fatalf("expected a card in place of the missing screenshot, got %+v", entry)
+	}
+	if entry, ok := g.store.entry("plain"); !ok || entry.SourceKind != "card" {
+		t.Fatalf("expected a card for the plugin without a screenshot, got %+v", entry)
+	}
+
+	release <- struct{}{}
+	waitFor(t, func() bool {
+		entry, ok := g.store.entry("shot")
+		return ok && entry.SourceKind == "screenshot"
+	})
+	// The job finishes once what is derived from the screenshot is kept as well.
+	waitFor(t, func() bool {
+		st := g.Queue()
+		return st.Running == 0
+	})
+	if st := g.Queue(); len(st.Jobs) != 1 || st.Jobs[0].PluginID != "broken" {
+		t.Fatalf("expected only the failing job to remain, got %+v", st)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for preview jobs")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
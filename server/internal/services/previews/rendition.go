@@ -0,0 +1,200 @@
+package previews
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/webp"
+)
+
+// RenditionWidths are the widths a preview can be requested at; anything else is
+// refused so the cache cannot be filled with arbitrary sizes.
+var RenditionWidths = []int{320, 480, 960}
+
+// Rendition is a derived form of a stored preview: narrower, WebP, or both. The zero
+// value is the preview as stored.
+type Rendition struct {
+	Width int
+	WebP  bool
+}
+
+func (r Rendition) name() string {
+	var parts []string
+	if r.Width > 0 {
+		parts = append(parts, "w"+strconv.Itoa(r.Width))
+	}
+	if r.WebP {
+		parts = append(parts, "webp")
+	}
+	return strings.Join(parts, "-")
+}
+
+// LookupRendition serves a rendition of the preview stored under id, rendering it from
+// the stored file on first request. Renditions are manifest entries of their own with
+// their own ETags, tied to the source key they were rendered from, and Put drops them
+// whenever the source changes. A width at or above the stored one adds nothing, and
+// the preview as stored is served for it.
+func (s *Store) LookupRendition(id string, r Rendition) (string, string, bool) {
+	s.mu.Lock()
+	parent, ok := s.manifest[id]
+	s.mu.Unlock()
+	if !ok {
+		return "", "", false
+	}
+
+	srcPath := filepath.Join(s.dir, parent.File)
+	config, err := decodeConfig(srcPath)
+	if err != nil {
+		return "", "", false
+	}
+	if r.Width >= config.Width {
+		r.Width = 0
+	}
+	if r == (Rendition{}) {
+		return s.Lookup(id)
+	}
+
+	key := id + "@" + r.name()
+	if path, etag, ok := s.renditionEntry(key, parent.SourceKey); ok {
+		return path, etag, true
+	}
+
+	// One render at a time: concurrent requests for a fresh rendition wait for the
+	// first rather than each decoding and encoding the same card.
+	s.renderMu.Lock()
+	defer s.renderMu.Unlock()
+	if path, etag, ok := s.renditionEntry(key, parent.SourceKey); ok {
+		return path, etag, true
+	}
+
+	src, err := decodeFile(srcPath)
+	if err != nil {
+		return "", "", false
+	}
+	data, ext, err := renderRendition(src, r, filepath.Ext(parent.File))
+	if err != nil {
+		return "", "", false
+	}
+	if err := s.putDerived(key, id, parent.SourceKind, parent.SourceKey, ext, data); err != nil {
+		return "", "", false
+	}
+	return s.renditionEntry(key, parent.SourceKey)
+}
+
+func (s *Store) renditionEntry(key, sourceKey string) (string, string, bool) {
+	s.mu.Lock()
+	entry, ok := s.manifest[key]
+	s.mu.Unlock()
+	if !ok || entry.SourceKey != sourceKey {
+		return "", "", false
+	}
+	path := filepath.Join(s.dir, entry.File)
+	if _, err := os.Stat(path); err != nil {
+		return "", "", false
+	}
+	return path, entry.ETag, true
+}
+
+// putDerived stores an entry rendered from parentID: a rendition, a palette variant or
+// a kept source image. Anything previously derived from key itself is dropped with it.
+func (s *Store) putDerived(key, parentID, sourceKind, sourceKey, ext string, data []byte) error {
+	file := key + "." + ext
+	if err := atomicWrite(filepath.Join(s.dir, file), data); err != nil {
+		return fmt.Errorf("failed to write preview %s: %w", file, err)
+	}
+
+	h := sha256.Sum256([]byte(sourceKey + "\x00" + key))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.manifest[key]; ok && prev.File != file {
+		_ = os.Remove(filepath.Join(s.dir, prev.File))
+	}
+	s.dropRenditionsLocked(key)
+	s.manifest[key] = manifestEntry{
+		SourceKind:  sourceKind,
+		SourceKey:   sourceKey,
+		File:        file,
+		ETag:        hex.EncodeToString(h[:8]),
+		GeneratedAt: time.Now().UTC(),
+		Parent:      parentID,
+	}
+	return s.saveManifestLocked()
+}
+
+// dropRenditionsLocked removes every entry derived from id, and whatever was derived
+// from those in turn.
+func (s *Store) dropRenditionsLocked(id string) {
+	for key, entry := range s.manifest {
+		if entry.Parent != id {
+			continue
+		}
+		_ = os.Remove(filepath.Join(s.dir, entry.File))
+		delete(s.manifest, key)
+		s.dropRenditionsLocked(key)
+	}
+}
+
+func decodeConfig(path string) (image.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	config, _, err := image.DecodeConfig(f)
+	return config, err
+}
+
+func decodeFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// renderRendition scales and encodes a rendition. The WebP encoder is lossless, so a
+// photographic screenshot can come out larger than its JPEG; the smaller file wins and
+// the response's Content-Type follows whichever was kept.
+func renderRendition(src image.Image, r Rendition, srcExt string) ([]byte, string, error) {
+	img := src
+	if r.Width > 0 {
+		b := src.Bounds()
+		height := max(1, b.Dy()*r.Width/b.Dx())
+		scaled := image.NewRGBA(image.Rect(0, 0, r.Width, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, b, draw.Src, nil)
+		img = scaled
+	}
+
+	encode, ext := encodePNG, "png"
+	if srcExt == ".jpg" {
+		encode, ext = encodeJPEG, "jpg"
+	}
+	fallback, err := encode(img)
+	if err != nil || !r.WebP {
+		return fallback, ext, err
+	}
+
+	var buf bytes.Buffer
+	if err := webp.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	if buf.Len() >= len(fallback) {
+		return fallback, ext, nil
+	}
+	return buf.Bytes(), "webp", nil
+}
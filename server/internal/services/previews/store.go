@@ -23,12 +23,15 @@ type manifestEntry struct {
 	File        string    `json:"file"`
 	ETag        string    `json:"etag"`
 	GeneratedAt time.Time `json:"generatedAt"`
+	// Parent is the entry a rendition was derived from; empty for source previews.
+	Parent string `json:"parent,omitempty"`
 }
 
 type Store struct {
 	dir      string
 	mu       sync.Mutex
 	manifest map[string]manifestEntry
+	renderMu sync.Mutex
 }
 
 func NewStore(cacheDir string) (*Store, error) {
@@ -44,12 +47,21 @@ func NewStore(cacheDir string) (*Store, error) {
 	return s, nil
 }
 
-const composeVersion = "v3"
+const composeVersion = "v7"
 
 func SourceKey(sourceURL string, p models.Plugin) string {
 	statuses := slices.Clone(p.Status)
 	slices.Sort(statuses)
-	parts := []string{composeVersion, sourceURL, p.Name, p.Category, p.Description, p.Author, p.Version, strings.Join(statuses, ",")}
+	parts := []string{composeVersion, sourceURL, p.Name, p.Category, p.Description, p.Author, p.Version, strings.Join(statuses, ","), iconSourceKey(p.Icon), fontSourceKey()}
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+const themeComposeVersion = "t4"
+
+func ThemeSourceKey(t models.Theme) string {
+	colors, _ := json.Marshal([]interface{}{t.Dark, t.Light, t.Variants})
+	parts := []string{themeComposeVersion, t.Name, t.Author, string(colors), fontSourceKey()}
 	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
 	return hex.EncodeToString(h[:])
 }
@@ -76,6 +88,32 @@ func (s *Store) PlaceholderPath() string {
 	return filepath.Join(s.dir, "placeholder.png")
 }
 
+func (s *Store) ThemePlaceholderPath() string {
+	return filepath.Join(s.dir, "placeholder-theme.png")
+}
+
+const themeKeyPrefix = "themes/"
+
+// ThemeKey namespaces theme entries apart from plugin ids, which share the manifest.
+func ThemeKey(themeID string) string {
+	return themeKeyPrefix + themeID
+}
+
+func isThemeKey(id string) bool {
+	return strings.HasPrefix(id, themeKeyPrefix)
+}
+
+const socialKeyPrefix = "og/"
+
+// socialKey namespaces social cards apart from previews, by what they are for.
+func socialKey(kind, name string) string {
+	return socialKeyPrefix + kind + "/" + name
+}
+
+func isSocialKey(id string) bool {
+	return strings.HasPrefix(id, socialKeyPrefix)
+}
+
 func (s *Store) NeedsUpdate(id, sourceKey string) bool {
 	s.mu.Lock()
 	entry, ok := s.manifest[id]
@@ -95,6 +133,9 @@ func (s *Store) NeedsUpdate(id, sourceKey string) bool {
 
 func (s *Store) Put(id, sourceKind, sourceKey, ext string, data []byte) error {
 	file := id + "." + ext
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(s.dir, file)), 0o755); err != nil {
+		return fmt.Errorf("failed to create preview directory: %w", err)
+	}
 	if err := atomicWrite(filepath.Join(s.dir, file), data); err != nil {
 		return fmt.Errorf("failed to write preview %s: %w", file, err)
 	}
@@ -109,6 +150,7 @@ func (s *Store) Put(id, sourceKind, sourceKey, ext string, data []byte) error {
 	if prev, ok := s.manifest[id]; ok && prev.File != file {
 		_ = os.Remove(filepath.Join(s.dir, prev.File))
 	}
+	s.dropRenditionsLocked(id)
 	s.manifest[id] = manifestEntry{
 		SourceKind:  sourceKind,
 		SourceKey:   sourceKey,
@@ -119,6 +161,13 @@ func (s *Store) Put(id, sourceKind, sourceKey, ext string, data []byte) error {
 	return s.saveManifestLocked()
 }
 
+func (s *Store) entry(id string) (manifestEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.manifest[id]
+	return entry, ok
+}
+
 func (s *Store) Lookup(id string) (string, string, bool) {
 	s.mu.Lock()
 	entry, ok := s.manifest[id]
@@ -135,7 +184,14 @@ func (s *Store) Lookup(id string) (string, string, bool) {
 }
 
 func (s *Store) EnsurePlaceholder(render func() ([]byte, error)) error {
-	path := s.PlaceholderPath()
+	return ensureFile(s.PlaceholderPath(), render)
+}
+
+func (s *Store) EnsureThemePlaceholder(render func() ([]byte, error)) error {
+	return ensureFile(s.ThemePlaceholderPath(), render)
+}
+
+func ensureFile(path string, render func() ([]byte, error)) error {
 	if _, err := os.Stat(path); err == nil {
 		return nil
 	}
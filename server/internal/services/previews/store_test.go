@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	xwebp "golang.org/x/image/webp"
+
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 )
 
@@ -35,7 +37,7 @@ func TestStoreReuseAndRegenerate(t *testing.T) {
 	}
 }
 
-func TestSourceKeyCoversVersionAndStatus(t *testing.T) {
+func TestSourceKeyCoversVersionStatusAndIcon(t *testing.T) {
 	p := models.Plugin{ID: "foo", Name: "Foo", Version: "1.0.0"}
 	base := SourceKey("u", p)
 
@@ -50,6 +52,13 @@ func TestSourceKeyCoversVersionAndStatus(t *testing.T) {
 		t.Fatal("expected sourceKey to change with status")
 	}
 
+	p.Status = nil
+	p.Icon = "widgets"
+	if SourceKey("u", p) == base {
+		t.Fatal("expected sourceKey to change with icon")
+	}
+
+	p.Icon = ""
 	p.Status = []string{"reviewed", "broken"}
 	reordered := models.Plugin{ID: "foo", Name: "Foo", Version: "1.0.0", Status: []string{"broken", "reviewed"}}
 	if SourceKey("u", p) != SourceKey("u", reordered) {
@@ -135,3 +144,86 @@ func TestStoreExtensionChangeRemovesStaleFile(t *testing.T) {
 		t.Fatalf("expected foo.png, got %q ok=%v", path, ok)
 	}
 }
+
+func TestStoreThemeKeysLiveInTheirOwnDirectory(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	if err := s.Put(ThemeKey("foo"), "theme", "k", "png", []byte("theme")); err != nil {
+		t.Fatalf("Put theme: %v", err)
+	}
+	if err := s.Put("foo", "card", "k", "png", []byte("plugin")); err != nil {
+		t.Fatalf("Put plugin: %v", err)
+	}
+
+	path, _, ok := s.Lookup(ThemeKey("foo"))
+	if !ok || path != filepath.Join(dir, "previews", "themes", "foo.png") {
+		t.Fatalf("unexpected theme path %q ok=%v", path, ok)
+	}
+	if _, _, ok := s.Lookup("foo"); !ok {
+		t.Fatal("expected plugin entry with the same id to coexist")
+	}
+}
+
+func TestStoreRenditionsAreCachedAndDroppedWithTheSource(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	p := models.Plugin{ID: "foo", Name: "Foo", Description: "A plugin"}
+	card, err := ComposeCard(p, DarkPalette)
+	if err != nil {
+		t.Fatalf("ComposeCard: %v", err)
+	}
+	data, err := encodePNG(card)
+	if err != nil {
+		t.Fatalf("encodePNG: %v", err)
+	}
+	key := SourceKey("", p)
+	if err := s.Put("foo", "card", key, "png", data); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	_, sourceETag, _ := s.Lookup("foo")
+
+	path, etag, ok := s.LookupRendition("foo", Rendition{Width: 320, WebP: true})
+	if !ok {
+		t.Fatal("expected rendition to render")
+	}
+	if filepath.Base(path) != "foo@w320-webp.webp" {
+		t.Fatalf("expected a WebP file, got %q", path)
+	}
+	if etag == sourceETag {
+		t.Fatal("expected the rendition to have its own etag")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := xwebp.DecodeConfig(f)
+	f.Close()
+	if err != nil || config.Width != 320 || config.Height != 180 {
+		t.Fatalf("expected a 320x180 WebP, got %+v err=%v", config, err)
+	}
+
+	if again, againETag, _ := s.LookupRendition("foo", Rendition{Width: 320, WebP: true}); again != path || againETag != etag {
+		t.Fatal("expected the cached rendition to be reused")
+	}
+	if full, fullETag, _ := s.LookupRendition("foo", Rendition{Width: 960}); filepath.Base(full) != "foo.png" || fullETag != sourceETag {
+		t.Fatalf("expected the full width to serve the source, got %q", full)
+	}
+
+	if err := s.Put("foo", "card", SourceKey("", models.Plugin{ID: "foo", Name: "Bar"}), "png", data); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected the rendition file to be removed with its source")
+	}
+	if _, fresh, ok := s.LookupRendition("foo", Rendition{Width: 320, WebP: true}); !ok || fresh == etag {
+		t.Fatal("expected the rendition to be re-rendered under a new etag")
+	}
+}
@@ -3,190 +3,332 @@ package previews
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"image/color"
-	"strconv"
+	"io"
+	"sort"
 	"strings"
+)
 
-	"github.com/fogleman/gg"
+const (
+	// maxSVGDepth bounds element nesting, and maxSVGNodes the elements a document may
+	// have, so a crafted file cannot exhaust the stack or the renderer.
+	maxSVGDepth = 256
+	maxSVGNodes = 50000
 )
 
-type svgText struct {
-	x, y    float64
-	size    float64
-	bold    bool
-	anchor  string
-	fill    color.NRGBA
-	content string
+var errSVGExternalRef = errors.New("svg references an external resource")
+
+// svgNode is one element of a parsed document, or a run of character data when name
+// is empty. Text content is kept in document order alongside tspans.
+type svgNode struct {
+	name     string
+	attrs    map[string]string
+	children []*svgNode
+	text     string
 }
 
-type svgFrame struct {
-	tx, ty      float64
-	unsupported bool
+type svgDocument struct {
+	root *svgNode
+	ids  map[string]*svgNode
+	css  []cssRule
 }
 
-func parseTranslate(transform string) (float64, float64, bool) {
-	t := strings.TrimSpace(transform)
-	if t == "" {
-		return 0, 0, true
-	}
-	if !strings.HasPrefix(t, "translate(") || !strings.HasSuffix(t, ")") {
-		return 0, 0, false
-	}
-	inner := strings.TrimSuffix(strings.TrimPrefix(t, "translate("), ")")
-	fields := strings.FieldsFunc(inner, func(r rune) bool { return r == ',' || r == ' ' })
-	if len(fields) == 0 || len(fields) > 2 {
-		return 0, 0, false
-	}
-	x, err := strconv.ParseFloat(fields[0], 64)
-	if err != nil {
-		return 0, 0, false
-	}
-	y := 0.0
-	if len(fields) == 2 {
-		y, err = strconv.ParseFloat(fields[1], 64)
+// parseSVGDocument reads a document into a tree, refusing anything that would make a
+// renderer reach outside it: hrefs other than fragment or data: references, url()
+// values other than fragments, and stylesheet imports. @font-face rules are dropped,
+// so text falls back to the embedded fonts instead of fetching one.
+func parseSVGDocument(data []byte) (*svgDocument, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	doc := &svgDocument{ids: map[string]*svgNode{}}
+
+	var stack []*svgNode
+	nodes := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return 0, 0, false
+			return nil, fmt.Errorf("failed to parse svg: %w", err)
 		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			nodes++
+			if nodes > maxSVGNodes || len(stack) >= maxSVGDepth {
+				return nil, errors.New("svg is too complex to render")
+			}
+			node := &svgNode{name: el.Name.Local, attrs: make(map[string]string, len(el.Attr))}
+			for _, attr := range el.Attr {
+				if err := checkSVGReference(node.name, attr.Name.Local, attr.Value); err != nil {
+					return nil, err
+				}
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			if id := node.attrs["id"]; id != "" {
+				if _, seen := doc.ids[id]; !seen {
+					doc.ids[id] = node
+				}
+			}
+			if len(stack) == 0 {
+				if doc.root != nil {
+					return nil, errors.New("svg has more than one root element")
+				}
+				doc.root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if node.name == "style" {
+				rules, err := parseCSS(svgNodeText(node), len(doc.css))
+				if err != nil {
+					return nil, err
+				}
+				doc.css = append(doc.css, rules...)
+			}
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, &svgNode{text: string(el)})
+		}
+	}
+
+	if doc.root == nil || doc.root.name != "svg" {
+		return nil, errors.New("document is not an svg")
 	}
-	return x, y, true
+	sort.SliceStable(doc.css, func(i, j int) bool {
+		return doc.css[i].specificity < doc.css[j].specificity
+	})
+	return doc, nil
 }
 
-func parseSVGColor(s string) color.NRGBA {
-	s = strings.TrimSpace(s)
-	if !strings.HasPrefix(s, "#") {
-		return color.NRGBA{A: 0xFF}
+// checkSVGReference rejects attribute values that point outside the document. Links
+// are left alone since nothing follows them.
+func checkSVGReference(element, attr, value string) error {
+	if attr == "href" && element != "a" {
+		v := strings.TrimSpace(value)
+		if strings.HasPrefix(v, "#") || (element == "image" && hasDataScheme(v)) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", errSVGExternalRef, truncateRef(v))
 	}
-	hexPart := s[1:]
-	if len(hexPart) == 3 {
-		hexPart = string([]byte{hexPart[0], hexPart[0], hexPart[1], hexPart[1], hexPart[2], hexPart[2]})
+	return checkSVGURLs(value)
+}
+
+// checkSVGURLs rejects any url() in value that is not a fragment or data: reference.
+func checkSVGURLs(value string) error {
+	rest := value
+	for {
+		i := strings.Index(strings.ToLower(rest), "url(")
+		if i < 0 {
+			return nil
+		}
+		rest = rest[i+4:]
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			end = len(rest)
+		}
+		ref := strings.Trim(strings.TrimSpace(rest[:end]), `"'`)
+		if !strings.HasPrefix(ref, "#") && !hasDataScheme(ref) {
+			return fmt.Errorf("%w: %s", errSVGExternalRef, truncateRef(ref))
+		}
+		rest = rest[end:]
 	}
-	if len(hexPart) != 6 {
-		return color.NRGBA{A: 0xFF}
+}
+
+func hasDataScheme(ref string) bool {
+	return len(ref) >= 5 && strings.EqualFold(ref[:5], "data:")
+}
+
+func truncateRef(ref string) string {
+	if len(ref) > 64 {
+		return ref[:64] + "…"
 	}
-	v, err := strconv.ParseUint(hexPart, 16, 32)
-	if err != nil {
-		return color.NRGBA{A: 0xFF}
+	return ref
+}
+
+func svgNodeText(node *svgNode) string {
+	var b strings.Builder
+	for _, child := range node.children {
+		if child.name == "" {
+			b.WriteString(child.text)
+		}
 	}
-	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}
+	return b.String()
 }
 
-func parseSVGTexts(data []byte) ([]svgText, error) {
-	dec := xml.NewDecoder(bytes.NewReader(data))
-	var stack []svgFrame
-	var texts []svgText
-	var active *svgText
+// cssRule is one simple selector from a <style> sheet with its declarations. Only
+// type, class and id selectors, alone or compounded, are understood; rules with any
+// other selector are skipped.
+type cssRule struct {
+	tag         string
+	id          string
+	classes     []string
+	specificity int
+	decls       map[string]string
+}
 
-	frameAt := func() svgFrame {
-		if len(stack) == 0 {
-			return svgFrame{}
+func (r *cssRule) matches(node *svgNode) bool {
+	if r.tag != "" && r.tag != "*" && r.tag != node.name {
+		return false
+	}
+	if r.id != "" && node.attrs["id"] != r.id {
+		return false
+	}
+	if len(r.classes) > 0 {
+		have := strings.Fields(node.attrs["class"])
+		for _, want := range r.classes {
+			found := false
+			for _, c := range have {
+				if c == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
 		}
-		return stack[len(stack)-1]
 	}
+	return true
+}
 
+// parseCSS reads the rules of a <style> sheet. order offsets the specificity
+// tiebreak so later sheets win over earlier ones.
+func parseCSS(sheet string, order int) ([]cssRule, error) {
+	sheet = stripCSSComments(sheet)
+	var rules []cssRule
 	for {
-		tok, err := dec.Token()
-		if err != nil {
-			break
+		sheet = strings.TrimSpace(sheet)
+		if sheet == "" {
+			return rules, nil
 		}
-		switch el := tok.(type) {
-		case xml.StartElement:
-			parent := frameAt()
-			frame := parent
-			for _, attr := range el.Attr {
-				if attr.Name.Local != "transform" {
-					continue
-				}
-				dx, dy, ok := parseTranslate(attr.Value)
-				if !ok {
-					frame.unsupported = true
-					continue
-				}
-				frame.tx += dx
-				frame.ty += dy
-			}
-			stack = append(stack, frame)
 
-			if el.Name.Local != "text" {
-				continue
-			}
-			if frame.unsupported {
-				return nil, fmt.Errorf("svg text uses an unsupported transform")
+		if strings.HasPrefix(sheet, "@") {
+			semi := strings.IndexByte(sheet, ';')
+			brace := strings.IndexByte(sheet, '{')
+			if strings.HasPrefix(strings.ToLower(sheet), "@import") {
+				return nil, fmt.Errorf("%w: stylesheet import", errSVGExternalRef)
 			}
-			t := svgText{size: 16, anchor: "start", fill: color.NRGBA{A: 0xFF}}
-			for _, attr := range el.Attr {
-				switch attr.Name.Local {
-				case "x":
-					t.x, _ = strconv.ParseFloat(attr.Value, 64)
-				case "y":
-					t.y, _ = strconv.ParseFloat(attr.Value, 64)
-				case "font-size":
-					if v, err := strconv.ParseFloat(strings.TrimSuffix(attr.Value, "px"), 64); err == nil {
-						t.size = v
-					}
-				case "font-weight":
-					if attr.Value == "bold" {
-						t.bold = true
-						continue
-					}
-					if v, err := strconv.Atoi(attr.Value); err == nil && v >= 600 {
-						t.bold = true
-					}
-				case "text-anchor":
-					t.anchor = attr.Value
-				case "fill":
-					t.fill = parseSVGColor(attr.Value)
+			if brace < 0 || (semi >= 0 && semi < brace) {
+				if semi < 0 {
+					return rules, nil
 				}
+				sheet = sheet[semi+1:]
+				continue
 			}
-			t.x += frame.tx
-			t.y += frame.ty
-			active = &t
-		case xml.CharData:
-			if active != nil {
-				active.content += string(el)
-			}
-		case xml.EndElement:
-			if el.Name.Local == "text" && active != nil {
-				active.content = strings.TrimSpace(active.content)
-				if active.content != "" {
-					texts = append(texts, *active)
-				}
-				active = nil
+			// Blocks such as @font-face and @media are skipped whole.
+			sheet = sheet[cssBlockEnd(sheet, brace):]
+			continue
+		}
+
+		brace := strings.IndexByte(sheet, '{')
+		if brace < 0 {
+			return rules, nil
+		}
+		end := cssBlockEnd(sheet, brace)
+		selectors := sheet[:brace]
+		body := strings.TrimSuffix(sheet[brace+1:end], "}")
+		sheet = sheet[end:]
+
+		if err := checkSVGURLs(body); err != nil {
+			return nil, err
+		}
+		decls := parseSVGDeclarations(body)
+		for _, sel := range strings.Split(selectors, ",") {
+			rule, ok := parseCSSSelector(strings.TrimSpace(sel))
+			if !ok {
+				continue
 			}
-			if len(stack) > 0 {
-				stack = stack[:len(stack)-1]
+			rule.decls = decls
+			rule.specificity = rule.specificity*maxSVGNodes + order + len(rules)
+			rules = append(rules, rule)
+		}
+	}
+}
+
+// cssBlockEnd returns the index just past the block opened at sheet[open].
+func cssBlockEnd(sheet string, open int) int {
+	depth := 0
+	for i := open; i < len(sheet); i++ {
+		switch sheet[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
 			}
 		}
 	}
-	return texts, nil
+	return len(sheet)
 }
 
-func drawSVGTexts(dc *gg.Context, texts []svgText, offsetX, offsetY, scale float64) error {
-	if err := loadFonts(); err != nil {
-		return err
+func stripCSSComments(s string) string {
+	for {
+		start := strings.Index(s, "/*")
+		if start < 0 {
+			return s
+		}
+		end := strings.Index(s[start+2:], "*/")
+		if end < 0 {
+			return s[:start]
+		}
+		s = s[:start] + " " + s[start+2+end+2:]
 	}
-	for _, t := range texts {
-		fnt := regularFont
-		if t.bold {
-			fnt = boldFont
+}
+
+func parseCSSSelector(sel string) (cssRule, bool) {
+	if sel == "" || strings.ContainsAny(sel, " >+~:[") {
+		return cssRule{}, false
+	}
+	var rule cssRule
+	for sel != "" {
+		next := strings.IndexAny(sel[1:], ".#")
+		part := sel
+		if next >= 0 {
+			part, sel = sel[:next+1], sel[next+1:]
+		} else {
+			sel = ""
 		}
-		face, err := newFace(fnt, t.size*scale)
-		if err != nil {
-			return err
+		switch part[0] {
+		case '.':
+			rule.classes = append(rule.classes, part[1:])
+			rule.specificity += 10
+		case '#':
+			rule.id = part[1:]
+			rule.specificity += 100
+		default:
+			rule.tag = part
+			if part != "*" {
+				rule.specificity++
+			}
 		}
-		dc.SetFontFace(face)
-		dc.SetColor(t.fill)
+	}
+	return rule, true
+}
 
-		x := (t.x - offsetX) * scale
-		y := (t.y - offsetY) * scale
-		w, _ := dc.MeasureString(t.content)
-		switch t.anchor {
-		case "middle":
-			x -= w / 2
-		case "end":
-			x -= w
+// parseSVGDeclarations reads "name: value; ..." as found in style attributes and
+// stylesheet rules.
+func parseSVGDeclarations(s string) map[string]string {
+	decls := map[string]string{}
+	for _, decl := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
 		}
-		dc.DrawString(t.content, x, y)
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		decls[strings.ToLower(strings.TrimSpace(name))] = value
 	}
-	return nil
+	return decls
 }
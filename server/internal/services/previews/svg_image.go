@@ -0,0 +1,141 @@
+package previews
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// svgImageTypes are the media types an <image> may embed. SVG is left out: a document
+// nested as data could nest another in turn.
+var svgImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/jpg":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// decodeDataImage decodes the raster image in a data: URI, with the same size limits as
+// a fetched image.
+func decodeDataImage(ref string) (image.Image, error) {
+	ref = strings.TrimSpace(ref)
+	if !hasDataScheme(ref) {
+		return nil, errors.New("image is not a data uri")
+	}
+	meta, payload, ok := strings.Cut(ref[len("data:"):], ",")
+	if !ok {
+		return nil, errors.New("malformed data uri")
+	}
+	params := strings.Split(strings.ToLower(meta), ";")
+	if !svgImageTypes[strings.TrimSpace(params[0])] {
+		return nil, fmt.Errorf("unsupported embedded image type %q", params[0])
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if params[len(params)-1] == "base64" {
+		payload = strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+				return -1
+			}
+			return r
+		}, payload)
+		if unescaped, err := url.PathUnescape(payload); err == nil {
+			payload = unescaped
+		}
+		data, err = base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+		}
+	} else {
+		var text string
+		text, err = url.PathUnescape(payload)
+		data = []byte(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malformed data uri: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("embedded image exceeds %d byte limit", maxImageBytes)
+	}
+	return decodeRaster(data)
+}
+
+// drawImage draws an <image> embedded as a data: URI, fitted to its viewport by
+// preserveAspectRatio. With slice, only the part of the image inside the viewport is
+// drawn. Images that cannot be decoded are skipped, as a browser shows nothing for them.
+func (r *svgRenderer) drawImage(node *svgNode, ctx svgContext) {
+	if ctx.style.hidden || ctx.style.opacity <= 0 {
+		return
+	}
+	// An image drawn many times over through <use> is decoded once.
+	src, ok := r.images[node]
+	if !ok {
+		src, _ = decodeDataImage(node.attrs["href"])
+		if r.images == nil {
+			r.images = map[*svgNode]image.Image{}
+		}
+		r.images[node] = src
+	}
+	if src == nil || src.Bounds().Empty() {
+		return
+	}
+	b := src.Bounds()
+
+	vp, fs := ctx.vp, ctx.style.fontSize
+	x := attrLength(node, "x", vp.w, fs, 0)
+	y := attrLength(node, "y", vp.h, fs, 0)
+	w := attrLength(node, "width", vp.w, fs, float64(b.Dx()))
+	h := attrLength(node, "height", vp.h, fs, float64(b.Dy()))
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	imageBox := svgBox{x: float64(b.Min.X), y: float64(b.Min.Y), w: float64(b.Dx()), h: float64(b.Dy())}
+	fit := viewBoxTransform(imageBox, w, h, node.attrs["preserveAspectRatio"])
+
+	inv := fit.Invert()
+	x0, y0 := inv.Transform(0, 0)
+	x1, y1 := inv.Transform(w, h)
+	visible := image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1))).Intersect(b)
+	if visible.Empty() {
+		return
+	}
+
+	m := ctx.ctm.Translate(x, y).Mult(fit)
+	if !r.scanner.spend(deviceBounds(m, visible).Intersect(r.img.Bounds())) {
+		return
+	}
+	var opts *draw.Options
+	if ctx.style.opacity < 1 {
+		opts = &draw.Options{SrcMask: image.NewUniform(color.Alpha{A: uint8(math.Round(ctx.style.opacity * 255))})}
+	}
+	draw.BiLinear.Transform(r.img, f64.Aff3{m.A, m.C, m.E, m.B, m.D, m.F}, src, visible, draw.Over, opts)
+}
+
+// deviceBounds is the pixel rectangle covering rect once m is applied.
+func deviceBounds(m rasterx.Matrix2D, rect image.Rectangle) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range []image.Point{rect.Min, {X: rect.Max.X, Y: rect.Min.Y}, rect.Max, {X: rect.Min.X, Y: rect.Max.Y}} {
+		x, y := m.Transform(float64(p.X), float64(p.Y))
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	clamp := func(v float64) int {
+		return int(math.Max(-1<<30, math.Min(1<<30, v)))
+	}
+	return image.Rect(clamp(math.Floor(minX)), clamp(math.Floor(minY)), clamp(math.Ceil(maxX)), clamp(math.Ceil(maxY)))
+}
@@ -0,0 +1,324 @@
+package previews
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+)
+
+type svgPaintKind uint8
+
+const (
+	paintNone svgPaintKind = iota
+	paintColor
+	paintRef
+)
+
+// svgPaint is a fill or stroke value. A reference to something that is not a gradient
+// paints the fallback, or nothing.
+type svgPaint struct {
+	kind     svgPaintKind
+	color    color.NRGBA
+	current  bool
+	ref      string
+	fallback *svgPaint
+}
+
+func parseSVGPaint(v string) (svgPaint, bool) {
+	lower := strings.ToLower(strings.TrimSpace(v))
+	switch lower {
+	case "none":
+		return svgPaint{kind: paintNone}, true
+	case "currentcolor":
+		return svgPaint{kind: paintColor, current: true}, true
+	}
+
+	if strings.HasPrefix(lower, "url(") {
+		end := strings.IndexByte(lower, ')')
+		if end < 0 {
+			return svgPaint{}, false
+		}
+		p := svgPaint{kind: paintRef}
+		if ref := strings.Trim(strings.TrimSpace(v[4:end]), `"'`); strings.HasPrefix(ref, "#") {
+			p.ref = ref[1:]
+		}
+		if rest := strings.TrimSpace(v[end+1:]); rest != "" {
+			if fallback, ok := parseSVGPaint(rest); ok && fallback.kind != paintRef {
+				p.fallback = &fallback
+			}
+		}
+		return p, true
+	}
+
+	c, ok := parseSVGColor(v)
+	return svgPaint{kind: paintColor, color: c}, ok
+}
+
+// svgGradient is a linear or radial gradient with its href chain resolved.
+type svgGradient struct {
+	radial         bool
+	bboxUnits      bool
+	transform      rasterx.Matrix2D
+	spread         string
+	x1, y1, x2, y2 float64
+	cx, cy, r      float64
+	fx, fy         float64
+	stops          []gradientStop
+}
+
+type gradientStop struct {
+	offset float64
+	color  color.NRGBA
+}
+
+// maxGradientRefs bounds how many gradients an href chain may pass through.
+const maxGradientRefs = 8
+
+// gradient resolves the gradient with the given id. Attributes and stops a gradient
+// leaves out come from the one it references, as the spec describes.
+func (doc *svgDocument) gradient(id string, vp svgViewport) *svgGradient {
+	node := doc.ids[id]
+	if node == nil || (node.name != "linearGradient" && node.name != "radialGradient") {
+		return nil
+	}
+
+	chain := []*svgNode{node}
+	for len(chain) < maxGradientRefs {
+		next := doc.ids[strings.TrimPrefix(chain[len(chain)-1].attrs["href"], "#")]
+		if next == nil || (next.name != "linearGradient" && next.name != "radialGradient") {
+			break
+		}
+		chain = append(chain, next)
+	}
+	attr := func(name string) (string, bool) {
+		for _, n := range chain {
+			if v, ok := n.attrs[name]; ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	g := &svgGradient{radial: node.name == "radialGradient", transform: rasterx.Identity, spread: "pad"}
+	units, _ := attr("gradientUnits")
+	g.bboxUnits = units != "userSpaceOnUse"
+	if v, ok := attr("gradientTransform"); ok {
+		if m, ok := parseSVGTransform(v); ok {
+			g.transform = m
+		}
+	}
+	if v, ok := attr("spreadMethod"); ok {
+		g.spread = v
+	}
+
+	// In bounding-box units a percentage is a fraction of the box, which is the unit
+	// square here.
+	refW, refH, refD := vp.w, vp.h, vp.diagonal()
+	if g.bboxUnits {
+		refW, refH, refD = 1, 1, 1
+	}
+	length := func(name string, ref float64, def string) float64 {
+		v, ok := attr(name)
+		if !ok {
+			v = def
+		}
+		f, ok := parseSVGLength(v, ref, 16)
+		if !ok {
+			f, _ = parseSVGLength(def, ref, 16)
+		}
+		return f
+	}
+	if g.radial {
+		g.cx = length("cx", refW, "50%")
+		g.cy = length("cy", refH, "50%")
+		g.r = length("r", refD, "50%")
+		g.fx, g.fy = g.cx, g.cy
+		if _, ok := attr("fx"); ok {
+			g.fx = length("fx", refW, "50%")
+		}
+		if _, ok := attr("fy"); ok {
+			g.fy = length("fy", refH, "50%")
+		}
+	} else {
+		g.x1 = length("x1", refW, "0%")
+		g.y1 = length("y1", refH, "0%")
+		g.x2 = length("x2", refW, "100%")
+		g.y2 = length("y2", refH, "0%")
+	}
+
+	for _, n := range chain {
+		if g.stops = doc.gradientStops(n); len(g.stops) > 0 {
+			break
+		}
+	}
+	return g
+}
+
+func (doc *svgDocument) gradientStops(node *svgNode) []gradientStop {
+	var stops []gradientStop
+	last := 0.0
+	for _, child := range node.children {
+		if child.name != "stop" {
+			continue
+		}
+		props := doc.properties(child)
+		offset := parseOpacity(child.attrs["offset"], 0)
+		// Offsets never go backwards; a smaller one is raised to the previous.
+		offset = math.Max(offset, last)
+		last = offset
+
+		c := color.NRGBA{A: 0xFF}
+		if v, ok := props["stop-color"]; ok {
+			if v == "currentColor" {
+				v = props["color"]
+			}
+			if parsed, ok := parseSVGColor(v); ok {
+				c = parsed
+			}
+		}
+		if v, ok := props["stop-opacity"]; ok {
+			c.A = uint8(math.Round(float64(c.A) * parseOpacity(v, 1)))
+		}
+		stops = append(stops, gradientStop{offset: offset, color: c})
+	}
+	return stops
+}
+
+// colorFunc paints the gradient, with m taking gradient space to device pixels.
+func (g *svgGradient) colorFunc(m rasterx.Matrix2D, opacity float64) rasterx.ColorFunc {
+	if len(g.stops) == 0 || m.A*m.D-m.B*m.C == 0 {
+		return nil
+	}
+	var lut [256]color.NRGBA
+	for i := range lut {
+		c := g.colorAt(float64(i) / 255)
+		c.A = uint8(math.Round(float64(c.A) * opacity))
+		lut[i] = c
+	}
+	last := lut[255]
+	inv := m.Invert()
+
+	var param func(x, y float64) (float64, bool)
+	if g.radial {
+		param = g.radialParam()
+	} else {
+		dx, dy := g.x2-g.x1, g.y2-g.y1
+		length2 := dx*dx + dy*dy
+		param = func(x, y float64) (float64, bool) {
+			if length2 == 0 {
+				return 0, false
+			}
+			return ((x-g.x1)*dx + (y-g.y1)*dy) / length2, true
+		}
+	}
+
+	return func(x, y int) color.Color {
+		gx, gy := inv.Transform(float64(x)+0.5, float64(y)+0.5)
+		t, ok := param(gx, gy)
+		if !ok {
+			return last
+		}
+		return lut[int(math.Round(spreadParam(t, g.spread)*255))]
+	}
+}
+
+// radialParam finds, for a point, the t whose circle passes through it, the circles
+// running from the focal point at t=0 to the outer circle at t=1. A focal point outside
+// the circle is moved just inside it, as SVG 1.1 specifies.
+func (g *svgGradient) radialParam() func(x, y float64) (float64, bool) {
+	fx, fy := g.fx, g.fy
+	if d := math.Hypot(fx-g.cx, fy-g.cy); d > g.r*0.999 && d > 0 {
+		k := g.r * 0.999 / d
+		fx, fy = g.cx+(fx-g.cx)*k, g.cy+(fy-g.cy)*k
+	}
+	dx, dy := g.cx-fx, g.cy-fy
+	a := dx*dx + dy*dy - g.r*g.r
+	return func(x, y float64) (float64, bool) {
+		if g.r <= 0 {
+			return 0, false
+		}
+		qx, qy := x-fx, y-fy
+		b := qx*dx + qy*dy
+		c := qx*qx + qy*qy
+		return (b - math.Sqrt(math.Max(0, b*b-a*c))) / a, true
+	}
+}
+
+func spreadParam(t float64, spread string) float64 {
+	switch spread {
+	case "reflect":
+		t = math.Mod(math.Abs(t), 2)
+		if t > 1 {
+			t = 2 - t
+		}
+		return t
+	case "repeat":
+		return t - math.Floor(t)
+	}
+	return math.Max(0, math.Min(1, t))
+}
+
+func (g *svgGradient) colorAt(t float64) color.NRGBA {
+	stops := g.stops
+	if t <= stops[0].offset {
+		return stops[0].color
+	}
+	for i := 1; i < len(stops); i++ {
+		if t > stops[i].offset {
+			continue
+		}
+		a, b := stops[i-1], stops[i]
+		span := b.offset - a.offset
+		if span <= 0 {
+			return b.color
+		}
+		f := (t - a.offset) / span
+		mix := func(x, y uint8) uint8 {
+			return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+		}
+		return color.NRGBA{R: mix(a.color.R, b.color.R), G: mix(a.color.G, b.color.G), B: mix(a.color.B, b.color.B), A: mix(a.color.A, b.color.A)}
+	}
+	return stops[len(stops)-1].color
+}
+
+// paintSource turns a paint into what the rasterizer draws with: a color, a gradient
+// color function, or nil when nothing is painted. bbox is the element's bounding box in
+// user space, which bounding-box gradients are laid over.
+func (doc *svgDocument) paintSource(p svgPaint, st *svgStyle, opacity float64, ctm rasterx.Matrix2D, bbox svgBox, vp svgViewport) any {
+	switch p.kind {
+	case paintColor:
+		c := p.color
+		if p.current {
+			c = st.color
+		}
+		c.A = uint8(math.Round(float64(c.A) * opacity))
+		if c.A == 0 {
+			return nil
+		}
+		return c
+	case paintRef:
+		g := doc.gradient(p.ref, vp)
+		if g == nil {
+			if p.fallback != nil {
+				return doc.paintSource(*p.fallback, st, opacity, ctm, bbox, vp)
+			}
+			return nil
+		}
+		if len(g.stops) == 1 {
+			return doc.paintSource(svgPaint{kind: paintColor, color: g.stops[0].color}, st, opacity, ctm, bbox, vp)
+		}
+		m := ctm
+		if g.bboxUnits {
+			if bbox.w <= 0 || bbox.h <= 0 {
+				return nil
+			}
+			m = m.Mult(rasterx.Matrix2D{A: bbox.w, D: bbox.h, E: bbox.x, F: bbox.y})
+		}
+		if f := g.colorFunc(m.Mult(g.transform), opacity); f != nil {
+			return f
+		}
+	}
+	return nil
+}
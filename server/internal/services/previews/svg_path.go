@@ -0,0 +1,425 @@
+package previews
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/math/fixed"
+)
+
+type svgPathOp uint8
+
+const (
+	pathMoveTo svgPathOp = iota
+	pathLineTo
+	pathQuadTo
+	pathCubeTo
+	pathClose
+)
+
+// svgPath is an outline in user space. Coordinates stay in floating point until the
+// path is handed to the rasterizer in device space, so a small viewBox scaled up to
+// the raster width loses no precision to fixed point.
+type svgPath struct {
+	ops []svgPathOp
+	pts []float64
+}
+
+func (p *svgPath) moveTo(x, y float64) {
+	p.ops = append(p.ops, pathMoveTo)
+	p.pts = append(p.pts, x, y)
+}
+
+func (p *svgPath) lineTo(x, y float64) {
+	p.ops = append(p.ops, pathLineTo)
+	p.pts = append(p.pts, x, y)
+}
+
+func (p *svgPath) quadTo(x1, y1, x, y float64) {
+	p.ops = append(p.ops, pathQuadTo)
+	p.pts = append(p.pts, x1, y1, x, y)
+}
+
+func (p *svgPath) cubeTo(x1, y1, x2, y2, x, y float64) {
+	p.ops = append(p.ops, pathCubeTo)
+	p.pts = append(p.pts, x1, y1, x2, y2, x, y)
+}
+
+func (p *svgPath) close() {
+	p.ops = append(p.ops, pathClose)
+}
+
+func (p *svgPath) empty() bool {
+	return len(p.pts) == 0
+}
+
+// bounds returns the box around the path's points, control points included. That can
+// overshoot a curve slightly, which only matters for bounding-box gradients.
+func (p *svgPath) bounds() (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for i := 0; i+1 < len(p.pts); i += 2 {
+		minX, maxX = math.Min(minX, p.pts[i]), math.Max(maxX, p.pts[i])
+		minY, maxY = math.Min(minY, p.pts[i+1]), math.Max(maxY, p.pts[i+1])
+	}
+	return minX, minY, maxX, maxY
+}
+
+// addTo sends the path through m into a rasterizer.
+func (p *svgPath) addTo(a rasterx.Adder, m rasterx.Matrix2D) {
+	// Points far off the canvas are pulled in to where fixed point still holds them.
+	const limit = 1 << 22
+	toFixed := func(v float64) fixed.Int26_6 {
+		return fixed.Int26_6(math.Round(math.Max(-limit, math.Min(limit, v)) * 64))
+	}
+	pt := func(i int) fixed.Point26_6 {
+		x, y := m.Transform(p.pts[i], p.pts[i+1])
+		return fixed.Point26_6{X: toFixed(x), Y: toFixed(y)}
+	}
+
+	open := false
+	i := 0
+	for _, op := range p.ops {
+		switch op {
+		case pathMoveTo:
+			if open {
+				a.Stop(false)
+			}
+			a.Start(pt(i))
+			open = true
+			i += 2
+		case pathLineTo:
+			a.Line(pt(i))
+			i += 2
+		case pathQuadTo:
+			a.QuadBezier(pt(i), pt(i+2))
+			i += 4
+		case pathCubeTo:
+			a.CubeBezier(pt(i), pt(i+2), pt(i+4))
+			i += 6
+		case pathClose:
+			if open {
+				a.Stop(true)
+				open = false
+			}
+		}
+	}
+	if open {
+		a.Stop(false)
+	}
+}
+
+// arcTo appends an elliptical arc from (x1, y1) as cubic Béziers, following the
+// endpoint-to-center conversion in the SVG implementation notes.
+func (p *svgPath) arcTo(x1, y1, rx, ry, rotation float64, large, sweep bool, x2, y2 float64) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(x2, y2)
+		return
+	}
+
+	phi := rotation * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+	dx, dy := (x1-x2)/2, (y1-y2)/2
+	x1p := cosPhi*dx + sinPhi*dy
+	y1p := -sinPhi*dx + cosPhi*dy
+
+	if lambda := x1p*x1p/(rx*rx) + y1p*y1p/(ry*ry); lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx, ry = rx*s, ry*s
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1p / ry
+	cyp := -coef * ry * x1p / rx
+	cx := cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	delta := angle((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	point := func(a float64) (float64, float64) {
+		ca, sa := math.Cos(a), math.Sin(a)
+		return cx + rx*ca*cosPhi - ry*sa*sinPhi, cy + rx*ca*sinPhi + ry*sa*cosPhi
+	}
+	tangent := func(a float64) (float64, float64) {
+		ca, sa := math.Cos(a), math.Sin(a)
+		return -rx*sa*cosPhi - ry*ca*sinPhi, -rx*sa*sinPhi + ry*ca*cosPhi
+	}
+
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := 0; i < n; i++ {
+		a1 := theta + float64(i)*step
+		a2 := a1 + step
+		px1, py1 := point(a1)
+		tx1, ty1 := tangent(a1)
+		px2, py2 := point(a2)
+		tx2, ty2 := tangent(a2)
+		if i == n-1 {
+			px2, py2 = x2, y2
+		}
+		p.cubeTo(px1+k*tx1, py1+k*ty1, px2-k*tx2, py2-k*ty2, px2, py2)
+	}
+}
+
+// parsePathData reads path data. As the spec asks, a path with an error renders up to
+// the segment where the error occurs.
+func parsePathData(d string) *svgPath {
+	s := numberScanner{s: d}
+	p := &svgPath{}
+	var (
+		cmd          byte
+		cx, cy       float64
+		startX       float64
+		startY       float64
+		ctrlX, ctrlY float64
+		prev         byte
+	)
+	for {
+		s.skipSeparators()
+		if s.done() {
+			return p
+		}
+		if c := s.s[s.i]; isPathCommand(c) {
+			cmd = c
+			s.i++
+		} else if cmd == 0 || cmd == 'z' || cmd == 'Z' {
+			return p
+		}
+		if p.empty() && cmd != 'M' && cmd != 'm' {
+			return p
+		}
+
+		var ox, oy float64
+		if cmd >= 'a' {
+			ox, oy = cx, cy
+		}
+		upper := cmd &^ 0x20
+		nums, ok := s.numbers(pathArgCount[upper])
+		if !ok {
+			return p
+		}
+
+		switch upper {
+		case 'M':
+			cx, cy = nums[0]+ox, nums[1]+oy
+			startX, startY = cx, cy
+			p.moveTo(cx, cy)
+			// Further pairs after a moveto are implicit linetos.
+			cmd = 'L' | cmd&0x20
+		case 'L':
+			cx, cy = nums[0]+ox, nums[1]+oy
+			p.lineTo(cx, cy)
+		case 'H':
+			cx = nums[0] + ox
+			p.lineTo(cx, cy)
+		case 'V':
+			cy = nums[0] + oy
+			p.lineTo(cx, cy)
+		case 'C':
+			x1, y1 := nums[0]+ox, nums[1]+oy
+			ctrlX, ctrlY = nums[2]+ox, nums[3]+oy
+			cx, cy = nums[4]+ox, nums[5]+oy
+			p.cubeTo(x1, y1, ctrlX, ctrlY, cx, cy)
+		case 'S':
+			x1, y1 := cx, cy
+			if prev == 'C' || prev == 'S' {
+				x1, y1 = 2*cx-ctrlX, 2*cy-ctrlY
+			}
+			ctrlX, ctrlY = nums[0]+ox, nums[1]+oy
+			cx, cy = nums[2]+ox, nums[3]+oy
+			p.cubeTo(x1, y1, ctrlX, ctrlY, cx, cy)
+		case 'Q':
+			ctrlX, ctrlY = nums[0]+ox, nums[1]+oy
+			cx, cy = nums[2]+ox, nums[3]+oy
+			p.quadTo(ctrlX, ctrlY, cx, cy)
+		case 'T':
+			if prev == 'Q' || prev == 'T' {
+				ctrlX, ctrlY = 2*cx-ctrlX, 2*cy-ctrlY
+			} else {
+				ctrlX, ctrlY = cx, cy
+			}
+			cx, cy = nums[0]+ox, nums[1]+oy
+			p.quadTo(ctrlX, ctrlY, cx, cy)
+		case 'A':
+			x, y := nums[5]+ox, nums[6]+oy
+			p.arcTo(cx, cy, nums[0], nums[1], nums[2], nums[3] != 0, nums[4] != 0, x, y)
+			cx, cy = x, y
+		case 'Z':
+			p.close()
+			cx, cy = startX, startY
+		}
+		prev = upper
+	}
+}
+
+var pathArgCount = map[byte]int{
+	'M': 2, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7, 'Z': 0,
+}
+
+func isPathCommand(c byte) bool {
+	_, ok := pathArgCount[c&^0x20]
+	return ok && c >= 'A'
+}
+
+// numberScanner reads the comma-or-space separated numbers of path data, point lists
+// and transform arguments, including the compact forms such as "1.5.5" and "1-2".
+type numberScanner struct {
+	s string
+	i int
+}
+
+func (s *numberScanner) done() bool {
+	return s.i >= len(s.s)
+}
+
+func (s *numberScanner) skipSeparators() {
+	for s.i < len(s.s) && strings.IndexByte(" \t\r\n,", s.s[s.i]) >= 0 {
+		s.i++
+	}
+}
+
+func (s *numberScanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.i
+	if s.i < len(s.s) && (s.s[s.i] == '+' || s.s[s.i] == '-') {
+		s.i++
+	}
+	digits, dot := 0, false
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		if c >= '0' && c <= '9' {
+			digits++
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		s.i++
+	}
+	if digits == 0 {
+		s.i = start
+		return 0, false
+	}
+	if s.i < len(s.s) && (s.s[s.i] == 'e' || s.s[s.i] == 'E') {
+		j := s.i + 1
+		if j < len(s.s) && (s.s[j] == '+' || s.s[j] == '-') {
+			j++
+		}
+		if j < len(s.s) && s.s[j] >= '0' && s.s[j] <= '9' {
+			for j < len(s.s) && s.s[j] >= '0' && s.s[j] <= '9' {
+				j++
+			}
+			s.i = j
+		}
+	}
+	v, err := strconv.ParseFloat(s.s[start:s.i], 64)
+	return v, err == nil
+}
+
+// numbers reads n numbers, or nothing if fewer are left. Arc flags are single digits
+// that may run straight into the next number, so args three and four of an arc are
+// read one character each.
+func (s *numberScanner) numbers(n int) ([]float64, bool) {
+	nums := make([]float64, n)
+	for k := range nums {
+		if n == 7 && (k == 3 || k == 4) {
+			s.skipSeparators()
+			if s.done() || (s.s[s.i] != '0' && s.s[s.i] != '1') {
+				return nil, false
+			}
+			nums[k] = float64(s.s[s.i] - '0')
+			s.i++
+			continue
+		}
+		v, ok := s.number()
+		if !ok {
+			return nil, false
+		}
+		nums[k] = v
+	}
+	return nums, true
+}
+
+// parseNumberList reads every number in s, stopping at the first thing that is not one.
+func parseNumberList(s string) []float64 {
+	scan := numberScanner{s: s}
+	var nums []float64
+	for {
+		v, ok := scan.number()
+		if !ok {
+			return nums
+		}
+		nums = append(nums, v)
+	}
+}
+
+// rectPath outlines a rectangle, with corners rounded by rx and ry already resolved
+// against the rectangle's size.
+func rectPath(x, y, w, h, rx, ry float64) *svgPath {
+	p := &svgPath{}
+	if rx <= 0 || ry <= 0 {
+		p.moveTo(x, y)
+		p.lineTo(x+w, y)
+		p.lineTo(x+w, y+h)
+		p.lineTo(x, y+h)
+		p.close()
+		return p
+	}
+	rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+	p.moveTo(x+rx, y)
+	p.lineTo(x+w-rx, y)
+	p.arcTo(x+w-rx, y, rx, ry, 0, false, true, x+w, y+ry)
+	p.lineTo(x+w, y+h-ry)
+	p.arcTo(x+w, y+h-ry, rx, ry, 0, false, true, x+w-rx, y+h)
+	p.lineTo(x+rx, y+h)
+	p.arcTo(x+rx, y+h, rx, ry, 0, false, true, x, y+h-ry)
+	p.lineTo(x, y+ry)
+	p.arcTo(x, y+ry, rx, ry, 0, false, true, x+rx, y)
+	p.close()
+	return p
+}
+
+func ellipsePath(cx, cy, rx, ry float64) *svgPath {
+	p := &svgPath{}
+	p.moveTo(cx+rx, cy)
+	p.arcTo(cx+rx, cy, rx, ry, 0, false, true, cx-rx, cy)
+	p.arcTo(cx-rx, cy, rx, ry, 0, false, true, cx+rx, cy)
+	p.close()
+	return p
+}
+
+func polyPath(points []float64, closed bool) *svgPath {
+	p := &svgPath{}
+	if len(points) < 4 {
+		return p
+	}
+	p.moveTo(points[0], points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		p.lineTo(points[i], points[i+1])
+	}
+	if closed {
+		p.close()
+	}
+	return p
+}
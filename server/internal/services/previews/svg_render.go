@@ -0,0 +1,396 @@
+package previews
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// maxSVGUseDepth bounds how deeply <use> elements may reference one another, and
+	// maxSVGRenderSteps the elements drawn in total, so references that fan out cannot
+	// multiply a small document into an endless render. maxSVGOverdraw bounds the
+	// pixels painted, as a multiple of the canvas, for documents of many large shapes.
+	maxSVGUseDepth    = 8
+	maxSVGRenderSteps = 20000
+	maxSVGOverdraw    = 64
+)
+
+var errSVGTooComplex = errors.New("svg is too complex to render")
+
+// svgRenderer draws a parsed document in document order, so shapes, text and images
+// overlap as they do in a browser.
+type svgRenderer struct {
+	doc     *svgDocument
+	img     *image.RGBA
+	scanner *svgScanner
+	dasher  *rasterx.Dasher
+	images  map[*svgNode]image.Image
+	steps   int
+	uses    int
+	err     error
+}
+
+// svgContext is what an element inherits from its ancestors.
+type svgContext struct {
+	ctm   rasterx.Matrix2D
+	style svgStyle
+	vp    svgViewport
+}
+
+// renderSVG draws doc onto a w by h canvas, with ctm taking the root's user space to
+// pixels.
+func renderSVG(doc *svgDocument, w, h int, ctm rasterx.Matrix2D, vp svgViewport) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := &svgScanner{dst: img, budget: maxSVGOverdraw * w * h}
+	r := &svgRenderer{
+		doc:     doc,
+		img:     img,
+		scanner: scanner,
+		dasher:  rasterx.NewDasher(w, h, scanner),
+	}
+	ctx := svgContext{ctm: ctm, style: defaultSVGStyle(), vp: vp}
+	ctx.style = computeStyle(ctx.style, doc.properties(doc.root), vp)
+	r.renderChildren(doc.root, ctx)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return img, nil
+}
+
+func (r *svgRenderer) renderChildren(node *svgNode, ctx svgContext) {
+	for _, child := range node.children {
+		if r.err != nil {
+			return
+		}
+		r.render(child, ctx)
+	}
+}
+
+func (r *svgRenderer) render(node *svgNode, parent svgContext) {
+	if node.name == "" {
+		return
+	}
+	if r.steps++; r.steps > maxSVGRenderSteps || r.scanner.budget < 0 {
+		r.err = errSVGTooComplex
+		return
+	}
+	switch node.name {
+	case "svg", "g", "a", "switch", "use", "path", "rect", "circle", "ellipse",
+		"line", "polyline", "polygon", "text", "image":
+	default:
+		// Definitions are drawn only through what references them; filters, masks,
+		// clip paths, patterns, markers and foreign content are not supported.
+		return
+	}
+
+	props := r.doc.properties(node)
+	if props["display"] == "none" {
+		return
+	}
+	ctx := parent
+	ctx.style = computeStyle(parent.style, props, parent.vp)
+	if v, ok := node.attrs["transform"]; ok {
+		if m, ok := parseSVGTransform(v); ok {
+			ctx.ctm = ctx.ctm.Mult(m)
+		}
+	}
+
+	switch node.name {
+	case "g", "a":
+		r.renderChildren(node, ctx)
+	case "switch":
+		// Conditional attributes are not evaluated, so the first candidate wins.
+		for _, child := range node.children {
+			if child.name != "" {
+				r.render(child, ctx)
+				return
+			}
+		}
+	case "svg":
+		r.renderViewport(node, ctx, nil)
+	case "use":
+		r.renderUse(node, ctx)
+	case "text":
+		r.drawText(node, ctx)
+	case "image":
+		r.drawImage(node, ctx)
+	default:
+		if p := r.shapePath(node, ctx); p != nil && !p.empty() {
+			r.drawPath(p, &ctx.style, ctx.ctm, pathBox(p), ctx.vp)
+		}
+	}
+}
+
+// renderViewport draws the children of a nested <svg> or <symbol>. A <use> placing it
+// is passed as override, whose width and height win over the target's own. Content is
+// not clipped to the viewport.
+func (r *svgRenderer) renderViewport(node *svgNode, ctx svgContext, override *svgNode) {
+	x := attrLength(node, "x", ctx.vp.w, ctx.style.fontSize, 0)
+	y := attrLength(node, "y", ctx.vp.h, ctx.style.fontSize, 0)
+	w := attrLength(node, "width", ctx.vp.w, ctx.style.fontSize, ctx.vp.w)
+	h := attrLength(node, "height", ctx.vp.h, ctx.style.fontSize, ctx.vp.h)
+	if override != nil {
+		w = attrLength(override, "width", ctx.vp.w, ctx.style.fontSize, w)
+		h = attrLength(override, "height", ctx.vp.h, ctx.style.fontSize, h)
+	}
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	ctx.ctm = ctx.ctm.Translate(x, y)
+	ctx.vp = svgViewport{w: w, h: h}
+	if vb, ok := parseViewBox(node.attrs["viewBox"]); ok {
+		ctx.ctm = ctx.ctm.Mult(viewBoxTransform(vb, w, h, node.attrs["preserveAspectRatio"]))
+		ctx.vp = svgViewport{w: vb.w, h: vb.h}
+	}
+	r.renderChildren(node, ctx)
+}
+
+// renderUse draws the element a <use> references, which parsing has already limited
+// to fragments of this document.
+func (r *svgRenderer) renderUse(node *svgNode, ctx svgContext) {
+	target := r.doc.ids[strings.TrimPrefix(strings.TrimSpace(node.attrs["href"]), "#")]
+	if target == nil {
+		return
+	}
+	if r.uses >= maxSVGUseDepth {
+		r.err = errSVGTooComplex
+		return
+	}
+	r.uses++
+	defer func() { r.uses-- }()
+
+	x := attrLength(node, "x", ctx.vp.w, ctx.style.fontSize, 0)
+	y := attrLength(node, "y", ctx.vp.h, ctx.style.fontSize, 0)
+	ctx.ctm = ctx.ctm.Translate(x, y)
+
+	switch target.name {
+	case "symbol", "svg":
+		props := r.doc.properties(target)
+		if props["display"] == "none" {
+			return
+		}
+		ctx.style = computeStyle(ctx.style, props, ctx.vp)
+		r.renderViewport(target, ctx, node)
+	default:
+		r.render(target, ctx)
+	}
+}
+
+func (r *svgRenderer) shapePath(node *svgNode, ctx svgContext) *svgPath {
+	vp, fs := ctx.vp, ctx.style.fontSize
+	switch node.name {
+	case "path":
+		return parsePathData(node.attrs["d"])
+	case "rect":
+		w := attrLength(node, "width", vp.w, fs, 0)
+		h := attrLength(node, "height", vp.h, fs, 0)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx := attrLength(node, "rx", vp.w, fs, -1)
+		ry := attrLength(node, "ry", vp.h, fs, -1)
+		if rx < 0 {
+			rx = ry
+		}
+		if ry < 0 {
+			ry = rx
+		}
+		return rectPath(attrLength(node, "x", vp.w, fs, 0), attrLength(node, "y", vp.h, fs, 0), w, h, rx, ry)
+	case "circle":
+		radius := attrLength(node, "r", vp.diagonal(), fs, 0)
+		if radius <= 0 {
+			return nil
+		}
+		return ellipsePath(attrLength(node, "cx", vp.w, fs, 0), attrLength(node, "cy", vp.h, fs, 0), radius, radius)
+	case "ellipse":
+		rx := attrLength(node, "rx", vp.w, fs, 0)
+		ry := attrLength(node, "ry", vp.h, fs, 0)
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		return ellipsePath(attrLength(node, "cx", vp.w, fs, 0), attrLength(node, "cy", vp.h, fs, 0), rx, ry)
+	case "line":
+		p := &svgPath{}
+		p.moveTo(attrLength(node, "x1", vp.w, fs, 0), attrLength(node, "y1", vp.h, fs, 0))
+		p.lineTo(attrLength(node, "x2", vp.w, fs, 0), attrLength(node, "y2", vp.h, fs, 0))
+		return p
+	case "polyline":
+		return polyPath(parseNumberList(node.attrs["points"]), false)
+	case "polygon":
+		return polyPath(parseNumberList(node.attrs["points"]), true)
+	}
+	return nil
+}
+
+func pathBox(p *svgPath) svgBox {
+	minX, minY, maxX, maxY := p.bounds()
+	return svgBox{x: minX, y: minY, w: maxX - minX, h: maxY - minY}
+}
+
+// drawPath fills then strokes p. The rasterizer only implements the nonzero rule, so
+// evenodd fills are drawn as nonzero.
+func (r *svgRenderer) drawPath(p *svgPath, st *svgStyle, ctm rasterx.Matrix2D, bbox svgBox, vp svgViewport) {
+	if st.hidden {
+		return
+	}
+
+	if src := r.doc.paintSource(st.fill, st, st.fillOpacity*st.opacity, ctm, bbox, vp); src != nil {
+		r.dasher.Clear()
+		filler := &r.dasher.Filler
+		p.addTo(filler, ctm)
+		filler.SetColor(src)
+		filler.Draw()
+	}
+
+	// Stroke geometry is laid out in device space, so widths and dashes are scaled by
+	// the transform's average scale.
+	scale := math.Sqrt(math.Abs(ctm.A*ctm.D - ctm.B*ctm.C))
+	width := st.strokeWidth * scale
+	if width <= 0 {
+		return
+	}
+	src := r.doc.paintSource(st.stroke, st, st.strokeOpacity*st.opacity, ctm, bbox, vp)
+	if src == nil {
+		return
+	}
+	var dashes []float64
+	for _, d := range st.dashes {
+		dashes = append(dashes, d*scale)
+	}
+	capFn, join, gap := strokeStyle(st)
+	r.dasher.Clear()
+	r.dasher.SetStroke(fixed.Int26_6(width*64), fixed.Int26_6(st.miterLimit*64), capFn, capFn, gap, join, dashes, st.dashOffset*scale)
+	p.addTo(r.dasher, ctm)
+	r.dasher.SetColor(src)
+	r.dasher.Draw()
+}
+
+func strokeStyle(st *svgStyle) (rasterx.CapFunc, rasterx.JoinMode, rasterx.GapFunc) {
+	capFn := rasterx.ButtCap
+	switch st.lineCap {
+	case "round":
+		capFn = rasterx.RoundCap
+	case "square":
+		capFn = rasterx.SquareCap
+	}
+	join, gap := rasterx.Miter, rasterx.FlatGap
+	switch st.lineJoin {
+	case "round":
+		join, gap = rasterx.Round, rasterx.RoundGap
+	case "bevel":
+		join = rasterx.Bevel
+	case "miter-clip":
+		join = rasterx.MiterClip
+	case "arcs":
+		join = rasterx.Arc
+	}
+	return capFn, join, gap
+}
+
+// svgScanner is a rasterx.Scanner that rasterizes each path over its own bounds only.
+// rasterx's own scanner clears and composites the whole canvas for every path, which
+// makes a document of many small shapes far slower than its content warrants.
+type svgScanner struct {
+	dst    *image.RGBA
+	r      vector.Rasterizer
+	points []fixed.Point26_6
+	starts []bool
+	min    fixed.Point26_6
+	max    fixed.Point26_6
+	src    image.Image
+	budget int
+}
+
+func (s *svgScanner) Start(a fixed.Point26_6) {
+	s.add(a, true)
+}
+
+func (s *svgScanner) Line(b fixed.Point26_6) {
+	s.add(b, false)
+}
+
+func (s *svgScanner) add(p fixed.Point26_6, start bool) {
+	if len(s.points) == 0 {
+		s.min, s.max = p, p
+	}
+	s.min.X, s.min.Y = min(s.min.X, p.X), min(s.min.Y, p.Y)
+	s.max.X, s.max.Y = max(s.max.X, p.X), max(s.max.Y, p.Y)
+	s.points = append(s.points, p)
+	s.starts = append(s.starts, start)
+}
+
+func (s *svgScanner) Draw() {
+	if len(s.points) == 0 || s.src == nil {
+		return
+	}
+	rect := image.Rect(s.min.X.Floor(), s.min.Y.Floor(), s.max.X.Ceil()+1, s.max.Y.Ceil()+1).Intersect(s.dst.Bounds())
+	if rect.Empty() || !s.spend(rect) {
+		return
+	}
+	s.r.Reset(rect.Dx(), rect.Dy())
+	s.r.DrawOp = draw.Over
+	ox, oy := float32(rect.Min.X), float32(rect.Min.Y)
+	for i, p := range s.points {
+		x, y := float32(p.X)/64-ox, float32(p.Y)/64-oy
+		if s.starts[i] {
+			s.r.MoveTo(x, y)
+		} else {
+			s.r.LineTo(x, y)
+		}
+	}
+	s.r.Draw(s.dst, rect, s.src, rect.Min)
+}
+
+// spend takes rect's area from the pixel budget, reporting whether any was left.
+func (s *svgScanner) spend(rect image.Rectangle) bool {
+	s.budget -= rect.Dx() * rect.Dy()
+	return s.budget >= 0
+}
+
+func (s *svgScanner) GetPathExtent() fixed.Rectangle26_6 {
+	return fixed.Rectangle26_6{Min: s.min, Max: s.max}
+}
+
+func (s *svgScanner) SetBounds(int, int) {}
+
+func (s *svgScanner) SetColor(c interface{}) {
+	switch c := c.(type) {
+	case color.Color:
+		s.src = image.NewUniform(c)
+	case rasterx.ColorFunc:
+		s.src = colorFuncImage(c)
+	}
+}
+
+// SetWinding is a no-op: the vector rasterizer fills by the nonzero rule only.
+func (s *svgScanner) SetWinding(bool) {}
+
+func (s *svgScanner) Clear() {
+	s.points, s.starts = s.points[:0], s.starts[:0]
+}
+
+func (s *svgScanner) SetClip(image.Rectangle) {}
+
+// colorFuncImage presents a gradient's color function as an unbounded image.
+type colorFuncImage rasterx.ColorFunc
+
+func (f colorFuncImage) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (f colorFuncImage) Bounds() image.Rectangle {
+	return image.Rect(math.MinInt32, math.MinInt32, math.MaxInt32, math.MaxInt32)
+}
+
+func (f colorFuncImage) At(x, y int) color.Color {
+	return f(x, y)
+}
@@ -0,0 +1,488 @@
+package previews
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/colornames"
+)
+
+// svgPresentation lists the properties the renderer reads. Presentation attributes of
+// these names feed the cascade below stylesheet rules and the style attribute.
+var svgPresentation = []string{
+	"fill", "fill-opacity", "stroke", "stroke-width", "stroke-opacity",
+	"stroke-linecap", "stroke-linejoin", "stroke-miterlimit", "stroke-dasharray",
+	"stroke-dashoffset", "opacity", "color", "font-family", "font-size", "font-weight",
+	"font-style", "text-anchor", "display", "visibility", "stop-color", "stop-opacity",
+}
+
+// properties resolves the declared properties of node: presentation attributes, then
+// matching stylesheet rules in order of specificity, then the style attribute.
+func (doc *svgDocument) properties(node *svgNode) map[string]string {
+	props := map[string]string{}
+	for _, name := range svgPresentation {
+		if v, ok := node.attrs[name]; ok {
+			props[name] = strings.TrimSpace(v)
+		}
+	}
+	for i := range doc.css {
+		if doc.css[i].matches(node) {
+			for name, v := range doc.css[i].decls {
+				props[name] = v
+			}
+		}
+	}
+	if style, ok := node.attrs["style"]; ok {
+		for name, v := range parseSVGDeclarations(style) {
+			props[name] = v
+		}
+	}
+	return props
+}
+
+// svgStyle is the computed style of an element. Opacity is folded into each shape as
+// it is painted rather than composited per group, which is exact unless the group's
+// children overlap.
+type svgStyle struct {
+	fill, stroke  svgPaint
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+	strokeWidth   float64
+	lineCap       string
+	lineJoin      string
+	miterLimit    float64
+	dashes        []float64
+	dashOffset    float64
+	color         color.NRGBA
+	fontFamily    string
+	fontSize      float64
+	bold, italic  bool
+	textAnchor    string
+	hidden        bool
+}
+
+func defaultSVGStyle() svgStyle {
+	black := color.NRGBA{A: 0xFF}
+	return svgStyle{
+		fill:          svgPaint{kind: paintColor, color: black},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+		strokeWidth:   1,
+		lineCap:       "butt",
+		lineJoin:      "miter",
+		miterLimit:    4,
+		color:         black,
+		fontFamily:    "sans-serif",
+		fontSize:      16,
+		textAnchor:    "start",
+	}
+}
+
+// computeStyle applies props over the style inherited from the parent. Values that do
+// not parse are ignored, leaving the inherited value, as a browser would.
+func computeStyle(parent svgStyle, props map[string]string, vp svgViewport) svgStyle {
+	s := parent
+	// The font size comes first since em lengths elsewhere are relative to it.
+	if v, ok := props["font-size"]; ok && v != "inherit" {
+		if size, ok := parseFontSize(v, parent.fontSize); ok {
+			s.fontSize = size
+		}
+	}
+
+	for name, v := range props {
+		if v == "inherit" {
+			continue
+		}
+		switch name {
+		case "fill":
+			if p, ok := parseSVGPaint(v); ok {
+				s.fill = p
+			}
+		case "stroke":
+			if p, ok := parseSVGPaint(v); ok {
+				s.stroke = p
+			}
+		case "color":
+			if c, ok := parseSVGColor(v); ok {
+				s.color = c
+			}
+		case "fill-opacity":
+			s.fillOpacity = parseOpacity(v, s.fillOpacity)
+		case "stroke-opacity":
+			s.strokeOpacity = parseOpacity(v, s.strokeOpacity)
+		case "opacity":
+			s.opacity = parent.opacity * parseOpacity(v, 1)
+		case "stroke-width":
+			if w, ok := parseSVGLength(v, vp.diagonal(), s.fontSize); ok && w >= 0 {
+				s.strokeWidth = w
+			}
+		case "stroke-linecap":
+			s.lineCap = v
+		case "stroke-linejoin":
+			s.lineJoin = v
+		case "stroke-miterlimit":
+			if m, err := strconv.ParseFloat(v, 64); err == nil && m >= 1 {
+				s.miterLimit = m
+			}
+		case "stroke-dasharray":
+			s.dashes = parseDashArray(v, vp, s.fontSize)
+		case "stroke-dashoffset":
+			if o, ok := parseSVGLength(v, vp.diagonal(), s.fontSize); ok {
+				s.dashOffset = o
+			}
+		case "font-family":
+			s.fontFamily = v
+		case "font-weight":
+			s.bold = parseFontWeight(v, parent.bold)
+		case "font-style":
+			s.italic = v == "italic" || v == "oblique"
+		case "text-anchor":
+			if v == "start" || v == "middle" || v == "end" {
+				s.textAnchor = v
+			}
+		case "visibility":
+			s.hidden = v == "hidden" || v == "collapse"
+		}
+	}
+	return s
+}
+
+func parseOpacity(v string, fallback float64) float64 {
+	v = strings.TrimSpace(v)
+	scale := 1.0
+	if strings.HasSuffix(v, "%") {
+		v, scale = strings.TrimSuffix(v, "%"), 0.01
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return math.Max(0, math.Min(1, f*scale))
+}
+
+func parseFontWeight(v string, parentBold bool) bool {
+	switch v {
+	case "bold", "bolder":
+		return true
+	case "normal", "lighter":
+		return false
+	}
+	if w, err := strconv.Atoi(v); err == nil {
+		return w >= 600
+	}
+	return parentBold
+}
+
+func parseFontSize(v string, parentSize float64) (float64, bool) {
+	switch v {
+	case "xx-small":
+		return 9, true
+	case "x-small":
+		return 10, true
+	case "small":
+		return 13, true
+	case "medium":
+		return 16, true
+	case "large":
+		return 18, true
+	case "x-large":
+		return 24, true
+	case "xx-large":
+		return 32, true
+	case "smaller":
+		return parentSize / 1.2, true
+	case "larger":
+		return parentSize * 1.2, true
+	}
+	size, ok := parseSVGLength(v, parentSize, parentSize)
+	return size, ok && size >= 0
+}
+
+func parseDashArray(v string, vp svgViewport, fontSize float64) []float64 {
+	if v == "none" {
+		return nil
+	}
+	var dashes []float64
+	total := 0.0
+	for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+		d, ok := parseSVGLength(part, vp.diagonal(), fontSize)
+		if !ok || d < 0 {
+			return nil
+		}
+		dashes = append(dashes, d)
+		total += d
+	}
+	if total == 0 {
+		return nil
+	}
+	if len(dashes)%2 == 1 {
+		dashes = append(dashes, dashes...)
+	}
+	return dashes
+}
+
+// svgViewport is the size percentages resolve against: the nearest viewBox, or the
+// viewport itself when there is none.
+type svgViewport struct {
+	w, h float64
+}
+
+// diagonal is the reference for percentages that are neither horizontal nor vertical.
+func (vp svgViewport) diagonal() float64 {
+	return math.Sqrt((vp.w*vp.w + vp.h*vp.h) / 2)
+}
+
+// parseSVGLength reads a length in user units. Percentages resolve against ref and em
+// units against fontSize.
+func parseSVGLength(v string, ref, fontSize float64) (float64, bool) {
+	v = strings.TrimSpace(v)
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"%", ref / 100}, {"px", 1}, {"pt", 4.0 / 3}, {"pc", 16}, {"mm", 96 / 25.4},
+		{"cm", 96 / 2.54}, {"in", 96}, {"em", fontSize}, {"ex", fontSize / 2},
+	}
+	scale := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(v, u.suffix) {
+			v, scale = strings.TrimSuffix(v, u.suffix), u.scale
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f * scale, true
+}
+
+// attrLength reads a length attribute, falling back to def when it is missing or
+// malformed.
+func attrLength(node *svgNode, name string, ref, fontSize, def float64) float64 {
+	v, ok := node.attrs[name]
+	if !ok {
+		return def
+	}
+	f, ok := parseSVGLength(v, ref, fontSize)
+	if !ok {
+		return def
+	}
+	return f
+}
+
+// parseSVGTransform reads a transform list. A malformed list is ignored as a whole,
+// as browsers do.
+func parseSVGTransform(v string) (rasterx.Matrix2D, bool) {
+	m := rasterx.Identity
+	rest := strings.TrimSpace(v)
+	for rest != "" {
+		open := strings.IndexByte(rest, '(')
+		end := strings.IndexByte(rest, ')')
+		if open < 0 || end < open {
+			return rasterx.Identity, false
+		}
+		name := strings.TrimSpace(rest[:open])
+		args := parseNumberList(rest[open+1 : end])
+		rest = strings.TrimLeft(rest[end+1:], " \t\r\n,")
+
+		deg := func(i int) float64 { return args[i] * math.Pi / 180 }
+		switch {
+		case name == "matrix" && len(args) == 6:
+			m = m.Mult(rasterx.Matrix2D{A: args[0], B: args[1], C: args[2], D: args[3], E: args[4], F: args[5]})
+		case name == "translate" && len(args) == 1:
+			m = m.Translate(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			m = m.Translate(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			m = m.Scale(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			m = m.Scale(args[0], args[1])
+		case name == "rotate" && len(args) == 1:
+			m = m.Rotate(deg(0))
+		case name == "rotate" && len(args) == 3:
+			m = m.Translate(args[1], args[2]).Rotate(deg(0)).Translate(-args[1], -args[2])
+		case name == "skewX" && len(args) == 1:
+			m = m.SkewX(deg(0))
+		case name == "skewY" && len(args) == 1:
+			m = m.SkewY(deg(0))
+		default:
+			return rasterx.Identity, false
+		}
+	}
+	return m, true
+}
+
+// svgBox is a rectangle in user space: a viewBox, or a shape's bounding box.
+type svgBox struct {
+	x, y, w, h float64
+}
+
+func parseViewBox(v string) (svgBox, bool) {
+	nums := parseNumberList(v)
+	if len(nums) != 4 || nums[2] <= 0 || nums[3] <= 0 {
+		return svgBox{}, false
+	}
+	return svgBox{x: nums[0], y: nums[1], w: nums[2], h: nums[3]}, true
+}
+
+// viewBoxTransform maps vb onto a viewport of w by h at the origin, honoring
+// preserveAspectRatio's alignment and meet or slice.
+func viewBoxTransform(vb svgBox, w, h float64, preserve string) rasterx.Matrix2D {
+	sx, sy := w/vb.w, h/vb.h
+	fields := strings.Fields(preserve)
+	align := "xMidYMid"
+	if len(fields) > 0 && fields[0] == "defer" {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align == "none" {
+		return rasterx.Identity.Scale(sx, sy).Translate(-vb.x, -vb.y)
+	}
+
+	s := math.Min(sx, sy)
+	if len(fields) > 1 && fields[1] == "slice" {
+		s = math.Max(sx, sy)
+	}
+	tx, ty := -vb.x*s, -vb.y*s
+	switch {
+	case strings.Contains(align, "xMid"):
+		tx += (w - vb.w*s) / 2
+	case strings.Contains(align, "xMax"):
+		tx += w - vb.w*s
+	}
+	switch {
+	case strings.Contains(align, "YMid"):
+		ty += (h - vb.h*s) / 2
+	case strings.Contains(align, "YMax"):
+		ty += h - vb.h*s
+	}
+	return rasterx.Matrix2D{A: s, D: s, E: tx, F: ty}
+}
+
+// parseSVGColor reads a CSS color: a keyword, #rgb[a], #rrggbb[aa], rgb[a]() or hsl[a]().
+func parseSVGColor(v string) (color.NRGBA, bool) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "transparent" {
+		return color.NRGBA{}, true
+	}
+	if c, ok := colornames.Map[v]; ok {
+		return color.NRGBA{R: c.R, G: c.G, B: c.B, A: 0xFF}, true
+	}
+	if strings.HasPrefix(v, "#") {
+		return parseHexColor(v[1:])
+	}
+
+	open := strings.IndexByte(v, '(')
+	if open < 0 || !strings.HasSuffix(v, ")") {
+		return color.NRGBA{}, false
+	}
+	fn := v[:open]
+	args := strings.FieldsFunc(v[open+1:len(v)-1], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/'
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return color.NRGBA{}, false
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		alpha = parseOpacity(args[3], 1)
+	}
+
+	switch fn {
+	case "rgb", "rgba":
+		var rgb [3]uint8
+		for i := range rgb {
+			ch, ok := parseColorChannel(args[i])
+			if !ok {
+				return color.NRGBA{}, false
+			}
+			rgb[i] = ch
+		}
+		return color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: uint8(math.Round(alpha * 255))}, true
+	case "hsl", "hsla":
+		h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+		s, okS := parsePercent(args[1])
+		l, okL := parsePercent(args[2])
+		if err != nil || !okS || !okL {
+			return color.NRGBA{}, false
+		}
+		c := hslToRGB(h, s, l)
+		c.A = uint8(math.Round(alpha * 255))
+		return c, true
+	}
+	return color.NRGBA{}, false
+}
+
+func parseHexColor(h string) (color.NRGBA, bool) {
+	if len(h) == 3 || len(h) == 4 {
+		long := make([]byte, 0, 8)
+		for i := 0; i < len(h); i++ {
+			long = append(long, h[i], h[i])
+		}
+		h = string(long)
+	}
+	if len(h) != 6 && len(h) != 8 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	if len(h) == 6 {
+		v = v<<8 | 0xFF
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+func parseColorChannel(v string) (uint8, bool) {
+	if p, ok := parsePercent(v); ok {
+		return uint8(math.Round(math.Max(0, math.Min(1, p)) * 255)), true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint8(math.Round(math.Max(0, math.Min(255, f)))), true
+}
+
+func parsePercent(v string) (float64, bool) {
+	if !strings.HasSuffix(v, "%") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	return f / 100, err == nil
+}
+
+func hslToRGB(h, s, l float64) color.NRGBA {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	s, l = math.Max(0, math.Min(1, s)), math.Max(0, math.Min(1, l))
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 0.5:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return color.NRGBA{R: channel(h + 1.0/3), G: channel(h), B: channel(h - 1.0/3), A: 0xFF}
+}
@@ -0,0 +1,216 @@
+package previews
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+var (
+	svgBlue  = color.NRGBA{B: 0xFF, A: 0xFF}
+	svgGreen = color.NRGBA{G: 0x80, A: 0xFF}
+)
+
+func TestRasterizeSVGAppliesNestedTransforms(t *testing.T) {
+	// scale with one argument scales both axes, and group transforms compose.
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">
+		<g transform="translate(50 0)"><g transform="scale(2)"><rect width="10" height="10" fill="#ff0000"/></g></g>
+		<rect x="0" y="50" width="10" height="10" fill="#0000ff" transform="skewX(0) matrix(1 0 0 1 20 20)"/>
+	</svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+	// One user unit is 12 pixels.
+	assertPixel(t, img, 12*65, 12*15, sourceRed)
+	assertPixel(t, img, 12*45, 12*15, color.NRGBA{})
+	assertPixel(t, img, 12*25, 12*75, svgBlue)
+}
+
+func TestRasterizeSVGDrawsUseAndSymbol(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 100 100">
+		<defs><rect id="dot" width="10" height="10" fill="#0000ff"/></defs>
+		<symbol id="sq" viewBox="0 0 1 1"><rect width="1" height="1" fill="#ff0000"/></symbol>
+		<use xlink:href="#dot" x="70" y="70"/>
+		<use href="#sq" x="10" y="10" width="20" height="20"/>
+	</svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+	assertPixel(t, img, 12*75, 12*75, svgBlue)
+	assertPixel(t, img, 12*25, 12*25, sourceRed)
+	assertPixel(t, img, 12*5, 12*5, color.NRGBA{})
+}
+
+func TestRasterizeSVGStylesTspansSeparately(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 50">
+		<style>.accent { fill: #0000ff }</style>
+		<text x="5" y="40" font-size="40" font-weight="bold" fill="#ff0000">MM<tspan class="accent" dx="10">MM</tspan></text>
+	</svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+
+	b := img.Bounds()
+	var redMax, blueMin = -1, b.Max.X
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, bl, a := img.At(x, y).RGBA()
+			if a>>8 < 0xF0 {
+				continue
+			}
+			if r>>8 > 0xF0 && bl>>8 < 0x10 {
+				redMax = max(redMax, x)
+			}
+			if bl>>8 > 0xF0 && r>>8 < 0x10 {
+				blueMin = min(blueMin, x)
+			}
+		}
+	}
+	if redMax < 0 || blueMin == b.Max.X {
+		t.Fatalf("expected both text colors, got red up to %d and blue from %d", redMax, blueMin)
+	}
+	if blueMin <= redMax {
+		t.Fatalf("expected the tspan to follow the text, got red up to %d and blue from %d", redMax, blueMin)
+	}
+}
+
+func TestRasterizeSVGAnchorsTextChunks(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 50"><text x="100" y="40" font-size="30" text-anchor="end" fill="#ff0000">MM</text></svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+	lit := func(x0, x1 int) bool {
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := x0; x < x1; x++ {
+				if _, _, _, a := img.At(x, y).RGBA(); a>>8 > 0x80 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if lit(0, 600) || !lit(600, 1200) {
+		t.Fatal("expected end-anchored text to sit against the right edge")
+	}
+}
+
+func TestRasterizeSVGDrawsDataURIImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, solidImage(4, 2, svgGreen)); err != nil {
+		t.Fatal(err)
+	}
+	uri := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">` +
+		`<rect width="100" height="100" fill="#0000ff"/>` +
+		`<image x="0" y="0" width="100" height="100" href="` + uri + `"/>` +
+		`</svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+	// The 2:1 image meets the square viewport centered, leaving bands above and below.
+	assertPixel(t, img, 600, 600, svgGreen)
+	assertPixel(t, img, 600, 100, svgBlue)
+	assertPixel(t, img, 600, 1100, svgBlue)
+}
+
+func TestRasterizeSVGPaintsGradients(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 50">
+		<linearGradient id="base"><stop offset="0" stop-color="#ff0000"/><stop offset="1" stop-color="#0000ff"/></linearGradient>
+		<linearGradient id="g" href="#base" x1="0.2" x2="0.8"/>
+		<rect width="100" height="50" fill="url(#g) #00ff00"/>
+	</svg>`
+	img, err := rasterizeSVG([]byte(svg))
+	if err != nil {
+		t.Fatalf("rasterizeSVG: %v", err)
+	}
+	assertPixel(t, img, 60, 300, sourceRed)
+	assertPixel(t, img, 1140, 300, svgBlue)
+	assertPixelNear(t, img, 600, 300, color.NRGBA{R: 0x80, B: 0x80, A: 0xFF}, 4)
+}
+
+func TestRasterizeSVGRefusesExternalReferences(t *testing.T) {
+	cases := map[string]string{
+		"image href":        `<image href="http://169.254.169.254/latest" width="10" height="10"/>`,
+		"image xlink":       `<image xmlns:xlink="http://www.w3.org/1999/xlink" xlink:href="file:///etc/passwd"/>`,
+		"use other file":    `<use href="other.svg#shape"/>`,
+		"paint url":         `<rect width="10" height="10" fill="url(https://example.com/p.svg#g)"/>`,
+		"style attr url":    `<rect width="10" height="10" style="fill: url('//example.com/g')"/>`,
+		"stylesheet url":    `<style>rect { fill: url(http://example.com/g) }</style>`,
+		"stylesheet import": `<style>@import "https://example.com/s.css";</style>`,
+		"svg data image":    `<image href="data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=" width="10" height="10"/>`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">` + body + `</svg>`
+			_, err := rasterizeSVG([]byte(svg))
+			if name == "svg data image" {
+				// Not fetched, just not drawn.
+				if err != nil {
+					t.Fatalf("expected a nested svg image to be skipped, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errSVGExternalRef) {
+				t.Fatalf("expected an external reference error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRasterizeSVGDropsWebFonts(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 50">
+		<style>@font-face { font-family: Inter; src: url(https://example.com/inter.woff2) } text { font-family: Inter, monospace }</style>
+		<text x="5" y="30" font-size="20">hi</text>
+	</svg>`
+	if _, err := rasterizeSVG([]byte(svg)); err != nil {
+		t.Fatalf("expected text to fall back to the embedded fonts, got %v", err)
+	}
+}
+
+func TestRasterizeSVGBoundsUseExpansion(t *testing.T) {
+	// Each level references the one below ten times over.
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><defs><rect id="l0" width="1" height="1"/>`
+	for i := 1; i <= 7; i++ {
+		svg += `<g id="l` + string(rune('0'+i)) + `">`
+		for j := 0; j < 10; j++ {
+			svg += `<use href="#l` + string(rune('0'+i-1)) + `"/>`
+		}
+		svg += `</g>`
+	}
+	svg += `</defs><use href="#l7"/></svg>`
+	if _, err := rasterizeSVG([]byte(svg)); !errors.Is(err, errSVGTooComplex) {
+		t.Fatalf("expected the expansion to be refused, got %v", err)
+	}
+}
+
+func TestParsePathData(t *testing.T) {
+	p := parsePathData("M10-20l5.5.5h1v1c0 1 1 1 1 0s1-1 1 0a5 5 0 0 1 10 0zM0 0 1 1")
+	want := []svgPathOp{pathMoveTo, pathLineTo, pathLineTo, pathLineTo, pathCubeTo, pathCubeTo}
+	if len(p.ops) < len(want) {
+		t.Fatalf("expected at least %d ops, got %v", len(want), p.ops)
+	}
+	for i, op := range want {
+		if p.ops[i] != op {
+			t.Fatalf("op %d = %v, want %v (all: %v)", i, p.ops[i], op, p.ops)
+		}
+	}
+	if p.pts[0] != 10 || p.pts[1] != -20 || p.pts[2] != 15.5 || p.pts[3] != -19.5 {
+		t.Fatalf("unexpected leading points %v", p.pts[:4])
+	}
+	if last := p.ops[len(p.ops)-1]; last != pathLineTo {
+		t.Fatalf("expected pairs after a moveto to be linetos, got %v", last)
+	}
+
+	truncated := parsePathData("M0 0 L10 10 L20")
+	if len(truncated.ops) != 2 {
+		t.Fatalf("expected the path up to the error, got %v", truncated.ops)
+	}
+}
@@ -0,0 +1,310 @@
+package previews
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// svgFontKey picks one of the embedded Go fonts. Documents cannot bring their own, so
+// every family falls back to Go or Go Mono in the requested weight and slant.
+type svgFontKey struct {
+	mono, bold, italic bool
+}
+
+var (
+	svgFontOnce sync.Once
+	svgFontErr  error
+	svgFonts    map[svgFontKey]*sfnt.Font
+)
+
+func loadSVGFonts() error {
+	svgFontOnce.Do(func() {
+		sources := map[svgFontKey][]byte{
+			{}:                                     goregular.TTF,
+			{bold: true}:                           gobold.TTF,
+			{italic: true}:                         goitalic.TTF,
+			{bold: true, italic: true}:             gobolditalic.TTF,
+			{mono: true}:                           gomono.TTF,
+			{mono: true, bold: true}:               gomonobold.TTF,
+			{mono: true, italic: true}:             gomonoitalic.TTF,
+			{mono: true, bold: true, italic: true}: gomonobolditalic.TTF,
+		}
+		svgFonts = make(map[svgFontKey]*sfnt.Font, len(sources))
+		for key, data := range sources {
+			f, err := sfnt.Parse(data)
+			if err != nil {
+				svgFontErr = err
+				return
+			}
+			svgFonts[key] = f
+		}
+	})
+	return svgFontErr
+}
+
+// isMonospaceFamily decides a font-family list. None of the named families are
+// available, so the first generic family decides, with names that say they are
+// monospaced standing in for monospace.
+func isMonospaceFamily(families string) bool {
+	for _, family := range strings.Split(families, ",") {
+		name := strings.ToLower(strings.Trim(strings.TrimSpace(family), `"'`))
+		switch name {
+		case "monospace", "ui-monospace":
+			return true
+		case "serif", "sans-serif", "cursive", "fantasy", "system-ui", "ui-sans-serif", "ui-serif":
+			return false
+		}
+		for _, hint := range []string{"mono", "courier", "consol", "menlo", "code"} {
+			if strings.Contains(name, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func svgFontFor(st *svgStyle) *sfnt.Font {
+	return svgFonts[svgFontKey{mono: isMonospaceFamily(st.fontFamily), bold: st.bold, italic: st.italic}]
+}
+
+// textChar is one addressable character of a <text> element, with the position
+// attributes that apply to it and, once laid out, where its glyph goes.
+type textChar struct {
+	r      rune
+	st     *svgStyle
+	x, y   *float64
+	dx, dy float64
+	px, py float64
+}
+
+// textPositions holds one element's x, y, dx and dy lists, indexed by the characters
+// the element contains.
+type textPositions struct {
+	x, y, dx, dy []float64
+	next         int
+}
+
+// collectText gathers the characters of a <text> element and its <tspan>s, collapsing
+// whitespace as xml:space="default" does. Each character takes, for each of x, y, dx
+// and dy, the value from the innermost element whose list reaches it.
+func (r *svgRenderer) collectText(node *svgNode, ctx svgContext, st *svgStyle, stack []*textPositions, chars []textChar) []textChar {
+	fs := st.fontSize
+	stack = append(stack, &textPositions{
+		x:  lengthList(node.attrs["x"], ctx.vp.w, fs),
+		y:  lengthList(node.attrs["y"], ctx.vp.h, fs),
+		dx: lengthList(node.attrs["dx"], ctx.vp.w, fs),
+		dy: lengthList(node.attrs["dy"], ctx.vp.h, fs),
+	})
+
+	for _, child := range node.children {
+		switch child.name {
+		case "":
+			for _, ch := range child.text {
+				if ch == '\n' || ch == '\r' || ch == '\t' {
+					ch = ' '
+				}
+				if ch == ' ' && (len(chars) == 0 || chars[len(chars)-1].r == ' ') {
+					continue
+				}
+				c := textChar{r: ch, st: st}
+				for i := len(stack) - 1; i >= 0; i-- {
+					pos := stack[i]
+					if c.x == nil && pos.next < len(pos.x) {
+						c.x = &pos.x[pos.next]
+					}
+					if c.y == nil && pos.next < len(pos.y) {
+						c.y = &pos.y[pos.next]
+					}
+				}
+				for i := len(stack) - 1; i >= 0; i-- {
+					if pos := stack[i]; pos.next < len(pos.dx) {
+						c.dx = pos.dx[pos.next]
+						break
+					}
+				}
+				for i := len(stack) - 1; i >= 0; i-- {
+					if pos := stack[i]; pos.next < len(pos.dy) {
+						c.dy = pos.dy[pos.next]
+						break
+					}
+				}
+				for _, pos := range stack {
+					pos.next++
+				}
+				chars = append(chars, c)
+			}
+		case "tspan", "a":
+			props := r.doc.properties(child)
+			if props["display"] == "none" {
+				continue
+			}
+			childStyle := computeStyle(*st, props, ctx.vp)
+			chars = r.collectText(child, ctx, &childStyle, stack, chars)
+		}
+	}
+	return chars
+}
+
+func lengthList(v string, ref, fontSize float64) []float64 {
+	var list []float64
+	for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+		f, ok := parseSVGLength(part, ref, fontSize)
+		if !ok {
+			return list
+		}
+		list = append(list, f)
+	}
+	return list
+}
+
+// drawText lays out a <text> element and draws its glyph outlines as paths, so text
+// takes any transform, fill, stroke or gradient a shape would. A new absolute x or y
+// starts a text chunk, and text-anchor aligns each chunk on its own.
+func (r *svgRenderer) drawText(node *svgNode, ctx svgContext) {
+	if err := loadSVGFonts(); err != nil {
+		r.err = err
+		return
+	}
+	chars := r.collectText(node, ctx, &ctx.style, nil, nil)
+	for len(chars) > 0 && chars[len(chars)-1].r == ' ' {
+		chars = chars[:len(chars)-1]
+	}
+	if r.steps += len(chars); r.steps > maxSVGRenderSteps {
+		r.err = errSVGTooComplex
+		return
+	}
+	if len(chars) == 0 {
+		return
+	}
+
+	var buf sfnt.Buffer
+	glyphs := make([]sfnt.GlyphIndex, len(chars))
+	var penX, penY float64
+	chunkStart := 0
+	alignChunk := func(end int) {
+		if end <= chunkStart {
+			return
+		}
+		width := penX - chars[chunkStart].px
+		shift := 0.0
+		switch chars[chunkStart].st.textAnchor {
+		case "middle":
+			shift = -width / 2
+		case "end":
+			shift = -width
+		}
+		for i := chunkStart; i < end; i++ {
+			chars[i].px += shift
+		}
+	}
+
+	for i := range chars {
+		c := &chars[i]
+		if i > 0 && (c.x != nil || c.y != nil) {
+			alignChunk(i)
+			chunkStart = i
+		}
+		if c.x != nil {
+			penX = *c.x
+		}
+		if c.y != nil {
+			penY = *c.y
+		}
+		penX += c.dx
+		penY += c.dy
+
+		f := svgFontFor(c.st)
+		scale := c.st.fontSize / float64(f.UnitsPerEm())
+		ppem := fixed.I(int(f.UnitsPerEm()))
+		glyphs[i], _ = f.GlyphIndex(&buf, c.r)
+		if i > chunkStart && chars[i-1].st == c.st {
+			if kern, err := f.Kern(&buf, glyphs[i-1], glyphs[i], ppem, font.HintingNone); err == nil {
+				penX += float64(kern) / 64 * scale
+			}
+		}
+		c.px, c.py = penX, penY
+		if adv, err := f.GlyphAdvance(&buf, glyphs[i], ppem, font.HintingNone); err == nil {
+			penX += float64(adv) / 64 * scale
+		}
+	}
+	alignChunk(len(chars))
+
+	// Glyphs sharing a style are drawn as one path, and a bounding-box gradient spans
+	// the whole element.
+	type run struct {
+		st   *svgStyle
+		path *svgPath
+	}
+	var runs []run
+	for i, c := range chars {
+		if len(runs) == 0 || runs[len(runs)-1].st != c.st {
+			runs = append(runs, run{st: c.st, path: &svgPath{}})
+		}
+		f := svgFontFor(c.st)
+		appendGlyph(runs[len(runs)-1].path, f, &buf, glyphs[i], c.px, c.py, c.st.fontSize/float64(f.UnitsPerEm()))
+	}
+
+	all := &svgPath{}
+	for _, run := range runs {
+		all.pts = append(all.pts, run.path.pts...)
+	}
+	if all.empty() {
+		return
+	}
+	bbox := pathBox(all)
+	for _, run := range runs {
+		if !run.path.empty() {
+			r.drawPath(run.path, run.st, ctx.ctm, bbox, ctx.vp)
+		}
+	}
+}
+
+// appendGlyph adds a glyph's outline to p with its origin at x, y. Outlines are loaded
+// at one pixel per font unit and scaled here, so no precision is lost before the path
+// reaches device space.
+func appendGlyph(p *svgPath, f *sfnt.Font, buf *sfnt.Buffer, gi sfnt.GlyphIndex, x, y, scale float64) {
+	segments, err := f.LoadGlyph(buf, gi, fixed.I(int(f.UnitsPerEm())), nil)
+	if err != nil {
+		return
+	}
+	pt := func(a fixed.Point26_6) (float64, float64) {
+		return x + float64(a.X)/64*scale, y + float64(a.Y)/64*scale
+	}
+	open := false
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			if open {
+				p.close()
+			}
+			p.moveTo(pt(seg.Args[0]))
+			open = true
+		case sfnt.SegmentOpLineTo:
+			p.lineTo(pt(seg.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			x1, y1 := pt(seg.Args[0])
+			x2, y2 := pt(seg.Args[1])
+			p.quadTo(x1, y1, x2, y2)
+		case sfnt.SegmentOpCubeTo:
+			x1, y1 := pt(seg.Args[0])
+			x2, y2 := pt(seg.Args[1])
+			x3, y3 := pt(seg.Args[2])
+			p.cubeTo(x1, y1, x2, y2, x3, y3)
+		}
+	}
+	if open {
+		p.close()
+	}
+}
@@ -0,0 +1,114 @@
+package previews
+
+import (
+	"image"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/fogleman/gg"
+	"golang.org/x/image/draw"
+)
+
+// cardTemplate is what sets one kind of card apart from another: what fills its
+// region, and the title, description and chips of its footer. The layout around them
+// is the same for every card.
+type cardTemplate struct {
+	title       string
+	description string
+	chips       []chipSpec
+	statuses    []string
+	palette     Palette
+	region      func(dc *gg.Context, regionHeight float64) error
+
+	// footer, when set, fills the footerHeight below the region in place of the
+	// title, description and chips.
+	footer       func(dc *gg.Context, top float64) error
+	footerHeight float64
+}
+
+// regionFrame draws one frame of an animated region over what region drew.
+type regionFrame func(dc *gg.Context, regionHeight float64) error
+
+func (t cardTemplate) compose() (image.Image, error) {
+	frames, err := t.composeFrames(nil)
+	if err != nil {
+		return nil, err
+	}
+	return frames[0], nil
+}
+
+// composeFrames draws the card once and then each of frames over a copy of it, the
+// status chips on top, so everything that doesn't animate is drawn a single time.
+// Without frames it draws the card alone.
+func (t cardTemplate) composeFrames(frames []regionFrame) ([]image.Image, error) {
+	if err := loadFonts(); err != nil {
+		return nil, err
+	}
+
+	dc := newCanvasWith(t.palette.Surface)
+	var descLines []string
+	var regionHeight float64
+	if t.footer != nil {
+		regionHeight = cardHeight - accentHeight - 3*regionInset - t.footerHeight
+	} else {
+		var err error
+		if descLines, regionHeight, err = footerLayout(dc, t.description); err != nil {
+			return nil, err
+		}
+	}
+
+	dc.SetColor(t.palette.SurfaceContainer)
+	dc.DrawRoundedRectangle(regionInset, regionInset, regionWidth, regionHeight, regionRadius)
+	dc.Fill()
+
+	if t.region != nil {
+		if err := t.region(dc, regionHeight); err != nil {
+			return nil, err
+		}
+	}
+	if err := drawFooter(dc, t, descLines, regionHeight); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		if err := drawStatusChips(dc, t.statuses); err != nil {
+			return nil, err
+		}
+		return []image.Image{dc.Image()}, nil
+	}
+
+	base := dc.Image()
+	out := make([]image.Image, len(frames))
+	for i, frame := range frames {
+		fc := dc
+		if len(frames) > 1 {
+			canvas := image.NewRGBA(base.Bounds())
+			draw.Draw(canvas, canvas.Rect, base, image.Point{}, draw.Src)
+			fc = gg.NewContextForRGBA(canvas)
+		}
+		if err := frame(fc, regionHeight); err != nil {
+			return nil, err
+		}
+		if err := drawStatusChips(fc, t.statuses); err != nil {
+			return nil, err
+		}
+		out[i] = fc.Image()
+	}
+	return out, nil
+}
+
+// pluginTemplate is a plugin's card without a screenshot, its icon standing in.
+func pluginTemplate(p models.Plugin, pal Palette) cardTemplate {
+	em := emblem{icon: p.Icon, name: p.Name, headline: p.Name, mark: "DMS"}
+	if p.Author != "" {
+		em.byline = "by " + p.Author
+	}
+	return cardTemplate{
+		title:       p.Name,
+		description: p.Description,
+		chips:       footerChips(p, pal),
+		statuses:    p.Status,
+		palette:     pal,
+		region: func(dc *gg.Context, regionHeight float64) error {
+			return em.draw(dc, pal, regionHeight)
+		},
+	}
+}
@@ -0,0 +1,469 @@
+package previews
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"strings"
+	"unicode"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/bidi"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+)
+
+// Fallback fonts, tried in order for runes the Go fonts lack. fonts/noto.sh builds
+// them; any that are missing are left out of the chain.
+var (
+	regularFallbackFiles = []string{
+		"fonts/NotoSansHebrew-Regular.ttf",
+		"fonts/NotoSansArabic-Regular.ttf",
+		"fonts/NotoSansCJK-Regular.ttf",
+		"fonts/NotoEmoji-Regular.ttf",
+	}
+	boldFallbackFiles = []string{
+		"fonts/NotoSansHebrew-Bold.ttf",
+		"fonts/NotoSansArabic-Bold.ttf",
+		"fonts/NotoSansCJK-Bold.ttf",
+		"fonts/NotoEmoji-Regular.ttf",
+	}
+)
+
+func loadFallbacks(files []string) []*opentype.Font {
+	var chain []*opentype.Font
+	for _, name := range files {
+		data, err := fontAssets.ReadFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Warnf("Failed to read fallback font %s: %v", name, err)
+			continue
+		}
+		f, err := opentype.Parse(data)
+		if err != nil {
+			log.Warnf("Failed to parse fallback font %s: %v", name, err)
+			continue
+		}
+		chain = append(chain, f)
+		fallbackFiles = append(fallbackFiles, name)
+	}
+	return chain
+}
+
+// newTextFace is newFace for text that may come from plugin or theme metadata: each
+// rune is drawn from the first font in f's fallback chain that has a glyph for it.
+func newTextFace(f *opentype.Font, size float64) (font.Face, error) {
+	chain := append([]*opentype.Font{f}, fallbackChains[f]...)
+	faces := make([]font.Face, len(chain))
+	for i, cf := range chain {
+		face, err := newFace(cf, size)
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = face
+	}
+	return &fallbackFace{fonts: chain, faces: faces, picks: map[rune]int{}}, nil
+}
+
+// fallbackFace is a font.Face over a chain of fonts. Runes none of them cover are left
+// to the first, which draws its missing-glyph box. Like the faces it wraps, it is not
+// safe for concurrent use.
+type fallbackFace struct {
+	fonts []*opentype.Font
+	faces []font.Face
+	picks map[rune]int
+	buf   sfnt.Buffer
+}
+
+func (f *fallbackFace) pick(r rune) font.Face {
+	i, ok := f.picks[r]
+	if !ok {
+		for j, cf := range f.fonts {
+			if g, err := cf.GlyphIndex(&f.buf, r); err == nil && g != 0 {
+				i = j
+				break
+			}
+		}
+		f.picks[r] = i
+	}
+	return f.faces[i]
+}
+
+// isIgnorable reports runes that are never drawn: joiners, variation selectors, bidi
+// controls and the like. Drawing them from a font without a glyph would leave a box
+// in the middle of an emoji sequence or an Arabic name.
+func isIgnorable(r rune) bool {
+	switch {
+	case r == 0x00AD, r == 0x034F, r == 0x061C, r == 0xFEFF:
+		return true
+	case r >= 0x200B && r <= 0x200F, r >= 0x202A && r <= 0x202E, r >= 0x2060 && r <= 0x206F:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0000 && r <= 0xE0FFF:
+		return true
+	}
+	return false
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return 0, false
+	}
+	return f.pick(r).GlyphAdvance(r)
+}
+
+// Kern only applies between runes drawn from the same font.
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if isIgnorable(r0) || isIgnorable(r1) {
+		return 0
+	}
+	a, b := f.pick(r0), f.pick(r1)
+	if a != b {
+		return 0
+	}
+	return a.Kern(r0, r1)
+}
+
+// Metrics are the primary font's, so line heights and anchoring match cards drawn
+// before fallbacks existed.
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+// displayText turns one line of logical text into the order it is drawn in: Arabic
+// letters take their joined forms, then right-to-left runs are reversed.
+func displayText(s string) string {
+	if !hasRTL(s) {
+		return s
+	}
+	return string(visualOrder(shapeArabic([]rune(s))))
+}
+
+// fontSourceKey names the fallback fonts cards are drawn with, so cards are drawn again
+// once fonts/noto.sh has been run.
+func fontSourceKey() string {
+	if err := loadFonts(); err != nil {
+		return ""
+	}
+	return strings.Join(fallbackFiles, ",")
+}
+
+func measureText(dc *gg.Context, s string) float64 {
+	w, _ := dc.MeasureString(displayText(s))
+	return w
+}
+
+func drawText(dc *gg.Context, s string, x, y, ax, ay float64) {
+	dc.DrawStringAnchored(displayText(s), x, y, ax, ay)
+}
+
+func hasRTL(s string) bool {
+	for _, r := range s {
+		if r >= 0x0590 && r <= 0x08FF || r >= 0xFB1D && r <= 0xFDFF || r >= 0xFE70 && r <= 0xFEFF {
+			return true
+		}
+	}
+	return false
+}
+
+// visualOrder applies the implicit rules of the Unicode bidirectional algorithm to a
+// single line with no explicit embeddings, which is all card text ever is. Combining
+// marks stay after their base when a run is reversed, where a font without mark
+// positioning expects them.
+func visualOrder(runes []rune) []rune {
+	n := len(runes)
+	if n == 0 {
+		return runes
+	}
+	orig := make([]bidi.Class, n)
+	for i, r := range runes {
+		p, _ := bidi.LookupRune(r)
+		orig[i] = p.Class()
+	}
+
+	base := 0
+	for _, c := range orig {
+		if c == bidi.L {
+			break
+		}
+		if c == bidi.R || c == bidi.AL {
+			base = 1
+			break
+		}
+	}
+	sor := bidi.L
+	if base == 1 {
+		sor = bidi.R
+	}
+
+	t := make([]bidi.Class, n)
+	for i, c := range orig {
+		switch c {
+		case bidi.LRO, bidi.RLO, bidi.LRE, bidi.RLE, bidi.PDF, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI, bidi.BN, bidi.Control:
+			c = bidi.ON
+		}
+		t[i] = c
+	}
+
+	// W1-W3: marks take the class before them, numbers after Arabic letters are
+	// Arabic numbers, and Arabic letters are right-to-left.
+	strong := sor
+	for i := range t {
+		if t[i] == bidi.NSM {
+			if i == 0 {
+				t[i] = sor
+			} else {
+				t[i] = t[i-1]
+			}
+		}
+		switch t[i] {
+		case bidi.L, bidi.R, bidi.AL:
+			strong = t[i]
+		case bidi.EN:
+			if strong == bidi.AL {
+				t[i] = bidi.AN
+			}
+		}
+	}
+	for i := range t {
+		if t[i] == bidi.AL {
+			t[i] = bidi.R
+		}
+	}
+	// W4: a single separator between two numbers of one kind joins them.
+	for i := 1; i+1 < n; i++ {
+		if t[i-1] != t[i+1] {
+			continue
+		}
+		if t[i] == bidi.ES && t[i-1] == bidi.EN || t[i] == bidi.CS && (t[i-1] == bidi.EN || t[i-1] == bidi.AN) {
+			t[i] = t[i-1]
+		}
+	}
+	// W5: terminators next to European numbers are part of them.
+	for i := 0; i < n; {
+		if t[i] != bidi.ET {
+			i++
+			continue
+		}
+		j := i
+		for j < n && t[j] == bidi.ET {
+			j++
+		}
+		if i > 0 && t[i-1] == bidi.EN || j < n && t[j] == bidi.EN {
+			for k := i; k < j; k++ {
+				t[k] = bidi.EN
+			}
+		}
+		i = j
+	}
+	// W6-W7: leftover separators are neutral, and European numbers in left-to-right
+	// text are left-to-right.
+	strong = sor
+	for i := range t {
+		switch t[i] {
+		case bidi.ES, bidi.ET, bidi.CS:
+			t[i] = bidi.ON
+		case bidi.L, bidi.R:
+			strong = t[i]
+		case bidi.EN:
+			if strong == bidi.L {
+				t[i] = bidi.L
+			}
+		}
+	}
+
+	// N1-N2: neutrals between two runs of one direction take it, and otherwise the
+	// line's.
+	direction := func(c bidi.Class) (bidi.Class, bool) {
+		switch c {
+		case bidi.L:
+			return bidi.L, true
+		case bidi.R, bidi.EN, bidi.AN:
+			return bidi.R, true
+		}
+		return 0, false
+	}
+	for i := 0; i < n; {
+		if _, ok := direction(t[i]); ok {
+			i++
+			continue
+		}
+		j := i
+		for j < n {
+			if _, ok := direction(t[j]); ok {
+				break
+			}
+			j++
+		}
+		before, after := sor, sor
+		if i > 0 {
+			before, _ = direction(t[i-1])
+		}
+		if j < n {
+			after, _ = direction(t[j])
+		}
+		resolved := sor
+		if before == after {
+			resolved = before
+		}
+		for k := i; k < j; k++ {
+			t[k] = resolved
+		}
+		i = j
+	}
+
+	// I1-I2 and L1: levels from the resolved classes, with trailing whitespace back at
+	// the line's level.
+	levels := make([]int, n)
+	for i, c := range t {
+		switch {
+		case base == 0 && c == bidi.R:
+			levels[i] = 1
+		case base == 0 && (c == bidi.EN || c == bidi.AN):
+			levels[i] = 2
+		case base == 1 && (c == bidi.L || c == bidi.EN || c == bidi.AN):
+			levels[i] = 2
+		default:
+			levels[i] = base
+		}
+	}
+	for i := n - 1; i >= 0 && (orig[i] == bidi.WS || orig[i] == bidi.S); i-- {
+		levels[i] = base
+	}
+
+	// L2 over clusters: from the highest level down to the lowest odd one, reverse
+	// every run at that level or above.
+	type cluster struct {
+		runes []rune
+		level int
+	}
+	var clusters []cluster
+	maxLevel := 0
+	for i, r := range runes {
+		if i > 0 && orig[i] == bidi.NSM {
+			last := &clusters[len(clusters)-1]
+			last.runes = append(last.runes, r)
+			continue
+		}
+		clusters = append(clusters, cluster{runes: []rune{r}, level: levels[i]})
+		maxLevel = max(maxLevel, levels[i])
+	}
+	for level := maxLevel; level >= 1; level-- {
+		for i := 0; i < len(clusters); {
+			if clusters[i].level < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(clusters) && clusters[j].level >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				clusters[a], clusters[b] = clusters[b], clusters[a]
+			}
+			i = j
+		}
+	}
+
+	out := make([]rune, 0, n)
+	for _, c := range clusters {
+		for _, r := range c.runes {
+			if c.level%2 == 1 {
+				r = mirrorRune(r)
+			}
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// mirrorRune swaps brackets and angle quotes, which point the other way in
+// right-to-left text.
+func mirrorRune(r rune) rune {
+	switch r {
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	case '«':
+		return '»'
+	case '»':
+		return '«'
+	case '‹':
+		return '›'
+	case '›':
+		return '‹'
+	}
+	if p, _ := bidi.LookupRune(r); p.IsBracket() {
+		return []rune(bidi.ReverseString(string(r)))[0]
+	}
+	return r
+}
+
+// breaksAround reports runes a line may break before or after without a space, as
+// it does between the ideographs and kana of CJK text.
+func breaksAround(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFF60
+}
+
+// noBreakBefore holds the closing punctuation and small kana that never start a line,
+// and noBreakAfter the opening brackets that never end one.
+const (
+	noBreakBefore = "、。，．：；！？）」』】〉》〕ー…・ぁぃぅぇぉっゃゅょァィゥェォッャュョ"
+	noBreakAfter  = "（「『【〈《〔"
+)
+
+// wrapSegments splits one line into the pieces wrapping keeps whole: a word with the
+// spaces after it, or one CJK character.
+func wrapSegments(line string) []string {
+	var segs []string
+	var cur strings.Builder
+	var prev rune
+	flush := func() {
+		if cur.Len() > 0 {
+			segs = append(segs, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range line {
+		switch {
+		case cur.Len() == 0:
+		case unicode.IsSpace(prev) && !unicode.IsSpace(r):
+			flush()
+		case (breaksAround(r) || breaksAround(prev)) && !unicode.IsSpace(r) &&
+			!strings.ContainsRune(noBreakBefore, r) && !strings.ContainsRune(noBreakAfter, prev):
+			flush()
+		}
+		cur.WriteRune(r)
+		prev = r
+	}
+	flush()
+	return segs
+}
@@ -0,0 +1,205 @@
+package previews
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden images in testdata")
+
+func TestShapeArabicJoinsLetters(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []rune
+		want []rune
+	}{
+		// Seen starts the word, lam and alef join as a ligature, and meem stands alone
+		// because alef never joins the letter after it.
+		{"salaam", []rune{0x0633, 0x0644, 0x0627, 0x0645}, []rune{0xFEB3, 0xFEFC, 0xFEE1}},
+		{"bayt", []rune{0x0628, 0x064A, 0x062A}, []rune{0xFE91, 0xFEF4, 0xFE96}},
+		// A mark does not break the join around it.
+		{"mark", []rune{0x0628, 0x064E, 0x0628}, []rune{0xFE91, 0x064E, 0xFE90}},
+		{"la", []rune{0x0644, 0x0627}, []rune{0xFEFB}},
+		{"latin", []rune("DMS 2"), []rune("DMS 2")},
+	}
+	for _, c := range cases {
+		if got := shapeArabic(c.in); !slices.Equal(got, c.want) {
+			t.Errorf("%s: shapeArabic = %U, want %U", c.name, got, c.want)
+		}
+	}
+}
+
+func TestVisualOrderReversesRightToLeftRuns(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"hebrew", "שלום", "םולש"},
+		{"embedded", "abc אבג def", "abc גבא def"},
+		{"numbers keep their order", "עמוד 12", "12 דומע"},
+		{"brackets mirror", "א(ב", "ב)א"},
+		{"ellipsis at the logical end", "שלום…", "…םולש"},
+		{"marks follow their base", "بَت", "تبَ"},
+	}
+	for _, c := range cases {
+		if got := string(visualOrder([]rune(c.in))); got != c.want {
+			t.Errorf("%s: visualOrder(%q) = %q, want %q", c.name, c.in, got, c.want)
+		}
+	}
+	if got := displayText("Plain text"); got != "Plain text" {
+		t.Fatalf("expected left-to-right text untouched, got %q", got)
+	}
+}
+
+func TestWrapLinesBreaksBetweenCJKCharacters(t *testing.T) {
+	if err := loadFonts(); err != nil {
+		t.Fatal(err)
+	}
+	dc := newCanvasWith(DarkPalette.Surface)
+	face, err := newTextFace(regularFont, 22)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc.SetFontFace(face)
+
+	desc := strings.Repeat("这是一个显示系统状态的插件。", 6)
+	lines := wrapLines(dc, desc, 300, 10)
+	if len(lines) < 2 {
+		t.Fatalf("expected text without spaces to wrap, got %q", lines)
+	}
+	if got := strings.Join(lines, ""); got != desc {
+		t.Fatalf("description altered by wrapping:\n%q\nwant\n%q", got, desc)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "。") {
+			t.Fatalf("expected no line to start with closing punctuation, got %q", lines)
+		}
+		if w := measureText(dc, line); w > 300 {
+			t.Fatalf("line %q is %.0f wide, over 300", line, w)
+		}
+	}
+}
+
+func TestFallbackFaceSkipsIgnorableRunes(t *testing.T) {
+	if err := loadFonts(); err != nil {
+		t.Fatal(err)
+	}
+	face, err := newTextFace(regularFont, 22)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []rune{0x200D, 0xFE0F, 0x200F} {
+		if _, ok := face.GlyphAdvance(r); ok {
+			t.Errorf("expected %U to have no glyph", r)
+		}
+		if _, mask, _, _, ok := face.Glyph(fixed.P(10, 10), r); ok || mask != nil {
+			t.Errorf("expected %U to draw nothing", r)
+		}
+	}
+	if adv, ok := face.GlyphAdvance('A'); !ok || adv <= 0 {
+		t.Fatalf("expected the primary font to draw ASCII, got %v %v", adv, ok)
+	}
+}
+
+// mixedScriptPlugins are the golden-image fixtures: names and descriptions that need
+// every fallback font, alone and next to Latin text.
+var mixedScriptPlugins = []struct {
+	name   string
+	plugin models.Plugin
+}{
+	{"cjk", models.Plugin{Name: "天气 Weather 天気 날씨", Category: "widgets", Version: "1.2", Author: "小明",
+		Description: "在顶栏显示当前天气和未来三天的预报，支持摄氏度和华氏度。"}},
+	{"arabic", models.Plugin{Name: "مراقب النظام", Category: "monitoring", Author: "سلمى",
+		Description: "يعرض استخدام المعالج والذاكرة في الشريط العلوي (الإصدار 2)"}},
+	{"hebrew", models.Plugin{Name: "שעון Clock", Category: "widgets", Version: "0.3", Author: "דנה",
+		Description: "מציג את השעה בכמה אזורי זמן, with English in between."}},
+	{"emoji", models.Plugin{Name: "Emoji Picker 🎉", Category: "utilities", Author: "tester",
+		Description: "Pick 😀 emoji and ✂️ symbols from the launcher 🚀"}},
+}
+
+func TestMixedScriptCardsMatchGoldenImages(t *testing.T) {
+	if err := loadFonts(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{}
+	for _, name := range append(slices.Clone(regularFallbackFiles), boldFallbackFiles...) {
+		want[name] = true
+	}
+	if len(fallbackFiles) != len(want) {
+		t.Fatalf("only %d of %d fallback fonts are embedded; run go generate ./internal/services/previews and commit its output", len(fallbackFiles), len(want))
+	}
+
+	for _, fixture := range mixedScriptPlugins {
+		t.Run(fixture.name, func(t *testing.T) {
+			img, err := ComposeCard(fixture.plugin, DarkPalette)
+			if err != nil {
+				t.Fatalf("ComposeCard: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "card-"+fixture.name+".png")
+			if *update {
+				var buf bytes.Buffer
+				if err := png.Encode(&buf, img); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll("testdata", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			f, err := os.Open(golden)
+			if err != nil {
+				t.Fatalf("missing golden image, run go test -update: %v", err)
+			}
+			defer f.Close()
+			expected, err := png.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := imageDiff(img, expected); diff > 0.001 {
+				t.Fatalf("card differs from %s in %.2f%% of pixels", golden, diff*100)
+			}
+		})
+	}
+}
+
+// imageDiff is the fraction of pixels that differ visibly, which tolerates the
+// rounding differences antialiasing has between platforms.
+func imageDiff(a, b image.Image) float64 {
+	if a.Bounds() != b.Bounds() {
+		return 1
+	}
+	bounds := a.Bounds()
+	differ := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := a.At(x, y).RGBA()
+			r2, g2, b2, _ := b.At(x, y).RGBA()
+			if absDiff(r1, r2) > 0x1000 || absDiff(g1, g2) > 0x1000 || absDiff(b1, b2) > 0x1000 {
+				differ++
+			}
+		}
+	}
+	return float64(differ) / float64(bounds.Dx()*bounds.Dy())
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,292 @@
+package previews
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+	"github.com/fogleman/gg"
+)
+
+const (
+	maxThemeStrips   = 6
+	themeStripHeight = 30.0
+	themeStripGap    = 8.0
+	themeLabelWidth  = 184.0
+	themeSwatchGap   = 4.0
+)
+
+// themeSwatchTokens are the tokens each strip shows, left to right: the surfaces DMS
+// layers its bars and popouts on, the accent, the two text tones and the status colors.
+var themeSwatchTokens = []string{
+	"surface",
+	"surfaceContainer",
+	"surfaceContainerHigh",
+	"primary",
+	"surfaceText",
+	"surfaceVariantText",
+	"error",
+	"warning",
+	"info",
+}
+
+// themePalette is the headline configuration a theme card is drawn with. Tokens a
+// theme leaves out fall back to the dark plugin card palette so the card always renders.
+type themePalette struct {
+	surface              color.NRGBA
+	surfaceContainer     color.NRGBA
+	surfaceContainerHigh color.NRGBA
+	primary              color.NRGBA
+	primaryText          color.NRGBA
+	surfaceText          color.NRGBA
+	surfaceVariantText   color.NRGBA
+	errorColor           color.NRGBA
+	warning              color.NRGBA
+	info                 color.NRGBA
+}
+
+func newThemePalette(scheme map[string]interface{}) themePalette {
+	pick := func(token string, fallback color.NRGBA) color.NRGBA {
+		return opaqueTokenColor(scheme, token, fallback)
+	}
+
+	p := themePalette{
+		surface:          pick("surface", DarkPalette.Surface),
+		surfaceContainer: pick("surfaceContainer", DarkPalette.SurfaceContainer),
+		primary:          pick("primary", DarkPalette.Primary),
+		surfaceText:      pick("surfaceText", DarkPalette.SurfaceText),
+		errorColor:       pick("error", statusChipColors["broken"]),
+		warning:          pick("warning", statusChipColors["unmaintained"]),
+		info:             pick("info", DarkPalette.Primary),
+	}
+	p.surfaceContainerHigh = pick("surfaceContainerHigh", lighten(p.surfaceContainer, 0.06))
+	p.primaryText = pick("primaryText", p.surface)
+	p.surfaceVariantText = pick("surfaceVariantText", p.surfaceText)
+	return p
+}
+
+// parseTokenColor reads a theme token as the registry does, keeping its alpha.
+// Anything it can't read is reported as missing so the caller can fall back rather
+// than paint black.
+func parseTokenColor(scheme map[string]interface{}, token string) (color.NRGBA, bool) {
+	c, err := registry.SchemeColor(scheme, token)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	r, g, b := c.Bytes()
+	return color.NRGBA{R: r, G: g, B: b, A: uint8(math.Round(c.A * 255))}, true
+}
+
+// opaqueTokenColor reads a token a card is painted with, falling back when it is
+// missing. A translucent token is composited onto fallback, which stands in for
+// whatever DMS would draw it over.
+func opaqueTokenColor(scheme map[string]interface{}, token string, fallback color.NRGBA) color.NRGBA {
+	c, err := registry.SchemeColor(scheme, token)
+	if err != nil {
+		return fallback
+	}
+	r, g, b := c.Over(rgbOf(fallback)).Bytes()
+	return color.NRGBA{R: r, G: g, B: b, A: 0xFF}
+}
+
+func rgbOf(c color.NRGBA) colors.RGB {
+	return colors.RGB{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}
+}
+
+// ComposeThemeCard renders a theme preview from its color tokens: a mock DMS panel in
+// the theme's default configuration above one swatch strip per flavor or variant.
+func ComposeThemeCard(t models.Theme) (image.Image, error) {
+	configs := registry.ThemeConfigs(&t)
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("theme %s has no colors to preview", t.ID)
+	}
+
+	headline := configs[0].Colors
+	if resolved, err := registry.ResolveScheme(&t, registry.ThemeSelection{Mode: configs[0].Mode}); err == nil {
+		headline = resolved.Colors
+	}
+	pal := newThemePalette(headline)
+
+	strips := min(len(configs), maxThemeStrips)
+	return cardTemplate{
+		palette: pal.card(),
+		region: func(dc *gg.Context, regionHeight float64) error {
+			return drawThemePanel(dc, t, pal, regionHeight)
+		},
+		footer: func(dc *gg.Context, top float64) error {
+			return drawThemeStrips(dc, configs, pal, top)
+		},
+		footerHeight: float64(strips)*themeStripHeight + float64(strips-1)*themeStripGap,
+	}.compose()
+}
+
+// card is the part of the palette the card template draws with itself.
+func (p themePalette) card() Palette {
+	return Palette{
+		Primary:          p.primary,
+		Surface:          p.surface,
+		SurfaceText:      p.surfaceText,
+		SurfaceContainer: p.surfaceContainer,
+		Outline:          p.surfaceVariantText,
+		Description:      p.surfaceVariantText,
+	}
+}
+
+func drawThemePanel(dc *gg.Context, t models.Theme, pal themePalette, panelHeight float64) error {
+	const (
+		padX       = 32.0
+		cardW      = 320.0
+		buttonH    = 40.0
+		statusR    = 10.0
+		statusStep = 32.0
+	)
+
+	cardX := regionInset + regionWidth - padX - cardW
+	cardY := regionInset + padX
+	cardH := panelHeight - 2*padX
+	dc.SetColor(pal.surfaceContainerHigh)
+	dc.DrawRoundedRectangle(cardX, cardY, cardW, cardH, 12)
+	dc.Fill()
+
+	sampleFace, err := newFace(boldFont, 20)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(sampleFace)
+	dc.SetColor(pal.surfaceText)
+	dc.DrawString("Notifications", cardX+20, cardY+36)
+
+	bodyFace, err := newFace(regularFont, 16)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(bodyFace)
+	dc.SetColor(pal.surfaceVariantText)
+	for i, line := range []string{"Updates available", "Battery at 80%", "Connected to Wi-Fi"} {
+		y := cardY + 68 + float64(i)*26
+		if y > cardY+cardH-12 {
+			break
+		}
+		dc.DrawString(line, cardX+20, y)
+	}
+
+	textMax := cardX - regionInset - 2*padX
+	nameFace, err := newTextFace(boldFont, 44)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(nameFace)
+	dc.SetColor(pal.surfaceText)
+	name := t.Name
+	if name == "" {
+		name = t.ID
+	}
+	drawText(dc, ellipsize(dc, name, textMax), regionInset+padX, regionInset+padX+40, 0, 0)
+
+	metaFace, err := newTextFace(regularFont, 18)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(metaFace)
+	dc.SetColor(pal.surfaceVariantText)
+	y := regionInset + padX + 74
+	if t.Author != "" {
+		drawText(dc, ellipsize(dc, "by "+t.Author, textMax), regionInset+padX, y, 0, 0)
+		y += 28
+	}
+	if t.Description != "" && y < regionInset+panelHeight-buttonH-padX-16 {
+		drawText(dc, ellipsize(dc, t.Description, textMax), regionInset+padX, y, 0, 0)
+	}
+
+	buttonY := regionInset + panelHeight - padX - buttonH
+	labelFace, err := newFace(boldFont, 16)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(labelFace)
+	labelW, _ := dc.MeasureString("Apply")
+	buttonW := labelW + 48
+	dc.SetColor(pal.primary)
+	dc.DrawRoundedRectangle(regionInset+padX, buttonY, buttonW, buttonH, buttonH/2)
+	dc.Fill()
+	dc.SetColor(pal.primaryText)
+	dc.DrawStringAnchored("Apply", regionInset+padX+buttonW/2, buttonY+buttonH/2, 0.5, 0.35)
+
+	statusX := regionInset + padX + buttonW + 24 + statusR
+	for i, c := range []color.NRGBA{pal.errorColor, pal.warning, pal.info} {
+		dc.SetColor(c)
+		dc.DrawCircle(statusX+float64(i)*statusStep, buttonY+buttonH/2, statusR)
+		dc.Fill()
+	}
+	return nil
+}
+
+func drawThemeStrips(dc *gg.Context, configs []registry.ThemeConfig, pal themePalette, top float64) error {
+	labelFace, err := newTextFace(boldFont, 16)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(labelFace)
+
+	shown := configs
+	more := 0
+	if len(configs) > maxThemeStrips {
+		shown = configs[:maxThemeStrips-1]
+		more = len(configs) - len(shown)
+	}
+
+	swatchLeft := regionInset + themeLabelWidth
+	swatchSpan := regionInset + regionWidth - swatchLeft
+	n := float64(len(themeSwatchTokens))
+	swatchW := (swatchSpan - (n-1)*themeSwatchGap) / n
+
+	for i, config := range shown {
+		y := top + float64(i)*(themeStripHeight+themeStripGap)
+
+		dc.SetColor(pal.surfaceText)
+		drawText(dc, ellipsize(dc, config.Label, themeLabelWidth-16), regionInset, y+themeStripHeight/2, 0, 0.35)
+
+		for j, token := range themeSwatchTokens {
+			x := swatchLeft + float64(j)*(swatchW+themeSwatchGap)
+			if c, ok := parseTokenColor(config.Colors, token); ok {
+				dc.SetColor(c)
+				dc.DrawRoundedRectangle(x, y, swatchW, themeStripHeight, 6)
+				dc.Fill()
+			}
+
+			// Outlines keep swatches that match the card background, and tokens the
+			// theme leaves out, visible as slots.
+			dc.SetColor(withAlpha(pal.surfaceText, 0.15))
+			dc.SetLineWidth(1)
+			dc.DrawRoundedRectangle(x+0.5, y+0.5, swatchW-1, themeStripHeight-1, 6)
+			dc.Stroke()
+		}
+	}
+
+	if more > 0 {
+		y := top + float64(len(shown))*(themeStripHeight+themeStripGap)
+		dc.SetColor(pal.surfaceVariantText)
+		drawText(dc, fmt.Sprintf("+%d more", more), regionInset, y+themeStripHeight/2, 0, 0.35)
+	}
+	return nil
+}
+
+func renderThemePlaceholder() ([]byte, error) {
+	img, err := ComposeThemeCard(models.Theme{
+		Name: "DMS Theme",
+		Dark: map[string]interface{}{
+			"surface":          rgbOf(DarkPalette.Surface).Hex(),
+			"surfaceContainer": rgbOf(DarkPalette.SurfaceContainer).Hex(),
+			"primary":          rgbOf(DarkPalette.Primary).Hex(),
+			"surfaceText":      rgbOf(DarkPalette.SurfaceText).Hex(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
@@ -0,0 +1,126 @@
+package previews
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+var testTheme = models.Theme{
+	ID:   "test-theme",
+	Name: "Test Theme",
+	Dark: map[string]interface{}{
+		"surface":          "#101010",
+		"surfaceContainer": "#202020",
+		"surfaceText":      "#F0F0F0",
+		"primary":          "#3366FF",
+	},
+	Light: map[string]interface{}{
+		"surface":          "#FAFAFA",
+		"surfaceContainer": "#EEEEEE",
+		"surfaceText":      "#101010",
+		"primary":          "#CC3300",
+	},
+}
+
+func TestComposeThemeCardDimensionsAndAccent(t *testing.T) {
+	img, err := ComposeThemeCard(testTheme)
+	if err != nil {
+		t.Fatalf("ComposeThemeCard: %v", err)
+	}
+	b := img.Bounds()
+	if b.Dx() != 960 || b.Dy() != 540 {
+		t.Fatalf("output size %dx%d, want 960x540", b.Dx(), b.Dy())
+	}
+	assertPixel(t, img, 480, 538, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+}
+
+func TestComposeThemeCardStripPerMode(t *testing.T) {
+	img, err := ComposeThemeCard(testTheme)
+	if err != nil {
+		t.Fatalf("ComposeThemeCard: %v", err)
+	}
+
+	// Two strips (dark, light) stacked above the accent bar; the fourth swatch is primary.
+	swatchW := (regionWidth - themeLabelWidth - 8*themeSwatchGap) / 9
+	x := int(regionInset + themeLabelWidth + 3*(swatchW+themeSwatchGap) + swatchW/2)
+	stripsTop := cardHeight - accentHeight - regionInset - (2*themeStripHeight + themeStripGap)
+	darkY := int(stripsTop + themeStripHeight/2)
+	lightY := int(stripsTop + themeStripHeight + themeStripGap + themeStripHeight/2)
+
+	assertPixel(t, img, x, darkY, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+	assertPixel(t, img, x, lightY, color.NRGBA{R: 0xCC, G: 0x33, B: 0x00})
+}
+
+func TestComposeThemeCardRejectsColorlessTheme(t *testing.T) {
+	if _, err := ComposeThemeCard(models.Theme{ID: "empty"}); err == nil {
+		t.Fatal("expected an error for a theme without colors")
+	}
+}
+
+func TestThemeSourceKeyTracksColors(t *testing.T) {
+	base := ThemeSourceKey(testTheme)
+
+	changed := testTheme
+	changed.Dark = map[string]interface{}{"surface": "#000000"}
+	if ThemeSourceKey(changed) == base {
+		t.Fatal("expected sourceKey to change with colors")
+	}
+	if ThemeSourceKey(testTheme) != base {
+		t.Fatal("expected sourceKey to be stable")
+	}
+}
+
+func TestParseTokenColorReadsEveryThemeNotation(t *testing.T) {
+	cases := []struct {
+		value    string
+		hexAlpha string
+		want     color.NRGBA
+	}{
+		{"#36F", "", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0xFF}},
+		{"#3366ff", "", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0xFF}},
+		{"#803366FF", "argb", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0x80}},
+		{"#3366FF80", "rgba", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0x80}},
+		{"rgb(51, 102, 255)", "", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0xFF}},
+		{"rgba(51 102 255 / 0)", "", color.NRGBA{R: 0x33, G: 0x66, B: 0xFF, A: 0x00}},
+	}
+	for _, tc := range cases {
+		scheme := map[string]interface{}{"primary": tc.value}
+		if tc.hexAlpha != "" {
+			scheme["hexAlpha"] = tc.hexAlpha
+		}
+		if got, ok := parseTokenColor(scheme, "primary"); !ok || got != tc.want {
+			t.Errorf("parseTokenColor(%q) = %v %v, want %v", tc.value, got, ok, tc.want)
+		}
+	}
+	for _, value := range []string{"blue", "#803366FF"} {
+		if _, ok := parseTokenColor(map[string]interface{}{"primary": value}, "primary"); ok {
+			t.Errorf("expected %q to be reported missing", value)
+		}
+	}
+
+	// A translucent surface is painted as it would show over the fallback.
+	transparent := map[string]interface{}{"surface": "#00FFFFFF", "hexAlpha": "argb"}
+	if got := opaqueTokenColor(transparent, "surface", DarkPalette.Surface); got != DarkPalette.Surface {
+		t.Errorf("expected a fully transparent token to show the fallback, got %v", got)
+	}
+}
+
+func TestComposeThemeCardDrawsShorthandTokens(t *testing.T) {
+	theme := models.Theme{ID: "short", Name: "Short", Dark: map[string]interface{}{
+		"surface":          "#111",
+		"surfaceContainer": "rgb(34, 34, 34)",
+		"surfaceText":      "#EEE",
+		"primary":          "#36F",
+	}}
+	img, err := ComposeThemeCard(theme)
+	if err != nil {
+		t.Fatalf("ComposeThemeCard: %v", err)
+	}
+	swatchW := (regionWidth - themeLabelWidth - 8*themeSwatchGap) / 9
+	x := int(regionInset + themeLabelWidth + 3*(swatchW+themeSwatchGap) + swatchW/2)
+	y := int(cardHeight - accentHeight - regionInset - themeStripHeight/2)
+	assertPixel(t, img, x, y, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+	assertPixel(t, img, 480, 538, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+}
@@ -0,0 +1,172 @@
+package previews
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/log"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+)
+
+// Variant is a palette a plugin preview can be redrawn in on request. Name becomes part
+// of the store key, so it is kept to characters safe in a file name.
+type Variant struct {
+	Name    string
+	Palette Palette
+}
+
+// LightVariant draws a preview for the docs site's light theme.
+func LightVariant() Variant {
+	return Variant{Name: "light", Palette: LightPalette}
+}
+
+// ThemeVariant draws a preview in a registry theme's colors. An empty mode takes the
+// theme's first configuration, as its own card does.
+func ThemeVariant(t models.Theme, mode string) (Variant, error) {
+	if !ThemeIDPattern.MatchString(t.ID) {
+		return Variant{}, fmt.Errorf("invalid theme id %q", t.ID)
+	}
+	name := "theme-" + t.ID
+	if mode == "" {
+		if configs := registry.ThemeConfigs(&t); len(configs) > 0 {
+			mode = configs[0].Mode
+		}
+	} else {
+		name += "-" + mode
+	}
+
+	resolved, err := registry.ResolveScheme(&t, registry.ThemeSelection{Mode: mode})
+	if err != nil {
+		return Variant{}, err
+	}
+	fallback := DarkPalette
+	if resolved.Mode == "light" {
+		fallback = LightPalette
+	}
+	return Variant{Name: name, Palette: PaletteFromScheme(resolved.Colors, fallback)}, nil
+}
+
+// variantSourceKey ties a variant to the preview it was redrawn from and to its colors,
+// so either changing renders it again.
+func variantSourceKey(parentKey string, pal Palette) string {
+	h := sha256.Sum256([]byte(parentKey + "\x00" + pal.key()))
+	return hex.EncodeToString(h[:])
+}
+
+func sourceImageKey(id string) string {
+	return id + "@source"
+}
+
+// PluginVariant returns the store key of p's preview drawn in v's palette, rendering it
+// on first request. Variants are derived from the stored preview: a card is drawn again,
+// and a screenshot is composed again from the source image kept at sync time, fetched
+// afresh if it is missing. Each variant is cached under its own key and dropped with the
+// preview it came from.
+func (g *Generator) PluginVariant(ctx context.Context, p models.Plugin, v Variant) (string, bool) {
+	parent, ok := g.store.entry(p.ID)
+	if !ok {
+		return "", false
+	}
+	key := p.ID + "@" + v.Name
+	sourceKey := variantSourceKey(parent.SourceKey, v.Palette)
+	if !g.store.NeedsUpdate(key, sourceKey) {
+		return key, true
+	}
+
+	g.variantMu.Lock()
+	defer g.variantMu.Unlock()
+	if !g.store.NeedsUpdate(key, sourceKey) {
+		return key, true
+	}
+
+	var (
+		img image.Image
+		ext = "png"
+		err error
+	)
+	if parent.SourceKind == "card" {
+		img, err = ComposeCard(p, v.Palette)
+	} else {
+		var src image.Image
+		src, err = g.sourceImage(ctx, p, parent)
+		if err == nil {
+			img, err = ComposeScreenshot(src, p, v.Palette)
+			ext = "jpg"
+		}
+	}
+	if err != nil {
+		log.Warnf("Preview %s variant failed for %s: %v", v.Name, p.ID, err)
+		return "", false
+	}
+
+	encode := encodePNG
+	if ext == "jpg" {
+		encode = encodeJPEG
+	}
+	data, err := encode(img)
+	if err != nil {
+		log.Warnf("Preview %s variant encoding failed for %s: %v", v.Name, p.ID, err)
+		return "", false
+	}
+	if err := g.store.putDerived(key, p.ID, parent.SourceKind, sourceKey, ext, data); err != nil {
+		log.Warnf("Preview store failed for %s: %v", key, err)
+		return "", false
+	}
+	return key, true
+}
+
+// sourceImage loads the image a stored screenshot preview was composed from.
+func (g *Generator) sourceImage(ctx context.Context, p models.Plugin, parent manifestEntry) (image.Image, error) {
+	if path, _, ok := g.store.renditionEntry(sourceImageKey(p.ID), parent.SourceKey); ok {
+		if src, err := decodeFile(path); err == nil {
+			return src, nil
+		}
+	}
+
+	if p.Screenshot == "" || SourceKey(p.Screenshot, p) != parent.SourceKey {
+		return nil, fmt.Errorf("stored preview was not composed from the current screenshot")
+	}
+	src, err := g.fetcher.fetch(ctx, p.Screenshot)
+	if err != nil {
+		return nil, err
+	}
+	g.keepSource(p.ID, parent.SourceKey, src)
+	return src, nil
+}
+
+// keepSource stores the fetched image a screenshot preview was composed from, so
+// palette variants can be composed without fetching it again. It is scaled down to
+// what the image region can show and dropped with the preview.
+func (g *Generator) keepSource(id, sourceKey string, src image.Image) {
+	data, err := encodePNG(regionSource(src))
+	if err == nil {
+		err = g.store.putDerived(sourceImageKey(id), id, "source", sourceKey, "png", data)
+	}
+	if err != nil {
+		log.Warnf("Preview source store failed for %s: %v", id, err)
+	}
+}
+
+// regionSource shrinks src to the most the image region can draw of it, whether it is
+// fitted or covers the region; smaller images are kept as they are.
+func regionSource(src image.Image) image.Image {
+	b := src.Bounds()
+	sw, sh := float64(b.Dx()), float64(b.Dy())
+	scale := math.Max(regionWidth/sw, baseRegionHeight/sh)
+	if scale >= 1 {
+		return src
+	}
+
+	dw := max(int(math.Ceil(sw*scale)), 1)
+	dh := max(int(math.Ceil(sh*scale)), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,101 @@
+package previews
+
+import (
+	"context"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestThemeVariantResolvesModeColors(t *testing.T) {
+	dark, err := ThemeVariant(testTheme, "")
+	if err != nil {
+		t.Fatalf("ThemeVariant: %v", err)
+	}
+	if dark.Name != "theme-test-theme" || rgbOf(dark.Palette.Primary).Hex() != "#3366FF" {
+		t.Fatalf("expected the dark scheme by default, got %+v", dark)
+	}
+
+	light, err := ThemeVariant(testTheme, "light")
+	if err != nil {
+		t.Fatalf("ThemeVariant: %v", err)
+	}
+	if light.Name != "theme-test-theme-light" || rgbOf(light.Palette.Surface).Hex() != "#FAFAFA" {
+		t.Fatalf("expected the light scheme, got %+v", light)
+	}
+	if light.Palette.Description != LightPalette.Description {
+		t.Fatalf("expected tokens the theme leaves out to come from the light palette, got %+v", light.Palette)
+	}
+
+	darkOnly := models.Theme{ID: "dark-only", Dark: testTheme.Dark}
+	if _, err := ThemeVariant(darkOnly, "light"); err == nil {
+		t.Fatal("expected an error for a mode the theme lacks")
+	}
+}
+
+func TestPluginVariantsAreCachedPerPaletteAndDroppedWithThePreview(t *testing.T) {
+	g, err := NewGenerator(t.TempDir(), "https://example.com")
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	p := models.Plugin{ID: "foo", Name: "Foo", Description: "A plugin"}
+	g.syncCard(p)
+	_, sourceETag, _ := g.store.Lookup("foo")
+
+	lightKey, ok := g.PluginVariant(context.Background(), p, LightVariant())
+	if !ok || lightKey != "foo@light" {
+		t.Fatalf("expected the light variant under its own key, got %q", lightKey)
+	}
+	lightPath, lightETag, ok := g.store.Lookup(lightKey)
+	if !ok || lightETag == sourceETag {
+		t.Fatal("expected the variant to have its own etag")
+	}
+	img, err := decodeFile(lightPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixel(t, img, 480, 538, LightPalette.Primary)
+
+	themed, err := ThemeVariant(testTheme, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	themeKey, ok := g.PluginVariant(context.Background(), p, themed)
+	if !ok || themeKey == lightKey {
+		t.Fatalf("expected the theme variant cached separately, got %q", themeKey)
+	}
+	themePath, _, _ := g.store.Lookup(themeKey)
+	img, err = decodeFile(themePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPixel(t, img, 480, 538, color.NRGBA{R: 0x33, G: 0x66, B: 0xFF})
+
+	if again, _ := g.PluginVariant(context.Background(), p, LightVariant()); again != lightKey {
+		t.Fatal("expected the cached variant to be reused")
+	}
+	if _, againETag, _ := g.store.Lookup(lightKey); againETag != lightETag {
+		t.Fatal("expected the cached variant not to be re-rendered")
+	}
+
+	g.syncCard(models.Plugin{ID: "foo", Name: "Bar"})
+	for _, path := range []string{lightPath, themePath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed with its preview", filepath.Base(path))
+		}
+	}
+}
+
+func TestRegionSourceKeepsWhatTheRegionCanShow(t *testing.T) {
+	small := solidImage(400, 200, sourceRed)
+	if regionSource(small) != small {
+		t.Fatal("expected an image smaller than the region to be kept as is")
+	}
+	b := regionSource(solidImage(3840, 2160, sourceRed)).Bounds()
+	if b.Dx() < int(regionWidth) || b.Dy() < int(baseRegionHeight) || b.Dx() >= 3840 {
+		t.Fatalf("expected a source covering the region, got %dx%d", b.Dx(), b.Dy())
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -168,11 +170,8 @@ func (c *Cache) saveToDisk() error {
 // behind a just-applied label due to API eventual consistency).
 func (c *Cache) ApplyStatus(pluginID, status string, add bool) {
 	c.mu.Lock()
-	for i := range c.plugins {
-		if c.plugins[i].ID == pluginID {
-			c.plugins[i].Status = upsertStatus(c.plugins[i].Status, status, add)
-			break
-		}
+	if i := pluginIndex(c.plugins, pluginID); i != -1 {
+		c.plugins[i].Status = upsertStatus(c.plugins[i].Status, status, add)
 	}
 	c.mu.Unlock()
 
@@ -185,11 +184,8 @@ func (c *Cache) ApplyStatus(pluginID, status string, add bool) {
 // action is reflected immediately, without waiting for the next GitHub re-fetch.
 func (c *Cache) ApplySimilar(pluginID, similarID string, add bool) {
 	c.mu.Lock()
-	for i := range c.plugins {
-		if c.plugins[i].ID == pluginID {
-			c.plugins[i].Similar = upsertStatus(c.plugins[i].Similar, similarID, add)
-			break
-		}
+	if i := pluginIndex(c.plugins, pluginID); i != -1 {
+		c.plugins[i].Similar = upsertStatus(c.plugins[i].Similar, similarID, add)
 	}
 	c.mu.Unlock()
 
@@ -198,18 +194,44 @@ func (c *Cache) ApplySimilar(pluginID, similarID string, add bool) {
 	}
 }
 
+// PluginByID looks a plugin up by its current id or any of its former ids, so links
+// and installed clients keep working after a rename. Callers that need to tell the two
+// apart compare the returned plugin's ID with the one they asked for.
 func (c *Cache) PluginByID(id string) (models.Plugin, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for _, plugin := range c.plugins {
-		if plugin.ID == id {
-			return plugin, true
-		}
+	if i := pluginIndex(c.plugins, id); i != -1 {
+		return c.plugins[i], true
 	}
 	return models.Plugin{}, false
 }
 
+// ResolveID maps a current or former plugin id to the current one.
+func (c *Cache) ResolveID(id string) (string, bool) {
+	plugin, ok := c.PluginByID(id)
+	if !ok {
+		return "", false
+	}
+	return plugin.ID, true
+}
+
+// pluginIndex prefers an exact id match over an alias, so a retired name that has
+// since been reused by another plugin resolves to the new owner.
+func pluginIndex(plugins []models.Plugin, id string) int {
+	for i := range plugins {
+		if plugins[i].ID == id {
+			return i
+		}
+	}
+	for i := range plugins {
+		if slices.Contains(plugins[i].Aliases, id) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (c *Cache) PluginByIssue(number int) (models.Plugin, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -239,17 +261,31 @@ func (c *Cache) RepoOwner(pluginID string) (string, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	i := pluginIndex(c.plugins, pluginID)
+	if i == -1 {
+		return "", false
+	}
+	_, owner, _, err := parseRepoURL(c.plugins[i].Repo)
+	if err != nil {
+		return "", false
+	}
+	return owner, true
+}
+
+// PluginsByAuthor returns the plugins whose author, or the owner of whose repo, is
+// handle, ignoring case as GitHub does.
+func (c *Cache) PluginsByAuthor(handle string) []models.Plugin {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var out []models.Plugin
 	for _, plugin := range c.plugins {
-		if plugin.ID != pluginID {
-			continue
-		}
 		_, owner, _, err := parseRepoURL(plugin.Repo)
-		if err != nil {
-			return "", false
+		if strings.EqualFold(plugin.Author, handle) || (err == nil && strings.EqualFold(owner, handle)) {
+			out = append(out, plugin)
 		}
-		return owner, true
 	}
-	return "", false
+	return out
 }
 
 func (c *Cache) IsReady() bool {
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestPluginByIDResolvesAliases(t *testing.T) {
+	c := &Cache{plugins: []models.Plugin{
+		{ID: "worldClock", Aliases: []string{"worldClockMulti"}},
+		{ID: "other"},
+	}}
+
+	plugin, ok := c.PluginByID("worldClockMulti")
+	if !ok || plugin.ID != "worldClock" {
+		t.Fatalf("expected alias to resolve to worldClock, got %+v ok=%v", plugin, ok)
+	}
+	if id, ok := c.ResolveID("other"); !ok || id != "other" {
+		t.Fatalf("expected current id to resolve to itself, got %q ok=%v", id, ok)
+	}
+	if _, ok := c.ResolveID("missing"); ok {
+		t.Fatal("expected unknown id to miss")
+	}
+}
+
+func TestPluginByIDPrefersCurrentIDOverAlias(t *testing.T) {
+	// A retired name reused by a new plugin belongs to the new plugin.
+	c := &Cache{plugins: []models.Plugin{
+		{ID: "renamed", Aliases: []string{"clock"}},
+		{ID: "clock"},
+	}}
+
+	if plugin, _ := c.PluginByID("clock"); plugin.ID != "clock" {
+		t.Fatalf("expected live id to win, got %s", plugin.ID)
+	}
+}
+
+func TestPruneAliasesDropsCollisions(t *testing.T) {
+	plugins := []models.Plugin{
+		{ID: "a", Aliases: []string{"b", "old", ""}},
+		{ID: "b", Aliases: []string{"old", "older"}},
+	}
+
+	pruneAliases(plugins)
+	if len(plugins[0].Aliases) != 1 || plugins[0].Aliases[0] != "old" {
+		t.Fatalf("unexpected aliases for a: %v", plugins[0].Aliases)
+	}
+	if len(plugins[1].Aliases) != 1 || plugins[1].Aliases[0] != "older" {
+		t.Fatalf("unexpected aliases for b: %v", plugins[1].Aliases)
+	}
+}
+
+func TestMergeFeedbackFollowsAliases(t *testing.T) {
+	plugins := []models.Plugin{{ID: "worldClock", Aliases: []string{"worldClockMulti"}}}
+	feedback := map[string]Feedback{"worldClockMulti": {Upvotes: 7, IssueNumber: 530}}
+
+	mergeFeedback(plugins, feedback)
+	if plugins[0].Upvotes != 7 || plugins[0].IssueNumber != 530 {
+		t.Fatalf("expected feedback filed under the old id to apply, got %+v", plugins[0])
+	}
+}
+
+func TestPluginsByAuthorMatchesAuthorOrRepoOwner(t *testing.T) {
+	c := &Cache{plugins: []models.Plugin{
+		{ID: "a", Author: "Someone", Repo: "https://github.com/someone/a"},
+		{ID: "b", Author: "Someone Else", Repo: "https://github.com/SomeOne/b"},
+		{ID: "c", Author: "other", Repo: "https://codeberg.org/other/c"},
+	}}
+
+	got := c.PluginsByAuthor("someone")
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("expected a and b, got %+v", got)
+	}
+	if got := c.PluginsByAuthor("nobody"); len(got) != 0 {
+		t.Fatalf("expected no plugins, got %+v", got)
+	}
+}
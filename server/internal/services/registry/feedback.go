@@ -13,6 +13,7 @@ import (
 const statusLabelPrefix = "status:"
 
 var markerRe = regexp.MustCompile(`<!--\s*dms-plugin-id:\s*([A-Za-z0-9]+)\s*-->`)
+var themeMarkerRe = regexp.MustCompile(`<!--\s*dms-theme-id:\s*([A-Za-z0-9_-]+)\s*-->`)
 var similarRe = regexp.MustCompile(`<!--\s*dms-similar:\s*([^>]*?)\s*-->`)
 
 type Feedback struct {
@@ -25,12 +26,21 @@ type Feedback struct {
 }
 
 func (p *Parser) FetchFeedback(ctx context.Context) (map[string]Feedback, error) {
+	return p.fetchFeedback(ctx, "plugin", markerRe)
+}
+
+// FetchThemeFeedback reads the theme-labeled issues, keyed by their dms-theme-id marker.
+func (p *Parser) FetchThemeFeedback(ctx context.Context) (map[string]Feedback, error) {
+	return p.fetchFeedback(ctx, "theme", themeMarkerRe)
+}
+
+func (p *Parser) fetchFeedback(ctx context.Context, label string, marker *regexp.Regexp) (map[string]Feedback, error) {
 	client, err := p.getClient("github.com")
 	if err != nil {
 		return nil, err
 	}
 
-	issues, err := client.ListIssues(ctx, "AvengeMedia", "dms-plugin-registry", "plugin")
+	issues, err := client.ListIssues(ctx, "AvengeMedia", "dms-plugin-registry", label)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +51,7 @@ func (p *Parser) FetchFeedback(ctx context.Context) (map[string]Feedback, error)
 			continue
 		}
 
-		match := markerRe.FindStringSubmatch(issue.Body)
+		match := marker.FindStringSubmatch(issue.Body)
 		if match == nil {
 			continue
 		}
@@ -61,7 +71,7 @@ func (p *Parser) FetchFeedback(ctx context.Context) (map[string]Feedback, error)
 
 func mergeFeedback(plugins []models.Plugin, feedback map[string]Feedback) {
 	for i := range plugins {
-		fb, ok := feedback[plugins[i].ID]
+		fb, ok := lookupByAlias(feedback, plugins[i])
 		if !ok {
 			continue
 		}
@@ -82,7 +92,7 @@ func carryFeedback(fresh, prev []models.Plugin) {
 		byID[prev[i].ID] = &prev[i]
 	}
 	for i := range fresh {
-		old, ok := byID[fresh[i].ID]
+		old, ok := lookupByAlias(byID, fresh[i])
 		if !ok {
 			continue
 		}
@@ -95,6 +105,52 @@ func carryFeedback(fresh, prev []models.Plugin) {
 	}
 }
 
+func mergeThemeFeedback(themes []models.Theme, feedback map[string]Feedback) {
+	for i := range themes {
+		fb, ok := feedback[themes[i].ID]
+		if !ok {
+			continue
+		}
+		themes[i].Upvotes = fb.Upvotes
+		themes[i].IssueURL = fb.IssueURL
+		themes[i].IssueNumber = fb.IssueNumber
+		themes[i].Status = fb.Status
+	}
+}
+
+// carryThemeFeedback is carryFeedback for themes, which have no aliases to follow.
+func carryThemeFeedback(fresh, prev []models.Theme) {
+	byID := make(map[string]*models.Theme, len(prev))
+	for i := range prev {
+		byID[prev[i].ID] = &prev[i]
+	}
+	for i := range fresh {
+		old, ok := byID[fresh[i].ID]
+		if !ok {
+			continue
+		}
+		fresh[i].Upvotes = old.Upvotes
+		fresh[i].IssueURL = old.IssueURL
+		fresh[i].IssueNumber = old.IssueNumber
+		fresh[i].Status = old.Status
+	}
+}
+
+// lookupByAlias finds a plugin's entry under its current id, falling back to its former
+// ids: feedback issues keep the marker they were opened with when a plugin is renamed.
+func lookupByAlias[V any](entries map[string]V, plugin models.Plugin) (V, bool) {
+	if v, ok := entries[plugin.ID]; ok {
+		return v, true
+	}
+	for _, alias := range plugin.Aliases {
+		if v, ok := entries[alias]; ok {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
 // extractSimilar reads the moderator-managed `dms-similar` marker, whose payload is a
 // comma-separated list of `id=issueNumber` pairs, and returns the related plugin ids.
 func extractSimilar(body string) []string {
@@ -3,9 +3,11 @@ package registry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/integrations/github"
@@ -14,19 +16,64 @@ import (
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 )
 
+// releaseTTL is how long a repo's latest release is reused before it is asked for
+// again. The registry refreshes every few minutes and plugins release far less often,
+// so this keeps a refresh from costing an API call per plugin.
+const releaseTTL = time.Hour
+
 type Parser struct {
-	token   string
-	clients map[string]*github.Client
-	gitlab  *gitlab.Client
+	token     string
+	clients   map[string]*github.Client
+	gitlab    *gitlab.Client
+	releaseMu sync.Mutex
+	releases  map[string]cachedRelease
+
+	// submitters holds who added each theme directory, which never changes once found.
+	submitterMu sync.Mutex
+	submitters  map[string]string
+}
+
+// cachedRelease is a repo's latest release as last fetched, nil for a repo without
+// releases.
+type cachedRelease struct {
+	release   *models.Release
+	fetchedAt time.Time
 }
 
 func NewParser(token string) *Parser {
 	return &Parser{
-		token:   token,
-		clients: make(map[string]*github.Client),
+		token:      token,
+		clients:    make(map[string]*github.Client),
+		releases:   make(map[string]cachedRelease),
+		submitters: make(map[string]string),
 	}
 }
 
+// latestRelease returns the latest release of the repo at host/owner/repo, calling
+// fetch only once the one held is older than releaseTTL. The latest release is the
+// same whichever ref a plugin pins, so plugins sharing a repo share the entry. When
+// fetch fails the release held before is returned along with the error.
+func (p *Parser) latestRelease(host, owner, repo string, fetch func() (*models.Release, error)) (*models.Release, error) {
+	key := strings.ToLower(host + "/" + owner + "/" + repo)
+
+	p.releaseMu.Lock()
+	cached, ok := p.releases[key]
+	p.releaseMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < releaseTTL {
+		return cached.release, nil
+	}
+
+	release, err := fetch()
+	if err != nil {
+		return cached.release, err
+	}
+
+	p.releaseMu.Lock()
+	p.releases[key] = cachedRelease{release: release, fetchedAt: time.Now()}
+	p.releaseMu.Unlock()
+	return release, nil
+}
+
 func (p *Parser) getGitLabClient() *gitlab.Client {
 	if p.gitlab == nil {
 		p.gitlab = gitlab.NewClient("")
@@ -71,11 +118,34 @@ func (p *Parser) FetchPlugins(ctx context.Context) ([]models.Plugin, error) {
 		plugins = append(plugins, plugin)
 	}
 
+	pruneAliases(plugins)
 	p.applyFeedback(ctx, plugins)
 
 	return plugins, nil
 }
 
+// pruneAliases drops aliases that collide with a live plugin id or that an earlier
+// entry already claimed, so an old id always resolves to exactly one plugin.
+func pruneAliases(plugins []models.Plugin) {
+	claimed := make(map[string]bool, len(plugins))
+	for _, plugin := range plugins {
+		claimed[plugin.ID] = true
+	}
+
+	for i := range plugins {
+		var kept []string
+		for _, alias := range plugins[i].Aliases {
+			if alias == "" || claimed[alias] {
+				log.Warnf("Ignoring alias %q on plugin %s: already in use", alias, plugins[i].ID)
+				continue
+			}
+			claimed[alias] = true
+			kept = append(kept, alias)
+		}
+		plugins[i].Aliases = kept
+	}
+}
+
 func (p *Parser) applyFeedback(ctx context.Context, plugins []models.Plugin) {
 	feedback, err := p.FetchFeedback(ctx)
 	if err != nil {
@@ -140,12 +210,37 @@ func (p *Parser) enrichPlugin(ctx context.Context, regPlugin models.RegistryPlug
 		return models.Plugin{}, err
 	}
 
+	release, err := p.latestRelease(host, owner, repo, func() (*models.Release, error) {
+		latest, err := client.GetLatestRelease(ctx, owner, repo)
+		if errors.Is(err, github.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &models.Release{
+			Tag:         latest.TagName,
+			Name:        latest.Name,
+			Notes:       latest.Body,
+			URL:         latest.HTMLURL,
+			PublishedAt: latest.PublishedAt,
+		}, nil
+	})
+	if err != nil {
+		log.Warnf("Failed to fetch latest release for %s: %v", regPlugin.ID, err)
+	}
+
+	ref, err := pluginRef(regPlugin, release)
+	if err != nil {
+		return models.Plugin{}, err
+	}
+
 	metadataPath := "plugin.json"
 	if regPlugin.Path != "" {
 		metadataPath = regPlugin.Path + "/plugin.json"
 	}
 
-	contents, err := client.GetRepoContents(ctx, owner, repo, metadataPath)
+	contents, err := client.GetRepoContentsAt(ctx, owner, repo, metadataPath, ref)
 	if err != nil {
 		return models.Plugin{}, fmt.Errorf("plugin.json not found or inaccessible: %w", err)
 	}
@@ -164,24 +259,57 @@ func (p *Parser) enrichPlugin(ctx context.Context, regPlugin models.RegistryPlug
 		return models.Plugin{}, err
 	}
 
-	lastCommit, err := client.GetLastCommit(ctx, owner, repo, regPlugin.Path)
+	lastCommit, err := client.GetLastCommitAt(ctx, owner, repo, regPlugin.Path, ref)
 	if err != nil {
 		return models.Plugin{}, fmt.Errorf("failed to fetch last commit: %w", err)
 	}
 
-	return buildPlugin(regPlugin, metadata, lastCommit.Commit.Committer.Date), nil
+	plugin := buildPlugin(regPlugin, metadata, lastCommit.Commit.Committer.Date)
+	plugin.Ref = ref
+	plugin.CommitSHA = lastCommit.SHA
+	plugin.Release = release
+	return plugin, nil
 }
 
 func (p *Parser) enrichPluginGitLab(ctx context.Context, regPlugin models.RegistryPlugin, owner, repo string) (models.Plugin, error) {
 	client := p.getGitLabClient()
 	project := owner + "/" + repo
 
+	release, err := p.latestRelease("gitlab.com", owner, repo, func() (*models.Release, error) {
+		latest, err := client.GetLatestRelease(ctx, project)
+		if errors.Is(err, gitlab.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &models.Release{
+			Tag:         latest.TagName,
+			Name:        latest.Name,
+			Notes:       latest.Description,
+			URL:         latest.Links.Self,
+			PublishedAt: latest.ReleasedAt,
+		}, nil
+	})
+	if err != nil {
+		log.Warnf("Failed to fetch latest release for %s: %v", regPlugin.ID, err)
+	}
+
+	ref, err := pluginRef(regPlugin, release)
+	if err != nil {
+		return models.Plugin{}, err
+	}
+
 	filePath := "plugin.json"
 	if regPlugin.Path != "" {
 		filePath = regPlugin.Path + "/plugin.json"
 	}
 
-	fileData, err := client.GetRawFile(ctx, project, filePath, "HEAD")
+	rawRef := ref
+	if rawRef == "" {
+		rawRef = "HEAD"
+	}
+	fileData, err := client.GetRawFile(ctx, project, filePath, rawRef)
 	if err != nil {
 		return models.Plugin{}, fmt.Errorf("plugin.json not found or inaccessible: %w", err)
 	}
@@ -191,12 +319,32 @@ func (p *Parser) enrichPluginGitLab(ctx context.Context, regPlugin models.Regist
 		return models.Plugin{}, err
 	}
 
-	updatedAt, err := client.GetLastCommitDate(ctx, project, regPlugin.Path)
+	lastCommit, err := client.GetLastCommit(ctx, project, regPlugin.Path, ref)
 	if err != nil {
 		return models.Plugin{}, fmt.Errorf("failed to fetch last commit: %w", err)
 	}
 
-	return buildPlugin(regPlugin, metadata, updatedAt), nil
+	plugin := buildPlugin(regPlugin, metadata, lastCommit.CommittedDate)
+	plugin.Ref = ref
+	plugin.CommitSHA = lastCommit.ID
+	plugin.Release = release
+	return plugin, nil
+}
+
+// pluginRef picks the ref a plugin's metadata is read from: an explicit ref wins, the
+// latest-release mode pins to the newest release tag, and otherwise the default branch
+// (empty ref) is used.
+func pluginRef(regPlugin models.RegistryPlugin, release *models.Release) (string, error) {
+	if regPlugin.Ref != "" {
+		return regPlugin.Ref, nil
+	}
+	if !regPlugin.LatestRelease {
+		return "", nil
+	}
+	if release == nil || release.Tag == "" {
+		return "", fmt.Errorf("latestRelease is set but the repository has no releases")
+	}
+	return release.Tag, nil
 }
 
 func parseMetadata(data []byte) (models.PluginMetadata, error) {
@@ -215,6 +363,7 @@ func parseMetadata(data []byte) (models.PluginMetadata, error) {
 func buildPlugin(regPlugin models.RegistryPlugin, metadata models.PluginMetadata, updatedAt time.Time) models.Plugin {
 	plugin := models.Plugin{
 		ID:           regPlugin.ID,
+		Aliases:      regPlugin.Aliases,
 		Name:         regPlugin.Name,
 		Capabilities: regPlugin.Capabilities,
 		Category:     regPlugin.Category,
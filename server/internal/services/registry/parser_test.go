@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestPluginRef(t *testing.T) {
+	release := &models.Release{Tag: "v1.2.0"}
+
+	cases := []struct {
+		name    string
+		reg     models.RegistryPlugin
+		release *models.Release
+		want    string
+		wantErr bool
+	}{
+		{"default branch", models.RegistryPlugin{}, release, "", false},
+		{"explicit ref", models.RegistryPlugin{Ref: "stable"}, release, "stable", false},
+		{"explicit ref wins over release", models.RegistryPlugin{Ref: "v1.0.0", LatestRelease: true}, release, "v1.0.0", false},
+		{"latest release", models.RegistryPlugin{LatestRelease: true}, release, "v1.2.0", false},
+		{"latest release without releases", models.RegistryPlugin{LatestRelease: true}, nil, "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := pluginRef(tc.reg, tc.release)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Fatalf("ref = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLatestReleaseIsReusedAcrossRefreshes(t *testing.T) {
+	p := NewParser("")
+	calls := 0
+	fetch := func() (*models.Release, error) {
+		calls++
+		return &models.Release{Tag: "v1.0.0"}, nil
+	}
+
+	for range 3 {
+		release, err := p.latestRelease("github.com", "Owner", "repo", fetch)
+		if err != nil || release == nil || release.Tag != "v1.0.0" {
+			t.Fatalf("unexpected release %+v, err %v", release, err)
+		}
+	}
+	// Another plugin in the same repo, spelled in another case, shares the entry.
+	p.latestRelease("github.com", "owner", "Repo", fetch)
+	if calls != 1 {
+		t.Fatalf("expected one fetch within the TTL, got %d", calls)
+	}
+
+	key := "github.com/owner/repo"
+	p.releases[key] = cachedRelease{release: p.releases[key].release, fetchedAt: time.Now().Add(-releaseTTL)}
+	failing := func() (*models.Release, error) {
+		calls++
+		return nil, errors.New("rate limited")
+	}
+	release, err := p.latestRelease("github.com", "owner", "repo", failing)
+	if err == nil || release == nil || release.Tag != "v1.0.0" {
+		t.Fatalf("expected the held release alongside the error, got %+v, %v", release, err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected an expired entry to be fetched again, got %d fetches", calls)
+	}
+}
+
+func TestLatestReleaseHoldsReposWithoutReleases(t *testing.T) {
+	p := NewParser("")
+	calls := 0
+	none := func() (*models.Release, error) {
+		calls++
+		return nil, nil
+	}
+	p.latestRelease("gitlab.com", "owner", "repo", none)
+	if release, _ := p.latestRelease("gitlab.com", "owner", "repo", none); release != nil || calls != 1 {
+		t.Fatalf("expected a repo without releases to be remembered, got %+v after %d fetches", release, calls)
+	}
+}
@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// APCA 0.0.98G-4g constants, the version the WCAG 3 working draft references.
+// https://github.com/Myndex/apca-w3
+const (
+	apcaMainTRC     = 2.4
+	apcaNormBG      = 0.56
+	apcaNormTXT     = 0.57
+	apcaRevTXT      = 0.62
+	apcaRevBG       = 0.65
+	apcaBlkThrs     = 0.022
+	apcaBlkClmp     = 1.414
+	apcaScale       = 1.14
+	apcaLoOffset    = 0.027
+	apcaLoClip      = 0.1
+	apcaDeltaYMin   = 0.0005
+	apcaRedCoeff    = 0.2126729
+	apcaGreenCoeff  = 0.7151522
+	apcaBlueCoeff   = 0.0721750
+	apcaMaxLcFactor = 100
+)
+
+// apcaLevels are the APCA-W3 "bronze simple mode" thresholds, highest first, with the
+// smallest normal and bold font sizes (CSS px) each Lc supports for reading text.
+var apcaLevels = []struct {
+	name   string
+	minLc  float64
+	fontPx int
+	boldPx int
+}{
+	{"preferred", 90, 18, 14},
+	{"body", 75, 24, 18},
+	{"content", 60, 24, 16},
+	{"large", 45, 36, 24},
+	// Placeholder and disabled text only; not for anything meant to be read.
+	{"spot", 30, 0, 0},
+}
+
+var apcaLevelRank = map[string]int{"fail": 0, "spot": 1, "large": 2, "content": 3, "body": 4, "preferred": 5}
+
+// apcaY is APCA's screen luminance: a plain 2.4 gamma rather than the sRGB piecewise
+// curve, with a soft clamp lifting near-black so dark pairs are not overrated.
+func apcaY(c wcagRGB) float64 {
+	y := apcaRedCoeff*math.Pow(c.r/255, apcaMainTRC) +
+		apcaGreenCoeff*math.Pow(c.g/255, apcaMainTRC) +
+		apcaBlueCoeff*math.Pow(c.b/255, apcaMainTRC)
+	if y < apcaBlkThrs {
+		y += math.Pow(apcaBlkThrs-y, apcaBlkClmp)
+	}
+	return y
+}
+
+// apcaContrast returns Lc for text on bg. It is signed: positive for dark text on a
+// light background, negative for light text on dark, which is how DMS themes mostly
+// run. Levels compare the magnitude.
+func apcaContrast(text, bg wcagRGB) float64 {
+	yText, yBg := apcaY(text), apcaY(bg)
+	if math.Abs(yBg-yText) < apcaDeltaYMin {
+		return 0
+	}
+
+	if yBg > yText {
+		sapc := (math.Pow(yBg, apcaNormBG) - math.Pow(yText, apcaNormTXT)) * apcaScale
+		if sapc < apcaLoClip {
+			return 0
+		}
+		return (sapc - apcaLoOffset) * apcaMaxLcFactor
+	}
+
+	sapc := (math.Pow(yBg, apcaRevBG) - math.Pow(yText, apcaRevTXT)) * apcaScale
+	if sapc > -apcaLoClip {
+		return 0
+	}
+	return (sapc + apcaLoOffset) * apcaMaxLcFactor
+}
+
+func apcaLevel(lc float64) string {
+	lc = math.Abs(lc)
+	for _, level := range apcaLevels {
+		if lc >= level.minLc {
+			return level.name
+		}
+	}
+	return "fail"
+}
+
+func (r *WCAGRules) worstLc(scheme map[string]interface{}, pairs [][2]string) (float64, []string) {
+	minLc := math.Inf(1)
+	var worstPair []string
+	for _, pair := range pairs {
+		text, bg, ok := r.schemePair(scheme, pair)
+		if !ok {
+			continue
+		}
+
+		lc := math.Abs(apcaContrast(text, bg))
+		if lc >= minLc {
+			continue
+		}
+		minLc = lc
+		worstPair = []string{pair[0], pair[1]}
+	}
+	return minLc, worstPair
+}
+
+func (r *WCAGRules) groupAPCA(scheme map[string]interface{}, pairs [][2]string) *models.ThemeAPCAGroup {
+	lc, pair := r.worstLc(scheme, pairs)
+	if pair == nil {
+		return nil
+	}
+
+	group := &models.ThemeAPCAGroup{
+		Level:     apcaLevel(lc),
+		MinLc:     math.Round(lc*10) / 10,
+		WorstPair: pair,
+	}
+	for _, level := range apcaLevels {
+		if lc >= level.minLc && level.fontPx > 0 {
+			group.MinFontPx = level.fontPx
+			group.MinBoldFontPx = level.boldPx
+			break
+		}
+	}
+	return group
+}
+
+// schemeAPCA scores the same pairs schemeWCAG does. Status colors are left out: APCA
+// has no separate non-text rule yet beyond the spot level every group already reports.
+func (r *WCAGRules) schemeAPCA(scheme map[string]interface{}) *models.ThemeAPCAMode {
+	lc, pair := r.worstLc(scheme, r.textPairs())
+	if pair == nil {
+		return nil
+	}
+
+	return &models.ThemeAPCAMode{
+		Level:     apcaLevel(lc),
+		MinLc:     math.Round(lc*10) / 10,
+		WorstPair: pair,
+		Body:      r.groupAPCA(scheme, r.Body),
+		Accent:    r.groupAPCA(scheme, r.Accent),
+	}
+}
+
+// themeAPCA rolls the per-mode headlines up the way computeThemeWCAG does: the theme
+// is only as good as its weaker mode.
+func themeAPCA(modes ...*models.ThemeWCAGMode) *models.ThemeAPCA {
+	var summary *models.ThemeAPCA
+	for _, mode := range modes {
+		if mode == nil || mode.APCA == nil {
+			continue
+		}
+		if summary == nil || mode.APCA.MinLc < summary.MinLc {
+			summary = &models.ThemeAPCA{Level: mode.APCA.Level, MinLc: mode.APCA.MinLc}
+		}
+	}
+	return summary
+}
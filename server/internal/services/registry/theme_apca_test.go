@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestAPCAContrastReferenceValues(t *testing.T) {
+	// Reference values from the apca-w3 test suite.
+	tests := []struct {
+		text, bg string
+		lc       float64
+	}{
+		{"#888888", "#FFFFFF", 63.056},
+		{"#FFFFFF", "#888888", -68.541},
+		{"#000000", "#AAAAAA", 58.146},
+		{"#AAAAAA", "#000000", -56.242},
+		{"#112233", "#DDEEFF", 91.667},
+		{"#DDEEFF", "#112233", -93.069},
+	}
+	for _, tt := range tests {
+		text := mustSchemeColor(t, tt.text)
+		bg := mustSchemeColor(t, tt.bg)
+		if got := apcaContrast(text, bg); math.Abs(got-tt.lc) > 0.01 {
+			t.Fatalf("%s on %s: expected %.3f, got %.3f", tt.text, tt.bg, tt.lc, got)
+		}
+	}
+
+	same := mustSchemeColor(t, "#777777")
+	if got := apcaContrast(same, same); got != 0 {
+		t.Fatalf("identical colors should score 0, got %f", got)
+	}
+}
+
+func TestAPCALevels(t *testing.T) {
+	for lc, expected := range map[float64]string{
+		95: "preferred", -90: "preferred", 80: "body", -62: "content", 50: "large", 31: "spot", 12: "fail",
+	} {
+		if got := apcaLevel(lc); got != expected {
+			t.Fatalf("Lc %v: expected %s, got %s", lc, expected, got)
+		}
+	}
+}
+
+func TestComputeThemeWCAGIncludesAPCA(t *testing.T) {
+	theme := &models.Theme{
+		Dark: map[string]interface{}{
+			"surface":            "#1E1E2E",
+			"surfaceContainer":   "#313244",
+			"surfaceText":        "#CDD6F4",
+			"surfaceVariantText": "#A6ADC8",
+			"primary":            "#CBA6F7",
+			"primaryText":        "#11111B",
+		},
+		Light: map[string]interface{}{
+			"surface":            "#EFF1F5",
+			"surfaceContainer":   "#E6E9EF",
+			"surfaceText":        "#4C4F69",
+			"surfaceVariantText": "#8C8FA1",
+			"primary":            "#8839EF",
+			"primaryText":        "#EFF1F5",
+		},
+	}
+
+	wcag := computeThemeWCAG(theme)
+	if wcag.Dark.APCA == nil || wcag.Light.APCA == nil || wcag.APCA == nil {
+		t.Fatalf("expected APCA reports, got %+v", wcag)
+	}
+	body := wcag.Light.APCA.Body
+	if body.WorstPair[0] != "surfaceVariantText" || body.MinLc >= 60 {
+		t.Fatalf("expected the light variant text to be the weak pair, got %+v", body)
+	}
+	if wcag.APCA.MinLc != math.Min(wcag.Dark.APCA.MinLc, wcag.Light.APCA.MinLc) {
+		t.Fatalf("summary should take the weaker mode, got %+v", wcag.APCA)
+	}
+	if group := wcag.Dark.APCA.Body; group.MinFontPx == 0 && group.Level != "spot" && group.Level != "fail" {
+		t.Fatalf("text levels should carry a minimum font size, got %+v", group)
+	}
+}
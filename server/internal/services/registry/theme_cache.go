@@ -7,6 +7,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,10 @@ import (
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 )
 
+type ThemePreviewSyncer interface {
+	SyncThemes(ctx context.Context, themes []models.Theme) []models.Theme
+}
+
 type ThemeCache struct {
 	mu          sync.RWMutex
 	themes      []models.Theme
@@ -21,6 +27,10 @@ type ThemeCache struct {
 	lastUpdate  time.Time
 	ready       bool
 	persistPath string
+	previews    ThemePreviewSyncer
+	similarity  *themeSimilarity
+	history     map[string][]models.ThemeRevision
+	historyPath string
 }
 
 type themeSnapshot struct {
@@ -29,11 +39,20 @@ type themeSnapshot struct {
 }
 
 func NewThemeCache(githubToken, persistPath string) *ThemeCache {
-	return &ThemeCache{
+	c := &ThemeCache{
 		themes:      []models.Theme{},
 		parser:      NewParser(githubToken),
 		persistPath: persistPath,
+		history:     map[string][]models.ThemeRevision{},
+	}
+	if persistPath != "" {
+		c.historyPath = strings.TrimSuffix(persistPath, filepath.Ext(persistPath)) + "-history.json"
 	}
+	return c
+}
+
+func (c *ThemeCache) SetPreviewSyncer(s ThemePreviewSyncer) {
+	c.previews = s
 }
 
 func (c *ThemeCache) Initialize(ctx context.Context) error {
@@ -46,6 +65,9 @@ func (c *ThemeCache) Initialize(ctx context.Context) error {
 		} else if !errors.Is(err, fs.ErrNotExist) {
 			log.Warn("Failed to load theme cache from disk", "err", err)
 		}
+		if err := c.loadHistory(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Warn("Failed to load theme history from disk", "err", err)
+		}
 	}
 	return c.Refresh(ctx)
 }
@@ -58,20 +80,54 @@ func (c *ThemeCache) Refresh(ctx context.Context) error {
 		return err
 	}
 
+	c.mu.Lock()
+	carryThemeFeedback(themes, c.themes)
+	c.mu.Unlock()
+
+	if c.previews != nil {
+		themes = c.previews.SyncThemes(ctx, themes)
+	}
+	similarity := buildThemeSimilarity(themes)
+
 	c.mu.Lock()
 	c.themes = themes
+	c.similarity = similarity
 	c.lastUpdate = time.Now()
 	c.ready = true
+	recorded := recordThemeRevisions(c.history, themes, c.lastUpdate)
 	c.mu.Unlock()
 
 	if err := c.saveToDisk(); err != nil {
 		log.Warn("Failed to persist theme cache", "err", err)
 	}
+	if recorded {
+		if err := c.saveHistory(); err != nil {
+			log.Warn("Failed to persist theme history", "err", err)
+		}
+	}
 
 	log.Infof("Theme cache refreshed with %d themes", len(themes))
 	return nil
 }
 
+func (c *ThemeCache) RefreshFeedback(ctx context.Context) error {
+	feedback, err := c.parser.FetchThemeFeedback(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	mergeThemeFeedback(c.themes, feedback)
+	c.mu.Unlock()
+
+	if err := c.saveToDisk(); err != nil {
+		log.Warn("Failed to persist theme cache", "err", err)
+	}
+
+	log.Info("Theme feedback refreshed")
+	return nil
+}
+
 func (c *ThemeCache) loadFromDisk() error {
 	data, err := os.ReadFile(c.persistPath)
 	if err != nil {
@@ -81,8 +137,11 @@ func (c *ThemeCache) loadFromDisk() error {
 	if err := json.Unmarshal(data, &snap); err != nil {
 		return err
 	}
+	similarity := buildThemeSimilarity(snap.Themes)
+
 	c.mu.Lock()
 	c.themes = snap.Themes
+	c.similarity = similarity
 	c.lastUpdate = snap.LastUpdate
 	c.ready = true
 	c.mu.Unlock()
@@ -114,6 +173,54 @@ func (c *ThemeCache) saveToDisk() error {
 	return os.Rename(tmp, c.persistPath)
 }
 
+func (c *ThemeCache) loadHistory() error {
+	data, err := os.ReadFile(c.historyPath)
+	if err != nil {
+		return err
+	}
+	var history map[string][]models.ThemeRevision
+	if err := json.Unmarshal(data, &history); err != nil {
+		return err
+	}
+	if history == nil {
+		history = map[string][]models.ThemeRevision{}
+	}
+	c.mu.Lock()
+	c.history = history
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *ThemeCache) saveHistory() error {
+	if c.historyPath == "" {
+		return nil
+	}
+	c.mu.RLock()
+	data, err := json.Marshal(c.history)
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := c.historyPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.historyPath)
+}
+
+// ThemeHistory returns a theme's recorded revisions, oldest first.
+func (c *ThemeCache) ThemeHistory(id string) ([]models.ThemeRevision, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	revisions, ok := c.history[id]
+	if !ok {
+		return nil, false
+	}
+	return append([]models.ThemeRevision{}, revisions...), true
+}
+
 func (c *ThemeCache) IsReady() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -129,8 +236,246 @@ func (c *ThemeCache) GetThemes() []models.Theme {
 	return themesCopy
 }
 
+func (c *ThemeCache) ThemeByID(id string) (models.Theme, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, theme := range c.themes {
+		if theme.ID == id {
+			return theme, true
+		}
+	}
+	return models.Theme{}, false
+}
+
+// SimilarThemes returns the themes whose palettes come perceptually nearest any
+// configuration of the given one, nearest first, from the index built on refresh.
+func (c *ThemeCache) SimilarThemes(id string, limit int) ([]models.SimilarTheme, bool) {
+	c.mu.RLock()
+	similarity := c.similarity
+	c.mu.RUnlock()
+
+	if similarity == nil {
+		return nil, false
+	}
+	neighbours, ok := similarity.neighbours[id]
+	if !ok {
+		return nil, false
+	}
+	return append([]models.SimilarTheme{}, neighbours[:min(limit, len(neighbours))]...), true
+}
+
+// SimilarToScheme ranks the indexed themes against one resolved scheme, leaving out
+// excludeID, for a client asking about a single flavor or mode.
+func (c *ThemeCache) SimilarToScheme(scheme map[string]interface{}, excludeID string, limit int) []models.SimilarTheme {
+	c.mu.RLock()
+	similarity := c.similarity
+	c.mu.RUnlock()
+
+	if similarity == nil {
+		return []models.SimilarTheme{}
+	}
+	source := []paletteEmbedding{embedScheme(scheme, "")}
+	matches := similarity.nearest(source, excludeID, min(limit, similarNeighbours))
+	if matches == nil {
+		matches = []models.SimilarTheme{}
+	}
+	return matches
+}
+
+// ApplyStatus updates a theme's status labels in place so a moderation command is
+// reflected immediately, without waiting for the next GitHub re-fetch.
+func (c *ThemeCache) ApplyStatus(themeID, status string, add bool) {
+	c.mu.Lock()
+	for i := range c.themes {
+		if c.themes[i].ID == themeID {
+			c.themes[i].Status = upsertStatus(c.themes[i].Status, status, add)
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	if err := c.saveToDisk(); err != nil {
+		log.Warn("Failed to persist theme cache after status update", "err", err)
+	}
+}
+
+// ThemeSubmitter returns the GitHub login that added a theme to the registry. The
+// author theme.json names is free text and is never taken as an account.
+func (c *ThemeCache) ThemeSubmitter(themeID string) (string, bool) {
+	theme, ok := c.ThemeByID(themeID)
+	if !ok || theme.Submitter == "" {
+		return "", false
+	}
+	return theme.Submitter, true
+}
+
+// ThemesByAuthor returns the themes whose theme.json names handle as author, ignoring
+// case.
+func (c *ThemeCache) ThemesByAuthor(handle string) []models.Theme {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var out []models.Theme
+	for _, theme := range c.themes {
+		if strings.EqualFold(theme.Author, handle) {
+			out = append(out, theme)
+		}
+	}
+	return out
+}
+
 func (c *ThemeCache) GetLastUpdate() time.Time {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.lastUpdate
 }
+
+type ThemeFilterOptions struct {
+	// MinLevel is the lowest acceptable WCAG level ("AA" or "AAA"), checked against
+	// LevelMode: "overall" (default), "dark" or "light".
+	MinLevel  string
+	LevelMode string
+	// MinAPCALevel is the lowest acceptable APCA level ("spot" through "preferred"),
+	// checked against the same LevelMode.
+	MinAPCALevel string
+	BothModes    bool
+	VariantType  string
+	Author       string
+	Query        string
+	// Status keeps only themes carrying every listed moderation label; ExcludeStatus
+	// drops themes carrying any of them.
+	Status        []string
+	ExcludeStatus []string
+}
+
+func (c *ThemeCache) FilterThemes(opts ThemeFilterOptions) []models.Theme {
+	themes := c.GetThemes()
+
+	var filtered []models.Theme
+	for i := range themes {
+		if !matchesThemeFilter(&themes[i], opts) {
+			continue
+		}
+		filtered = append(filtered, themes[i])
+	}
+
+	return filtered
+}
+
+func matchesThemeFilter(theme *models.Theme, opts ThemeFilterOptions) bool {
+	if opts.MinLevel != "" && wcagLevelRank[themeLevel(theme, opts.LevelMode)] < wcagLevelRank[opts.MinLevel] {
+		return false
+	}
+
+	if opts.MinAPCALevel != "" && apcaLevelRank[themeAPCALevel(theme, opts.LevelMode)] < apcaLevelRank[opts.MinAPCALevel] {
+		return false
+	}
+
+	if opts.BothModes && !(hasMode(theme, "dark") && hasMode(theme, "light")) {
+		return false
+	}
+
+	if opts.VariantType != "" && themeVariantType(theme) != opts.VariantType {
+		return false
+	}
+
+	if opts.Author != "" && !strings.EqualFold(theme.Author, opts.Author) {
+		return false
+	}
+
+	if opts.Query != "" {
+		query := strings.ToLower(strings.TrimSpace(opts.Query))
+		haystack := strings.ToLower(theme.Name + "\x00" + theme.Description + "\x00" + theme.Author)
+		if !strings.Contains(haystack, query) {
+			return false
+		}
+	}
+
+	for _, required := range opts.Status {
+		if !slices.Contains(theme.Status, required) {
+			return false
+		}
+	}
+
+	for _, excluded := range opts.ExcludeStatus {
+		if slices.Contains(theme.Status, excluded) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// themeLevel reads the computed WCAG level for one mode, or the overall level. A theme
+// without a report for the mode ranks as failing so it never passes a minimum.
+func themeLevel(theme *models.Theme, mode string) string {
+	if theme.WCAG == nil {
+		return "fail"
+	}
+
+	var report *models.ThemeWCAGMode
+	switch mode {
+	case "dark":
+		report = theme.WCAG.Dark
+	case "light":
+		report = theme.WCAG.Light
+	default:
+		return theme.WCAG.Level
+	}
+	if report == nil {
+		return "fail"
+	}
+	return report.Level
+}
+
+// themeAPCALevel is themeLevel for the parallel APCA report.
+func themeAPCALevel(theme *models.Theme, mode string) string {
+	if theme.WCAG == nil {
+		return "fail"
+	}
+
+	var report *models.ThemeWCAGMode
+	switch mode {
+	case "dark":
+		report = theme.WCAG.Dark
+	case "light":
+		report = theme.WCAG.Light
+	default:
+		if theme.WCAG.APCA == nil {
+			return "fail"
+		}
+		return theme.WCAG.APCA.Level
+	}
+	if report == nil || report.APCA == nil {
+		return "fail"
+	}
+	return report.APCA.Level
+}
+
+// ThemeAPCAScore is the theme's weakest |Lc| across modes, for sorting; themes without
+// a report sort last.
+func ThemeAPCAScore(theme *models.Theme) float64 {
+	if theme.WCAG == nil || theme.WCAG.APCA == nil {
+		return -1
+	}
+	return theme.WCAG.APCA.MinLc
+}
+
+func hasMode(theme *models.Theme, mode string) bool {
+	_, err := ResolveScheme(theme, ThemeSelection{Mode: mode})
+	return err == nil
+}
+
+func themeVariantType(theme *models.Theme) string {
+	switch {
+	case theme.Variants == nil:
+		return "none"
+	case theme.Variants.Type == "multi":
+		return "multi"
+	case len(theme.Variants.Options) > 0:
+		return "options"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func filterTestCache() *ThemeCache {
+	themes := []models.Theme{
+		{
+			ID: "accessible", Name: "Accessible Night", Author: "Alice", Description: "High contrast",
+			Status: []string{"reviewed"},
+			Dark:   map[string]interface{}{"surfaceText": "#FFFFFF", "surface": "#000000"},
+			Light:  map[string]interface{}{"surfaceText": "#000000", "surface": "#FFFFFF"},
+		},
+		{
+			ID: "dim", Name: "Dim", Author: "bob", Description: "Low contrast dark theme",
+			Status: []string{"reviewed", "broken"},
+			Dark:   map[string]interface{}{"surfaceText": "#777777", "surface": "#555555"},
+		},
+		{
+			ID: "multi", Name: "Flavors", Author: "alice",
+			Dark: map[string]interface{}{"surfaceText": "#FFFFFF", "surface": "#000000"},
+			Variants: &models.ThemeVariants{
+				Type:    "multi",
+				Flavors: []models.ThemeFlavor{{ID: "a", Dark: map[string]interface{}{}}},
+				Accents: []map[string]interface{}{{"id": "x"}},
+			},
+		},
+	}
+	for i := range themes {
+		themes[i].WCAG = computeThemeWCAG(&themes[i])
+	}
+	return &ThemeCache{themes: themes}
+}
+
+func filteredIDs(c *ThemeCache, opts ThemeFilterOptions) []string {
+	var ids []string
+	for _, theme := range c.FilterThemes(opts) {
+		ids = append(ids, theme.ID)
+	}
+	return ids
+}
+
+func TestFilterThemes(t *testing.T) {
+	c := filterTestCache()
+
+	cases := []struct {
+		name string
+		opts ThemeFilterOptions
+		want []string
+	}{
+		{"no filters", ThemeFilterOptions{}, []string{"accessible", "dim", "multi"}},
+		{"min AAA overall", ThemeFilterOptions{MinLevel: "AAA"}, []string{"accessible", "multi"}},
+		{"min AA light", ThemeFilterOptions{MinLevel: "AA", LevelMode: "light"}, []string{"accessible"}},
+		{"both modes", ThemeFilterOptions{BothModes: true}, []string{"accessible"}},
+		{"multi variants", ThemeFilterOptions{VariantType: "multi"}, []string{"multi"}},
+		{"no variants", ThemeFilterOptions{VariantType: "none"}, []string{"accessible", "dim"}},
+		{"author case-insensitive", ThemeFilterOptions{Author: "ALICE"}, []string{"accessible", "multi"}},
+		{"query description", ThemeFilterOptions{Query: "dark theme"}, []string{"dim"}},
+		{"query author", ThemeFilterOptions{Query: "bob"}, []string{"dim"}},
+		{"min APCA body", ThemeFilterOptions{MinAPCALevel: "body"}, []string{"accessible", "multi"}},
+		{"min APCA spot dark", ThemeFilterOptions{MinAPCALevel: "spot", LevelMode: "dark"}, []string{"accessible", "multi"}},
+		{"status", ThemeFilterOptions{Status: []string{"reviewed"}}, []string{"accessible", "dim"}},
+		{"exclude status", ThemeFilterOptions{ExcludeStatus: []string{"broken"}}, []string{"accessible", "multi"}},
+		{"status and exclude", ThemeFilterOptions{Status: []string{"reviewed"}, ExcludeStatus: []string{"broken"}}, []string{"accessible"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filteredIDs(c, tc.opts)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestThemeFeedbackMergeAndCarry(t *testing.T) {
+	themes := []models.Theme{{ID: "nord-ish"}, {ID: "other"}}
+	mergeThemeFeedback(themes, map[string]Feedback{
+		"nord-ish": {Upvotes: 7, IssueURL: "https://example.com/1", IssueNumber: 1, Status: []string{"reviewed"}},
+	})
+	if themes[0].Upvotes != 7 || themes[0].IssueNumber != 1 || len(themes[0].Status) != 1 {
+		t.Fatalf("expected feedback merged, got %+v", themes[0])
+	}
+	if themes[1].Upvotes != 0 || themes[1].IssueURL != "" {
+		t.Fatalf("expected theme without an issue untouched, got %+v", themes[1])
+	}
+
+	fresh := []models.Theme{{ID: "nord-ish"}}
+	carryThemeFeedback(fresh, themes)
+	if fresh[0].Upvotes != 7 || fresh[0].IssueURL != "https://example.com/1" {
+		t.Fatalf("expected feedback carried across refresh, got %+v", fresh[0])
+	}
+
+	c := &ThemeCache{themes: fresh}
+	c.ApplyStatus("nord-ish", "broken", true)
+	c.ApplyStatus("nord-ish", "reviewed", false)
+	theme, _ := c.ThemeByID("nord-ish")
+	if len(theme.Status) != 1 || theme.Status[0] != "broken" {
+		t.Fatalf("expected status [broken], got %v", theme.Status)
+	}
+}
+
+func TestThemeSubmitterIgnoresAuthorField(t *testing.T) {
+	c := &ThemeCache{themes: []models.Theme{
+		{ID: "claimed", Author: "alice"},
+		{ID: "submitted", Author: "alice", Submitter: "bob"},
+	}}
+
+	if login, ok := c.ThemeSubmitter("claimed"); ok {
+		t.Fatalf("theme.json author was taken as the submitter: %q", login)
+	}
+	if login, ok := c.ThemeSubmitter("submitted"); !ok || login != "bob" {
+		t.Fatalf("expected bob, got %q %v", login, ok)
+	}
+}
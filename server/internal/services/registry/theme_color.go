@@ -0,0 +1,150 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// errUnset marks a token the scheme does not define. Unset tokens are skipped rather
+// than reported, since most themes leave some to DMS defaults.
+var errUnset = errors.New("token not set")
+
+func (c wcagRGB) colorsRGB() colors.RGB {
+	return colors.RGB{R: c.r / 255, G: c.g / 255, B: c.b / 255}
+}
+
+func toWCAGRGB(c colors.RGB) wcagRGB {
+	c = c.Clamp()
+	return wcagRGB{r: c.R * 255, g: c.G * 255, b: c.B * 255}
+}
+
+// SchemeColor reads a scheme's token in any notation colors.ParseColor accepts, and
+// eight-digit hex in the order the scheme's hexAlpha key names.
+func SchemeColor(scheme map[string]interface{}, token string) (colors.RGBA, error) {
+	value := scheme[token]
+	if value == nil {
+		return colors.RGBA{}, errUnset
+	}
+	s, ok := value.(string)
+	if !ok {
+		return colors.RGBA{}, errors.New("not a color string")
+	}
+	order := schemeHexAlpha(scheme)
+	if colors.IsEightDigitHex(s) && order == colors.HexAlphaUnknown {
+		return colors.RGBA{}, errors.New(`eight-digit hex needs "hexAlpha": "argb" or "rgba" in the scheme`)
+	}
+	c, ok := colors.ParseColorHexAlpha(s, order)
+	if !ok {
+		return colors.RGBA{}, errors.New("unrecognized color notation")
+	}
+	return c, nil
+}
+
+// schemeHexAlpha reads where the scheme's eight-digit hex keeps its alpha: "argb" for
+// Qt's #AARRGGBB, "rgba" for CSS's #RRGGBBAA.
+func schemeHexAlpha(scheme map[string]interface{}) colors.HexAlpha {
+	switch scheme["hexAlpha"] {
+	case "argb":
+		return colors.HexAlphaFirst
+	case "rgba":
+		return colors.HexAlphaLast
+	default:
+		return colors.HexAlphaUnknown
+	}
+}
+
+// SchemeOpaqueColor resolves a token to the opaque color on screen, compositing a
+// translucent one down the backdrops of the rules the registry's reports use.
+func SchemeOpaqueColor(scheme map[string]interface{}, token string) (colors.RGB, error) {
+	c, err := activeWCAGRules().schemeBackground(scheme, token)
+	if err != nil {
+		return colors.RGB{}, err
+	}
+	return c.colorsRGB(), nil
+}
+
+// schemeBackground resolves a background token to the opaque color on screen by
+// compositing it down its Backdrops chain.
+func (r *WCAGRules) schemeBackground(scheme map[string]interface{}, token string) (wcagRGB, error) {
+	var layers []colors.RGBA
+	visited := []string{}
+	current := token
+	for {
+		c, err := SchemeColor(scheme, current)
+		switch {
+		case err == errUnset && current != token:
+			return wcagRGB{}, fmt.Errorf("translucent over %s, which is not set", current)
+		case err != nil && current != token:
+			return wcagRGB{}, fmt.Errorf("translucent over %s: %w", current, err)
+		case err != nil:
+			return wcagRGB{}, err
+		}
+		layers = append(layers, c)
+		if c.Opaque() {
+			break
+		}
+
+		visited = append(visited, current)
+		next, ok := r.Backdrops[current]
+		if !ok || slices.Contains(visited, next) {
+			return wcagRGB{}, errors.New("translucent with nothing opaque beneath it")
+		}
+		current = next
+	}
+
+	result := layers[len(layers)-1].RGB
+	for i := len(layers) - 2; i >= 0; i-- {
+		result = layers[i].Over(result)
+	}
+	return toWCAGRGB(result), nil
+}
+
+// schemePair resolves a pair to the colors on screen: the background down its backdrop
+// chain, the foreground composited over that.
+func (r *WCAGRules) schemePair(scheme map[string]interface{}, pair [2]string) (wcagRGB, wcagRGB, bool) {
+	bg, err := r.schemeBackground(scheme, pair[1])
+	if err != nil {
+		return wcagRGB{}, wcagRGB{}, false
+	}
+	fg, err := SchemeColor(scheme, pair[0])
+	if err != nil {
+		return wcagRGB{}, wcagRGB{}, false
+	}
+	return toWCAGRGB(fg.Over(bg.colorsRGB())), bg, true
+}
+
+// unevaluatedTokens lists the tokens a scheme sets for the checked pairs that the
+// report could not use, so a theme is never credited for pairs nobody measured.
+func (r *WCAGRules) unevaluatedTokens(scheme map[string]interface{}) []models.ThemeWCAGUnevaluated {
+	pairs := append(r.textPairs(), r.NonText...)
+	for _, pair := range r.CVD {
+		pairs = append(pairs, [2]string{pair[0], r.CVDBackdrop}, [2]string{pair[1], r.CVDBackdrop})
+	}
+
+	var unevaluated []models.ThemeWCAGUnevaluated
+	seen := map[string]bool{}
+	note := func(token string, err error) {
+		if err == nil || err == errUnset || seen[token] {
+			return
+		}
+		seen[token] = true
+		value, _ := scheme[token].(string)
+		unevaluated = append(unevaluated, models.ThemeWCAGUnevaluated{
+			Token:  token,
+			Value:  value,
+			Reason: err.Error(),
+		})
+	}
+
+	for _, pair := range pairs {
+		_, err := SchemeColor(scheme, pair[0])
+		note(pair[0], err)
+		_, err = r.schemeBackground(scheme, pair[1])
+		note(pair[1], err)
+	}
+	return unevaluated
+}
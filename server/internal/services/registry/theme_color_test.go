@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchemeBackgroundCompositesDownTheChain(t *testing.T) {
+	scheme := map[string]interface{}{
+		"background":       "#000000",
+		"surface":          "rgba(255, 255, 255, 0.5)",
+		"surfaceContainer": "#80FFFFFF",
+		"hexAlpha":         "argb",
+	}
+
+	bg, err := activeWCAGRules().schemeBackground(scheme, "surfaceContainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Half white over (half white over black) is three quarters white.
+	if math.Abs(bg.r-191.25) > 1 || bg.r != bg.g || bg.g != bg.b {
+		t.Fatalf("expected a gray near 191, got %+v", bg)
+	}
+}
+
+func TestSchemeWCAGEvaluatesTranslucentTokens(t *testing.T) {
+	// Translucent surfaces over a dark background that read as AAA if skipped.
+	scheme := map[string]interface{}{
+		"background":       "#000000",
+		"surface":          "#000000",
+		"surfaceContainer": "#E6FFFFFF",
+		"surfaceText":      "#FFFFFF",
+		"hexAlpha":         "argb",
+	}
+
+	report := activeWCAGRules().schemeWCAG(scheme)
+	if report == nil {
+		t.Fatal("expected report, got nil")
+	}
+	if report.Level != "fail" || report.WorstPair[1] != "surfaceContainer" {
+		t.Fatalf("expected white text on a 90%% white container to fail, got %+v", report)
+	}
+	if len(report.Unevaluated) != 0 {
+		t.Fatalf("expected every token to be evaluated, got %+v", report.Unevaluated)
+	}
+}
+
+func TestSchemeWCAGListsUnevaluatedTokens(t *testing.T) {
+	scheme := map[string]interface{}{
+		"surface":          "rgba(0, 0, 0, 0.4)",
+		"surfaceContainer": "#101010",
+		"surfaceText":      "#FFFFFF",
+		"primary":          "hsl(220, 90%, 70%)",
+		"error":            42,
+	}
+
+	report := activeWCAGRules().schemeWCAG(scheme)
+	if report == nil {
+		t.Fatal("expected report, got nil")
+	}
+
+	reasons := map[string]string{}
+	for _, u := range report.Unevaluated {
+		reasons[u.Token] = u.Reason
+	}
+	if reasons["surface"] != "translucent over background, which is not set" {
+		t.Fatalf("expected surface to need a background, got %q", reasons["surface"])
+	}
+	if reasons["primary"] != "unrecognized color notation" {
+		t.Fatalf("expected primary notation to be rejected, got %q", reasons["primary"])
+	}
+	if reasons["error"] != "not a color string" {
+		t.Fatalf("expected error to be rejected, got %q", reasons["error"])
+	}
+	if _, listed := reasons["surfaceContainer"]; listed {
+		t.Fatal("expected the opaque container to be evaluated")
+	}
+	if report.WorstPair[1] != "surfaceContainer" {
+		t.Fatalf("expected only the container pair to be measured, got %v", report.WorstPair)
+	}
+}
+
+func TestSchemeWCAGNothingEvaluable(t *testing.T) {
+	report := activeWCAGRules().schemeWCAG(map[string]interface{}{"surfaceText": "white", "surface": "black"})
+	if report == nil || report.Level != "fail" || len(report.Unevaluated) != 2 {
+		t.Fatalf("expected a failing report listing both tokens, got %+v", report)
+	}
+}
+
+func TestSchemeColorReadsEightDigitHexInTheSchemesOrder(t *testing.T) {
+	// #1e1e2ecc is CSS's 80% mocha base; read alpha-first it would be 12% pink.
+	css := map[string]interface{}{"surface": "#1e1e2ecc", "hexAlpha": "rgba"}
+	c, err := SchemeColor(css, "surface")
+	if err != nil || c.Hex() != "#1E1E2E" || math.Abs(c.A-0.8) > 0.01 {
+		t.Fatalf("expected #1E1E2E at 80%%, got %s at %v, %v", c.Hex(), c.A, err)
+	}
+
+	qt := map[string]interface{}{"surface": "#cc1e1e2e", "hexAlpha": "argb"}
+	if c, err := SchemeColor(qt, "surface"); err != nil || c.Hex() != "#1E1E2E" || math.Abs(c.A-0.8) > 0.01 {
+		t.Fatalf("expected #1E1E2E at 80%%, got %s at %v, %v", c.Hex(), c.A, err)
+	}
+
+	if _, err := SchemeColor(map[string]interface{}{"surface": "#1e1e2ecc"}, "surface"); err == nil {
+		t.Fatal("expected eight-digit hex without hexAlpha to be left unevaluated")
+	}
+}
+
+func TestSchemeWCAGLeavesAmbiguousHexUnevaluated(t *testing.T) {
+	scheme := map[string]interface{}{
+		"background":  "#000000",
+		"surface":     "#1e1e2ecc",
+		"surfaceText": "#FFFFFF",
+	}
+
+	report := activeWCAGRules().schemeWCAG(scheme)
+	if report == nil {
+		t.Fatal("expected report, got nil")
+	}
+	for _, u := range report.Unevaluated {
+		if u.Token == "surface" {
+			return
+		}
+	}
+	t.Fatalf("expected surface to be reported unevaluated, got %+v", report.Unevaluated)
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"math"
+	"sort"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// ContrastMatrix pairs every color token of a flat scheme with every other: rows are the
+// foreground, columns the background, each composited the way schemePair does. Cells
+// are nil where the pairing cannot be evaluated, such as a translucent background with
+// nothing beneath it. Lc keeps APCA's sign, so it differs with direction even where
+// the ratio does not. Checked repeats the pairs these rules grade, with their levels.
+func (r *WCAGRules) ContrastMatrix(scheme map[string]interface{}) *models.ThemeContrastMatrix {
+	tokens := make([]string, 0, len(scheme))
+	for token := range scheme {
+		if _, err := SchemeColor(scheme, token); err == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+
+	matrix := &models.ThemeContrastMatrix{
+		Rules:       r.Version,
+		Tokens:      tokens,
+		Ratios:      make([][]*float64, len(tokens)),
+		Lc:          make([][]*float64, len(tokens)),
+		Checked:     []models.ThemeContrastCheck{},
+		Unevaluated: r.unevaluatedTokens(scheme),
+	}
+	for i, fgToken := range tokens {
+		matrix.Ratios[i] = make([]*float64, len(tokens))
+		matrix.Lc[i] = make([]*float64, len(tokens))
+		for j, bgToken := range tokens {
+			fg, bg, ok := r.schemePair(scheme, [2]string{fgToken, bgToken})
+			if !ok {
+				continue
+			}
+			ratio := roundRatio(contrastRatio(fg, bg))
+			lc := math.Round(apcaContrast(fg, bg)*10) / 10
+			matrix.Ratios[i][j] = &ratio
+			matrix.Lc[i][j] = &lc
+		}
+	}
+
+	for _, group := range r.gradedGroups() {
+		for _, pair := range group.pairs {
+			fg, bg, ok := r.schemePair(scheme, pair)
+			if !ok {
+				continue
+			}
+			ratio := contrastRatio(fg, bg)
+			matrix.Checked = append(matrix.Checked, models.ThemeContrastCheck{
+				Pair:  []string{pair[0], pair[1]},
+				Group: group.name,
+				Ratio: roundRatio(ratio),
+				Lc:    math.Round(apcaContrast(fg, bg)*10) / 10,
+				Level: group.level(ratio),
+			})
+		}
+	}
+	return matrix
+}
+
+// gradedGroup is one group of pairs the rules grade, with how its ratios map to levels.
+type gradedGroup struct {
+	name  string
+	pairs [][2]string
+	level func(float64) string
+}
+
+func (r *WCAGRules) gradedGroups() []gradedGroup {
+	return []gradedGroup{
+		{"body", r.Body, wcagLevel},
+		{"accent", r.Accent, wcagLevel},
+		{"nonText", r.NonText, r.nonTextLevel},
+	}
+}
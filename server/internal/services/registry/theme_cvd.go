@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// cvdMinDistance is the OKLab distance below which a simulated pair is flagged. About
+// three just-noticeable steps: enough to tell two small icons apart at a glance, where
+// a side-by-side swatch comparison would get by with less.
+const cvdMinDistance = 0.06
+
+// schemeCVD simulates each deficiency on every CVD pair the scheme defines and flags
+// those that collapse together.
+func (r *WCAGRules) schemeCVD(scheme map[string]interface{}) *models.ThemeCVDMode {
+	var report *models.ThemeCVDMode
+	for _, pair := range r.CVD {
+		a, ok := r.cvdColor(scheme, pair[0])
+		if !ok {
+			continue
+		}
+		b, ok := r.cvdColor(scheme, pair[1])
+		if !ok {
+			continue
+		}
+		if report == nil {
+			report = &models.ThemeCVDMode{Distinguishable: true, MinDistance: math.Inf(1)}
+		}
+
+		for _, d := range colors.Deficiencies {
+			dist := colors.DeltaE(colors.Simulate(a, d), colors.Simulate(b, d))
+			if dist < report.MinDistance {
+				report.MinDistance = dist
+				report.WorstPair = []string{pair[0], pair[1]}
+				report.WorstDeficiency = string(d)
+			}
+			if dist < cvdMinDistance {
+				report.Distinguishable = false
+				report.Flagged = append(report.Flagged, models.ThemeCVDPair{
+					Pair:       []string{pair[0], pair[1]},
+					Deficiency: string(d),
+					Distance:   roundDistance(dist),
+				})
+			}
+		}
+	}
+	if report != nil {
+		report.MinDistance = roundDistance(report.MinDistance)
+	}
+	return report
+}
+
+// cvdColor reads a token as drawn, compositing it over CVDBackdrop when translucent.
+func (r *WCAGRules) cvdColor(scheme map[string]interface{}, token string) (colors.RGB, bool) {
+	c, err := SchemeColor(scheme, token)
+	if err != nil {
+		return colors.RGB{}, false
+	}
+	if c.Opaque() {
+		return c.RGB, true
+	}
+	bg, err := r.schemeBackground(scheme, r.CVDBackdrop)
+	if err != nil {
+		return colors.RGB{}, false
+	}
+	return c.Over(bg.colorsRGB()), true
+}
+
+// mergeCVD folds the reports of every config in a mode into one, so a flavor whose
+// status colors collide is flagged even when the default flavor is fine. Flagged pairs
+// name the configs they occur in and keep the smallest distance seen.
+func mergeCVD(keys []string, reports map[string]*models.ThemeWCAGMode) *models.ThemeCVDMode {
+	var merged *models.ThemeCVDMode
+	index := map[string]int{}
+	for _, key := range keys {
+		cvd := reports[key].CVD
+		if cvd == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &models.ThemeCVDMode{Distinguishable: true, MinDistance: math.Inf(1)}
+		}
+		if cvd.MinDistance < merged.MinDistance {
+			merged.MinDistance = cvd.MinDistance
+			merged.WorstPair = cvd.WorstPair
+			merged.WorstDeficiency = cvd.WorstDeficiency
+		}
+		merged.Distinguishable = merged.Distinguishable && cvd.Distinguishable
+
+		for _, flagged := range cvd.Flagged {
+			id := flagged.Deficiency + ":" + flagged.Pair[0] + ":" + flagged.Pair[1]
+			i, seen := index[id]
+			if !seen {
+				index[id] = len(merged.Flagged)
+				flagged.Configs = []string{key}
+				merged.Flagged = append(merged.Flagged, flagged)
+				continue
+			}
+			merged.Flagged[i].Configs = append(merged.Flagged[i].Configs, key)
+			merged.Flagged[i].Distance = math.Min(merged.Flagged[i].Distance, flagged.Distance)
+		}
+	}
+	return merged
+}
+
+func roundDistance(d float64) float64 {
+	return math.Round(d*1000) / 1000
+}
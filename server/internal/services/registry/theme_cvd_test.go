@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func TestSchemeCVDFlagsRedGreenStatus(t *testing.T) {
+	scheme := map[string]interface{}{
+		"primary": "#89B4FA",
+		"error":   "#D05A4A",
+		"warning": "#6E8F2E",
+		"info":    "#89DCEB",
+	}
+
+	report := activeWCAGRules().schemeCVD(scheme)
+	if report == nil {
+		t.Fatal("expected report, got nil")
+	}
+	if report.Distinguishable {
+		t.Fatal("expected red and green status colors to be flagged")
+	}
+	if report.WorstDeficiency != "deuteranopia" || report.WorstPair[0] != "error" || report.WorstPair[1] != "warning" {
+		t.Fatalf("expected error/warning under deuteranopia as the worst case, got %+v", report)
+	}
+	for _, flagged := range report.Flagged {
+		if flagged.Deficiency == "tritanopia" {
+			t.Fatalf("red and green stay apart for tritanopes, got %+v", flagged)
+		}
+	}
+}
+
+func TestSchemeCVDPassesCatppuccin(t *testing.T) {
+	scheme := map[string]interface{}{
+		"primary": "#89B4FA",
+		"error":   "#F38BA8",
+		"warning": "#F9E2AF",
+		"info":    "#89DCEB",
+	}
+
+	report := activeWCAGRules().schemeCVD(scheme)
+	if report == nil || !report.Distinguishable {
+		t.Fatalf("expected Mocha status colors to be distinguishable, got %+v", report)
+	}
+	if report.MinDistance < cvdMinDistance {
+		t.Fatalf("expected min distance at least %v, got %v", cvdMinDistance, report.MinDistance)
+	}
+}
+
+func TestSchemeCVDSkipsMissingTokens(t *testing.T) {
+	if report := activeWCAGRules().schemeCVD(map[string]interface{}{"error": "#FF0000"}); report != nil {
+		t.Fatalf("expected no report without a complete pair, got %+v", report)
+	}
+}
+
+func TestModeWCAGMergesCVDAcrossVariants(t *testing.T) {
+	base := map[string]interface{}{
+		"surfaceText": "#FFFFFF",
+		"surface":     "#000000",
+		"primary":     "#89B4FA",
+		"error":       "#F38BA8",
+		"warning":     "#F9E2AF",
+		"info":        "#89DCEB",
+	}
+	theme := &models.Theme{
+		Dark: base,
+		Variants: &models.ThemeVariants{
+			Default: "good",
+			Options: []models.ThemeVariantOption{
+				{ID: "good"},
+				{ID: "clash", Dark: map[string]interface{}{"error": "#D05A4A", "warning": "#6E8F2E"}},
+			},
+		},
+	}
+
+	report := activeWCAGRules().modeWCAG(theme, "dark")
+	if report == nil || report.CVD == nil {
+		t.Fatalf("expected a CVD report, got %+v", report)
+	}
+	if report.CVD.Distinguishable {
+		t.Fatal("expected the clashing variant to flag the mode")
+	}
+	for _, flagged := range report.CVD.Flagged {
+		if len(flagged.Configs) != 1 || flagged.Configs[0] != "clash" {
+			t.Fatalf("expected flags to name only the clash variant, got %+v", flagged)
+		}
+	}
+}
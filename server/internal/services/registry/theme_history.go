@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// maxThemeRevisions caps the history kept per theme; the oldest revisions go first.
+const maxThemeRevisions = 50
+
+// themeRevision snapshots a theme as DMS would apply it: every resolved configuration's
+// token map, so a change to a shared base token shows up in each flavor it reaches.
+func themeRevision(theme *models.Theme, now time.Time) models.ThemeRevision {
+	configs := ThemeConfigs(theme)
+	rev := models.ThemeRevision{
+		Version:    theme.Version,
+		CommitSHA:  theme.CommitSHA,
+		UpdatedAt:  theme.UpdatedAt,
+		RecordedAt: now,
+		Configs:    make([]models.ThemeRevisionConfig, 0, len(configs)),
+		WCAG:       revisionWCAG(theme.WCAG),
+	}
+	for _, config := range configs {
+		rev.Configs = append(rev.Configs, models.ThemeRevisionConfig{
+			Mode:   config.Mode,
+			Label:  config.Label,
+			Colors: config.Colors,
+		})
+	}
+	return rev
+}
+
+func revisionWCAG(report *models.ThemeWCAG) *models.ThemeRevisionWCAG {
+	if report == nil {
+		return nil
+	}
+	summary := func(mode *models.ThemeWCAGMode) *models.ThemeWCAGSummary {
+		if mode == nil {
+			return nil
+		}
+		return &models.ThemeWCAGSummary{Level: mode.Level, MinRatio: mode.MinRatio}
+	}
+	return &models.ThemeRevisionWCAG{
+		Level: report.Level,
+		Rules: report.Rules,
+		Dark:  summary(report.Dark),
+		Light: summary(report.Light),
+	}
+}
+
+// sameRevision ignores the WCAG summary, which also moves when the rules change.
+func sameRevision(a, b *models.ThemeRevision) bool {
+	return a.Version == b.Version && a.CommitSHA == b.CommitSHA && reflect.DeepEqual(a.Configs, b.Configs)
+}
+
+// recordThemeRevisions appends a revision for every theme that differs from its latest
+// one and reports whether anything was added. Themes that leave the registry keep their
+// history in case they come back.
+func recordThemeRevisions(history map[string][]models.ThemeRevision, themes []models.Theme, now time.Time) bool {
+	changed := false
+	for i := range themes {
+		rev := themeRevision(&themes[i], now)
+		revisions := history[themes[i].ID]
+		if n := len(revisions); n > 0 && sameRevision(&revisions[n-1], &rev) {
+			continue
+		}
+		revisions = append(revisions, rev)
+		if len(revisions) > maxThemeRevisions {
+			revisions = revisions[len(revisions)-maxThemeRevisions:]
+		}
+		history[themes[i].ID] = revisions
+		changed = true
+	}
+	return changed
+}
+
+// DiffRevisions turns a theme's revisions into history entries, newest first, each
+// diffed against the one before it. Regressions are graded by these rules rather than
+// the ones the stored summaries were computed with, so every entry is judged alike.
+func (r *WCAGRules) DiffRevisions(revisions []models.ThemeRevision) []models.ThemeHistoryEntry {
+	entries := make([]models.ThemeHistoryEntry, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		rev := &revisions[i]
+		entry := models.ThemeHistoryEntry{
+			Version:     rev.Version,
+			CommitSHA:   rev.CommitSHA,
+			UpdatedAt:   rev.UpdatedAt,
+			RecordedAt:  rev.RecordedAt,
+			WCAG:        rev.WCAG,
+			Changes:     []models.ThemeTokenChange{},
+			Regressions: []models.ThemeContrastRegression{},
+		}
+		if i > 0 {
+			r.diffRevision(&revisions[i-1], rev, &entry)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func revisionConfigKey(config *models.ThemeRevisionConfig) string {
+	return config.Mode + "\x00" + config.Label
+}
+
+func (r *WCAGRules) diffRevision(prev, next *models.ThemeRevision, entry *models.ThemeHistoryEntry) {
+	before := make(map[string]*models.ThemeRevisionConfig, len(prev.Configs))
+	for i := range prev.Configs {
+		before[revisionConfigKey(&prev.Configs[i])] = &prev.Configs[i]
+	}
+	after := make(map[string]bool, len(next.Configs))
+
+	for i := range next.Configs {
+		config := &next.Configs[i]
+		after[revisionConfigKey(config)] = true
+		old, ok := before[revisionConfigKey(config)]
+		if !ok {
+			entry.ConfigsAdded = append(entry.ConfigsAdded, config.Label)
+			continue
+		}
+		entry.Changes = append(entry.Changes, tokenChanges(old, config)...)
+		entry.Regressions = append(entry.Regressions, r.contrastRegressions(old, config)...)
+	}
+	for i := range prev.Configs {
+		if !after[revisionConfigKey(&prev.Configs[i])] {
+			entry.ConfigsRemoved = append(entry.ConfigsRemoved, prev.Configs[i].Label)
+		}
+	}
+
+	entry.Regressed = len(entry.Regressions) > 0 || levelDropped(prev.WCAG, next.WCAG)
+}
+
+func tokenChanges(old, config *models.ThemeRevisionConfig) []models.ThemeTokenChange {
+	tokens := make([]string, 0, len(config.Colors))
+	for token := range old.Colors {
+		tokens = append(tokens, token)
+	}
+	for token := range config.Colors {
+		if _, ok := old.Colors[token]; !ok {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+
+	var changes []models.ThemeTokenChange
+	for _, token := range tokens {
+		from, to := old.Colors[token], config.Colors[token]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		changes = append(changes, models.ThemeTokenChange{
+			Mode:   config.Mode,
+			Config: config.Label,
+			Token:  token,
+			From:   from,
+			To:     to,
+		})
+	}
+	return changes
+}
+
+// contrastRegressions lists the graded pairs whose level dropped. A pair that can no
+// longer be measured is left to the revision's unevaluated tokens rather than counted.
+func (r *WCAGRules) contrastRegressions(old, config *models.ThemeRevisionConfig) []models.ThemeContrastRegression {
+	var regressions []models.ThemeContrastRegression
+	for _, group := range r.gradedGroups() {
+		for _, pair := range group.pairs {
+			oldFg, oldBg, ok := r.schemePair(old.Colors, pair)
+			if !ok {
+				continue
+			}
+			fg, bg, ok := r.schemePair(config.Colors, pair)
+			if !ok {
+				continue
+			}
+
+			fromRatio, toRatio := contrastRatio(oldFg, oldBg), contrastRatio(fg, bg)
+			fromLevel, toLevel := group.level(fromRatio), group.level(toRatio)
+			if wcagLevelRank[toLevel] >= wcagLevelRank[fromLevel] {
+				continue
+			}
+			regressions = append(regressions, models.ThemeContrastRegression{
+				Mode:      config.Mode,
+				Config:    config.Label,
+				Pair:      []string{pair[0], pair[1]},
+				Group:     group.name,
+				FromRatio: roundRatio(fromRatio),
+				ToRatio:   roundRatio(toRatio),
+				FromLevel: fromLevel,
+				ToLevel:   toLevel,
+			})
+		}
+	}
+	return regressions
+}
+
+func levelDropped(prev, next *models.ThemeRevisionWCAG) bool {
+	if prev == nil || next == nil {
+		return false
+	}
+	return wcagLevelRank[next.Level] < wcagLevelRank[prev.Level]
+}
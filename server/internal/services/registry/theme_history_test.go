@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func historyTestTheme(version, commit, container string) models.Theme {
+	theme := models.Theme{
+		ID: "dusk", Version: version, CommitSHA: commit,
+		Dark: map[string]interface{}{
+			"surface":          "#101010",
+			"surfaceContainer": container,
+			"surfaceText":      "#E0E0E0",
+		},
+	}
+	theme.WCAG = computeThemeWCAG(&theme)
+	return theme
+}
+
+func TestRecordThemeRevisionsSkipsUnchanged(t *testing.T) {
+	history := map[string][]models.ThemeRevision{}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	theme := historyTestTheme("1.0.0", "aaa", "#1A1A1A")
+	if !recordThemeRevisions(history, []models.Theme{theme}, start) {
+		t.Fatal("expected the first sighting to be recorded")
+	}
+	if recordThemeRevisions(history, []models.Theme{theme}, start.Add(time.Hour)) {
+		t.Fatal("expected an unchanged theme to be skipped")
+	}
+
+	theme = historyTestTheme("1.0.1", "bbb", "#1A1A1A")
+	if !recordThemeRevisions(history, []models.Theme{theme}, start.Add(2*time.Hour)) {
+		t.Fatal("expected a new commit to be recorded")
+	}
+	if len(history["dusk"]) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(history["dusk"]))
+	}
+}
+
+func TestDiffRevisionsReportsTokenChangesAndRegressions(t *testing.T) {
+	history := map[string][]models.ThemeRevision{}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recordThemeRevisions(history, []models.Theme{historyTestTheme("1.0.0", "aaa", "#1A1A1A")}, start)
+	recordThemeRevisions(history, []models.Theme{historyTestTheme("1.1.0", "bbb", "#8A8A8A")}, start.Add(time.Hour))
+
+	entries := activeWCAGRules().DiffRevisions(history["dusk"])
+	if len(entries) != 2 || entries[0].Version != "1.1.0" {
+		t.Fatalf("expected newest first, got %+v", entries)
+	}
+
+	latest := entries[0]
+	if len(latest.Changes) != 1 || latest.Changes[0].Token != "surfaceContainer" ||
+		latest.Changes[0].From != "#1A1A1A" || latest.Changes[0].To != "#8A8A8A" {
+		t.Fatalf("expected the container change, got %+v", latest.Changes)
+	}
+	if !latest.Regressed || len(latest.Regressions) == 0 {
+		t.Fatalf("expected a contrast regression, got %+v", latest)
+	}
+	r := latest.Regressions[0]
+	if r.Pair[1] != "surfaceContainer" || r.FromLevel != "AAA" || r.ToLevel != "fail" {
+		t.Fatalf("expected text on the container to drop from AAA to fail, got %+v", r)
+	}
+
+	if first := entries[1]; len(first.Changes) != 0 || first.Regressed {
+		t.Fatalf("expected the first revision to have nothing to diff, got %+v", first)
+	}
+}
+
+func TestRecordThemeRevisionsCapsHistory(t *testing.T) {
+	history := map[string][]models.ThemeRevision{}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxThemeRevisions+5; i++ {
+		theme := historyTestTheme("1.0.0", string(rune('a'+i%26))+string(rune('a'+i/26)), "#1A1A1A")
+		recordThemeRevisions(history, []models.Theme{theme}, start.Add(time.Duration(i)*time.Hour))
+	}
+	if len(history["dusk"]) != maxThemeRevisions {
+		t.Fatalf("expected %d revisions, got %d", maxThemeRevisions, len(history["dusk"]))
+	}
+	if history["dusk"][0].RecordedAt != start.Add(5*time.Hour) {
+		t.Fatalf("expected the oldest revisions dropped, got %v", history["dusk"][0].RecordedAt)
+	}
+}
@@ -35,9 +35,20 @@ func (p *Parser) FetchThemes(ctx context.Context) ([]models.Theme, error) {
 		themes = append(themes, theme)
 	}
 
+	p.applyThemeFeedback(ctx, themes)
 	return themes, nil
 }
 
+func (p *Parser) applyThemeFeedback(ctx context.Context, themes []models.Theme) {
+	feedback, err := p.FetchThemeFeedback(ctx)
+	if err != nil {
+		log.Warnf("Failed to fetch theme feedback: %v", err)
+		return
+	}
+
+	mergeThemeFeedback(themes, feedback)
+}
+
 func (p *Parser) fetchTheme(ctx context.Context, themeName string) (models.Theme, error) {
 	client, err := p.getClient("github.com")
 	if err != nil {
@@ -75,8 +86,38 @@ func (p *Parser) fetchTheme(ctx context.Context, themeName string) (models.Theme
 	}
 
 	theme.PreviewURL = fmt.Sprintf("https://raw.githubusercontent.com/AvengeMedia/dms-plugin-registry/main/themes/%s/preview.svg", themeName)
+	theme.CommitSHA = lastCommit.SHA
 	theme.UpdatedAt = lastCommit.Commit.Committer.Date
+	theme.Submitter = p.themeSubmitter(themeName, func() (string, error) {
+		commit, err := client.GetFirstCommit(ctx, "AvengeMedia", "dms-plugin-registry", fmt.Sprintf("themes/%s", themeName))
+		if err != nil {
+			return "", err
+		}
+		if commit.Author == nil {
+			return "", nil
+		}
+		return commit.Author.Login, nil
+	})
 	theme.WCAG = computeThemeWCAG(&theme)
 
 	return theme, nil
 }
+
+// themeSubmitter returns the GitHub login of whoever added the theme directory to the
+// registry, which unlike theme.json's author can't be written in by anyone. It is
+// looked up once per directory; a failed lookup leaves it empty until the next refresh.
+func (p *Parser) themeSubmitter(themeDir string, fetch func() (string, error)) string {
+	p.submitterMu.Lock()
+	defer p.submitterMu.Unlock()
+
+	if login, ok := p.submitters[themeDir]; ok {
+		return login
+	}
+	login, err := fetch()
+	if err != nil {
+		log.Warnf("Failed to find who submitted theme %s: %v", themeDir, err)
+		return ""
+	}
+	p.submitters[themeDir] = login
+	return login
+}
@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// ErrInvalidSelection wraps every way a requested mode, variant, flavor or accent
+// fails to match what a theme offers, so handlers can answer with a client error.
+var ErrInvalidSelection = errors.New("invalid theme selection")
+
+// ThemeSelection names one configuration a user can pick. Empty fields fall back to
+// the theme's declared defaults for the mode, the same config computeThemeWCAG headlines.
+type ThemeSelection struct {
+	Mode    string
+	Variant string
+	Flavor  string
+	Accent  string
+}
+
+type ResolvedScheme struct {
+	Mode    string
+	Variant string
+	Flavor  string
+	Accent  string
+	Colors  map[string]interface{}
+	WCAG    *models.ThemeWCAGMode
+}
+
+// ResolveScheme flattens a theme's base, variant, flavor and accent layers into the
+// token map DMS applies for the selection, using the same merge order as the WCAG pass.
+func ResolveScheme(theme *models.Theme, sel ThemeSelection) (*ResolvedScheme, error) {
+	mode := sel.Mode
+	if mode == "" {
+		mode = "dark"
+	}
+	if _, ok := wcagModeLabels[mode]; !ok {
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidSelection, mode)
+	}
+
+	base := modeColors(theme.Dark, theme.Light, mode)
+	resolved := &ResolvedScheme{Mode: mode}
+
+	variants := theme.Variants
+	switch {
+	case variants != nil && variants.Type == "multi":
+		flavor, accent, err := resolveMulti(variants, sel, mode)
+		if err != nil {
+			return nil, err
+		}
+		accentColors, _ := accent[flavor.ID].(map[string]interface{})
+		resolved.Flavor = flavor.ID
+		resolved.Accent, _ = accent["id"].(string)
+		resolved.Colors = mergeSchemes(base, modeColors(flavor.Dark, flavor.Light, mode), accentColors)
+	case variants != nil && len(variants.Options) > 0:
+		option, err := resolveOption(variants, sel.Variant)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Variant = option.ID
+		resolved.Colors = mergeSchemes(base, modeColors(option.Dark, option.Light, mode))
+	default:
+		resolved.Colors = mergeSchemes(base)
+	}
+
+	if len(resolved.Colors) == 0 {
+		return nil, fmt.Errorf("%w: theme has no %s colors", ErrInvalidSelection, mode)
+	}
+	resolved.WCAG = activeWCAGRules().SchemeWCAG(resolved.Colors)
+	return resolved, nil
+}
+
+func resolveOption(variants *models.ThemeVariants, id string) (*models.ThemeVariantOption, error) {
+	if id == "" {
+		id = variants.Default
+	}
+	for i := range variants.Options {
+		option := &variants.Options[i]
+		if option.ID == "" {
+			continue
+		}
+		if id == "" || option.ID == id {
+			return option, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown variant %q", ErrInvalidSelection, id)
+}
+
+func resolveMulti(variants *models.ThemeVariants, sel ThemeSelection, mode string) (*models.ThemeFlavor, map[string]interface{}, error) {
+	flavorID, accentID := sel.Flavor, sel.Accent
+	if defaults := variants.Defaults[mode]; defaults != nil {
+		if flavorID == "" {
+			flavorID = defaults.Flavor
+		}
+		if accentID == "" {
+			accentID = defaults.Accent
+		}
+	}
+
+	var flavor *models.ThemeFlavor
+	for i := range variants.Flavors {
+		candidate := &variants.Flavors[i]
+		if candidate.ID == "" || modeColors(candidate.Dark, candidate.Light, mode) == nil {
+			continue
+		}
+		if flavorID == "" || candidate.ID == flavorID {
+			flavor = candidate
+			break
+		}
+	}
+	if flavor == nil {
+		return nil, nil, fmt.Errorf("%w: unknown %s flavor %q", ErrInvalidSelection, mode, flavorID)
+	}
+
+	for _, accent := range variants.Accents {
+		id, _ := accent["id"].(string)
+		if id == "" {
+			continue
+		}
+		if accentID == "" || id == accentID {
+			return flavor, accent, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: unknown accent %q", ErrInvalidSelection, accentID)
+}
+
+func modeColors(dark, light map[string]interface{}, mode string) map[string]interface{} {
+	if mode == "light" {
+		return light
+	}
+	return dark
+}
+
+// ThemeConfig is one flavor or variant of a theme, flattened for display.
+type ThemeConfig struct {
+	Mode   string
+	Label  string
+	Colors map[string]interface{}
+}
+
+// ThemeConfigs lists one resolved scheme per variant option or flavor in each mode, in
+// declaration order, dark first. Accents roll into their flavor the same way the WCAG
+// breakdown does; the flavor is shown with the mode's default accent when it has one.
+func ThemeConfigs(theme *models.Theme) []ThemeConfig {
+	var out []ThemeConfig
+	for _, mode := range []string{"dark", "light"} {
+		configs, _ := modeConfigs(theme, mode)
+
+		preferredAccent := ""
+		if theme.Variants != nil && theme.Variants.Defaults[mode] != nil {
+			preferredAccent = theme.Variants.Defaults[mode].Accent
+		}
+
+		chosen := map[string]int{}
+		start := len(out)
+		for _, config := range configs {
+			if len(config.scheme) == 0 {
+				continue
+			}
+			idx, seen := chosen[config.group]
+			if !seen {
+				chosen[config.group] = len(out)
+				out = append(out, ThemeConfig{Mode: mode, Label: config.label, Colors: config.scheme})
+				continue
+			}
+			if preferredAccent != "" && config.key == config.group+"-"+preferredAccent {
+				out[idx].Colors = config.scheme
+			}
+		}
+
+		// Plain and option themes reuse names across modes, so say which one it is.
+		if theme.Variants == nil || theme.Variants.Type != "multi" {
+			for i := start; i < len(out); i++ {
+				if out[i].Label != wcagModeLabels[mode] {
+					out[i].Label += " · " + wcagModeLabels[mode]
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func multiTestTheme() *models.Theme {
+	return &models.Theme{
+		Dark:  map[string]interface{}{"surfaceText": "#FFFFFF", "surface": "#000000"},
+		Light: map[string]interface{}{"surfaceText": "#000000", "surface": "#FFFFFF"},
+		Variants: &models.ThemeVariants{
+			Type: "multi",
+			Defaults: map[string]*models.ThemeModeDefaults{
+				"dark":  {Flavor: "mocha", Accent: "blue"},
+				"light": {Flavor: "latte", Accent: "blue"},
+			},
+			Flavors: []models.ThemeFlavor{
+				{ID: "mocha", Dark: map[string]interface{}{"surface": "#1E1E2E"}},
+				{ID: "latte", Light: map[string]interface{}{"surface": "#EFF1F5"}},
+			},
+			Accents: []map[string]interface{}{
+				{"id": "blue", "mocha": map[string]interface{}{"primary": "#89B4FA"}, "latte": map[string]interface{}{"primary": "#1E66F5"}},
+				{"id": "red", "mocha": map[string]interface{}{"primary": "#F38BA8"}},
+			},
+		},
+	}
+}
+
+func TestResolveSchemeMultiDefaults(t *testing.T) {
+	resolved, err := ResolveScheme(multiTestTheme(), ThemeSelection{})
+	if err != nil {
+		t.Fatalf("ResolveScheme: %v", err)
+	}
+	if resolved.Mode != "dark" || resolved.Flavor != "mocha" || resolved.Accent != "blue" {
+		t.Fatalf("expected dark mocha/blue defaults, got %+v", resolved)
+	}
+	if resolved.Colors["surface"] != "#1E1E2E" || resolved.Colors["primary"] != "#89B4FA" {
+		t.Fatalf("expected flavor and accent layers over base, got %v", resolved.Colors)
+	}
+	if resolved.Colors["surfaceText"] != "#FFFFFF" {
+		t.Fatalf("expected base tokens to carry through, got %v", resolved.Colors)
+	}
+	if resolved.WCAG == nil {
+		t.Fatal("expected a WCAG report for the resolved scheme")
+	}
+}
+
+func TestResolveSchemeMultiExplicit(t *testing.T) {
+	resolved, err := ResolveScheme(multiTestTheme(), ThemeSelection{Mode: "light", Flavor: "latte", Accent: "blue"})
+	if err != nil {
+		t.Fatalf("ResolveScheme: %v", err)
+	}
+	if resolved.Colors["surface"] != "#EFF1F5" || resolved.Colors["primary"] != "#1E66F5" {
+		t.Fatalf("unexpected light colors %v", resolved.Colors)
+	}
+}
+
+func TestResolveSchemeRejectsUnknownSelections(t *testing.T) {
+	cases := []ThemeSelection{
+		{Mode: "dim"},
+		{Flavor: "frappe"},
+		{Accent: "green"},
+		{Mode: "light", Flavor: "mocha"},
+	}
+	for _, sel := range cases {
+		if _, err := ResolveScheme(multiTestTheme(), sel); !errors.Is(err, ErrInvalidSelection) {
+			t.Fatalf("selection %+v: expected ErrInvalidSelection, got %v", sel, err)
+		}
+	}
+}
+
+func TestResolveSchemeOptions(t *testing.T) {
+	theme := &models.Theme{
+		Dark: map[string]interface{}{"surfaceText": "#FFFFFF", "surface": "#000000"},
+		Variants: &models.ThemeVariants{
+			Default: "soft",
+			Options: []models.ThemeVariantOption{
+				{ID: "hard", Dark: map[string]interface{}{"surface": "#101010"}},
+				{ID: "soft", Dark: map[string]interface{}{"surface": "#202020"}},
+			},
+		},
+	}
+
+	resolved, err := ResolveScheme(theme, ThemeSelection{})
+	if err != nil {
+		t.Fatalf("ResolveScheme: %v", err)
+	}
+	if resolved.Variant != "soft" || resolved.Colors["surface"] != "#202020" {
+		t.Fatalf("expected default option soft, got %+v", resolved)
+	}
+
+	if _, err := ResolveScheme(theme, ThemeSelection{Variant: "medium"}); !errors.Is(err, ErrInvalidSelection) {
+		t.Fatalf("expected unknown variant to be rejected, got %v", err)
+	}
+	if _, err := ResolveScheme(theme, ThemeSelection{Mode: "light", Variant: "hard"}); !errors.Is(err, ErrInvalidSelection) {
+		t.Fatalf("expected missing light colors to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package registry
+
+import (
+	"math"
+	"sort"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// paletteTokens are the colors that make a theme look the way it does, weighted by how
+// much of the screen they cover: surfaces and the accent dominate, text and status
+// colors are only seen in small doses.
+var paletteTokens = [...]struct {
+	name   string
+	weight float64
+}{
+	{"surface", 3},
+	{"surfaceContainer", 3},
+	{"surfaceContainerHigh", 1},
+	{"surfaceText", 1.5},
+	{"surfaceVariantText", 1},
+	{"primary", 3},
+	{"secondary", 1.5},
+	{"error", 0.5},
+	{"warning", 0.5},
+	{"info", 0.5},
+}
+
+// paletteMinCoverage is the share of the total token weight two palettes must both set
+// to be compared at all; below it a sparse theme would match on a couple of tokens.
+const paletteMinCoverage = 0.5
+
+// similarNeighbours is how many neighbours each theme keeps precomputed, which caps the
+// endpoint's limit.
+const similarNeighbours = 24
+
+// paletteEmbedding is one resolved configuration of a theme as OKLab coordinates of
+// paletteTokens, as drawn.
+type paletteEmbedding struct {
+	label string
+	lab   [len(paletteTokens)]colors.OKLab
+	set   [len(paletteTokens)]bool
+}
+
+func embedScheme(scheme map[string]interface{}, label string) paletteEmbedding {
+	rules := activeWCAGRules()
+	e := paletteEmbedding{label: label}
+	for i, token := range paletteTokens {
+		// Backgrounds composite down their backdrop chain, everything else over the
+		// container the way status colors are drawn.
+		if bg, err := rules.schemeBackground(scheme, token.name); err == nil {
+			e.lab[i], e.set[i] = bg.colorsRGB().OKLab(), true
+		} else if c, ok := rules.cvdColor(scheme, token.name); ok {
+			e.lab[i], e.set[i] = c.OKLab(), true
+		}
+	}
+	return e
+}
+
+func embedTheme(theme *models.Theme) []paletteEmbedding {
+	configs := ThemeConfigs(theme)
+	embeddings := make([]paletteEmbedding, 0, len(configs))
+	for _, config := range configs {
+		embeddings = append(embeddings, embedScheme(config.Colors, config.Label))
+	}
+	return embeddings
+}
+
+// paletteDistance is the weighted root-mean-square OKLab distance over the tokens both
+// palettes set, so it reads on the same scale as DeltaE.
+func paletteDistance(a, b *paletteEmbedding) (float64, bool) {
+	var sum, weight, total float64
+	for i, token := range paletteTokens {
+		total += token.weight
+		if !a.set[i] || !b.set[i] {
+			continue
+		}
+		dl, da, db := a.lab[i].L-b.lab[i].L, a.lab[i].A-b.lab[i].A, a.lab[i].B-b.lab[i].B
+		sum += token.weight * (dl*dl + da*da + db*db)
+		weight += token.weight
+	}
+	if weight < total*paletteMinCoverage {
+		return 0, false
+	}
+	return math.Sqrt(sum / weight), true
+}
+
+// themeSimilarity holds every theme's embeddings and its nearest neighbours, rebuilt
+// whenever the cache's themes are replaced.
+type themeSimilarity struct {
+	themes     []models.Theme
+	embeddings [][]paletteEmbedding
+	neighbours map[string][]models.SimilarTheme
+}
+
+func buildThemeSimilarity(themes []models.Theme) *themeSimilarity {
+	s := &themeSimilarity{
+		themes:     append([]models.Theme(nil), themes...),
+		embeddings: make([][]paletteEmbedding, len(themes)),
+		neighbours: make(map[string][]models.SimilarTheme, len(themes)),
+	}
+	for i := range themes {
+		s.embeddings[i] = embedTheme(&themes[i])
+	}
+	for i := range themes {
+		s.neighbours[themes[i].ID] = s.nearest(s.embeddings[i], themes[i].ID, similarNeighbours)
+	}
+	return s
+}
+
+// nearest ranks every other theme by its closest configuration to any of source, so a
+// theme whose one flavor matches closely ranks above one that is vaguely near overall.
+func (s *themeSimilarity) nearest(source []paletteEmbedding, excludeID string, limit int) []models.SimilarTheme {
+	var matches []models.SimilarTheme
+	for i := range s.themes {
+		theme := &s.themes[i]
+		if theme.ID == excludeID {
+			continue
+		}
+
+		best := models.SimilarTheme{Distance: math.Inf(1)}
+		for j := range source {
+			for k := range s.embeddings[i] {
+				d, ok := paletteDistance(&source[j], &s.embeddings[i][k])
+				if !ok || d >= best.Distance {
+					continue
+				}
+				best.Distance = d
+				best.Config = s.embeddings[i][k].label
+				best.SourceConfig = source[j].label
+			}
+		}
+		if math.IsInf(best.Distance, 1) {
+			continue
+		}
+
+		best.ID = theme.ID
+		best.Name = theme.Name
+		best.Author = theme.Author
+		best.PreviewURL = theme.PreviewURL
+		best.Distance = roundDistance(best.Distance)
+		matches = append(matches, best)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func similarTestThemes() []models.Theme {
+	mocha := map[string]interface{}{
+		"surface": "#1E1E2E", "surfaceContainer": "#181825", "surfaceText": "#CDD6F4",
+		"primary": "#CBA6F7", "secondary": "#89B4FA", "error": "#F38BA8",
+	}
+	latte := map[string]interface{}{
+		"surface": "#EFF1F5", "surfaceContainer": "#E6E9EF", "surfaceText": "#4C4F69",
+		"primary": "#8839EF", "secondary": "#1E66F5", "error": "#D20F39",
+	}
+	return []models.Theme{
+		{ID: "mocha", Name: "Mocha", Dark: mocha, Light: latte},
+		{ID: "macchiato", Name: "Macchiato", Dark: map[string]interface{}{
+			"surface": "#24273A", "surfaceContainer": "#1E2030", "surfaceText": "#CAD3F5",
+			"primary": "#C6A0F6", "secondary": "#8AADF4", "error": "#ED8796",
+		}},
+		{ID: "paper", Name: "Paper", Light: map[string]interface{}{
+			"surface": "#F4F4F0", "surfaceContainer": "#EAEAE4", "surfaceText": "#333333",
+			"primary": "#7A3FE4", "secondary": "#2A6FE0", "error": "#C82838",
+		}},
+		{ID: "gruvbox", Name: "Gruvbox", Dark: map[string]interface{}{
+			"surface": "#282828", "surfaceContainer": "#1D2021", "surfaceText": "#EBDBB2",
+			"primary": "#FABD2F", "secondary": "#B8BB26", "error": "#FB4934",
+		}},
+		{ID: "sparse", Name: "Sparse", Dark: map[string]interface{}{"error": "#FF0000"}},
+	}
+}
+
+func similarIDs(matches []models.SimilarTheme) []string {
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestThemeSimilarityRanksPerceptualNeighbours(t *testing.T) {
+	c := &ThemeCache{}
+	c.similarity = buildThemeSimilarity(similarTestThemes())
+
+	got, ok := c.SimilarThemes("mocha", 10)
+	if !ok {
+		t.Fatal("expected mocha to be indexed")
+	}
+	ids := similarIDs(got)
+	if len(ids) != 3 || ids[0] != "macchiato" || ids[1] != "paper" || ids[2] != "gruvbox" {
+		t.Fatalf("expected macchiato, paper, gruvbox; sparse has too few tokens to compare; got %v", ids)
+	}
+	if got[0].SourceConfig != "Dark" || got[0].Config != "Dark" {
+		t.Fatalf("expected dark modes to match, got %+v", got[0])
+	}
+	if got[1].SourceConfig != "Light" {
+		t.Fatalf("expected paper to match mocha's light mode, got %+v", got[1])
+	}
+
+	if limited, _ := c.SimilarThemes("mocha", 1); len(limited) != 1 {
+		t.Fatalf("expected limit to apply, got %d", len(limited))
+	}
+	if _, ok := c.SimilarThemes("missing", 5); ok {
+		t.Fatal("expected unknown theme to be reported")
+	}
+}
+
+func TestSimilarToSchemeComparesOneConfiguration(t *testing.T) {
+	themes := similarTestThemes()
+	c := &ThemeCache{}
+	c.similarity = buildThemeSimilarity(themes)
+
+	got := c.SimilarToScheme(themes[0].Dark, "mocha", 2)
+	ids := similarIDs(got)
+	if len(ids) != 2 || ids[0] != "macchiato" || ids[1] != "gruvbox" {
+		t.Fatalf("expected dark neighbours only, got %v", ids)
+	}
+	if got[0].SourceConfig != "" {
+		t.Fatalf("expected no source config label, got %q", got[0].SourceConfig)
+	}
+}
@@ -2,7 +2,6 @@ package registry
 
 import (
 	"math"
-	"strconv"
 
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 )
@@ -14,41 +13,6 @@ const (
 	wcagAAARatio = 7.0
 )
 
-// Pairs mirror what DMS/quickshell actually renders: bars, popouts, and modals
-// fill with surfaceContainer, nested cards with surfaceContainerHigh (see
-// DankMaterialShell Common/Theme.qml nestedSurface), window bases with surface.
-// Body covers the text read constantly; accent covers primary, which DMS draws
-// as bar text (Clock widget) and as filled button labels.
-var wcagBodyPairs = [][2]string{
-	{"surfaceText", "surface"},
-	{"surfaceText", "surfaceContainer"},
-	{"surfaceText", "surfaceContainerHigh"},
-	{"surfaceText", "surfaceContainerHighest"},
-	{"surfaceVariantText", "surface"},
-	{"surfaceVariantText", "surfaceContainer"},
-	{"surfaceVariantText", "surfaceContainerHigh"},
-}
-
-var wcagAccentPairs = [][2]string{
-	{"primaryText", "primary"},
-	{"primary", "surfaceContainer"},
-}
-
-var wcagTextPairs = append(append([][2]string{}, wcagBodyPairs...), wcagAccentPairs...)
-
-// Status colors render as standalone icons and badges, so they get the 3:1
-// non-text minimum from WCAG 2.2 SC 1.4.11
-// https://www.w3.org/TR/WCAG22/#non-text-contrast
-// Outline is excluded: it is a divider color that DMS draws at 12% alpha
-// (Theme.outlineMedium), which SC 1.4.11 exempts as decorative.
-var wcagNonTextPairs = [][2]string{
-	{"error", "surfaceContainer"},
-	{"warning", "surfaceContainer"},
-	{"info", "surfaceContainer"},
-}
-
-const wcagNonTextRatio = 3.0
-
 var wcagLevelRank = map[string]int{"fail": 0, "AA": 1, "AAA": 2}
 
 var wcagModeLabels = map[string]string{"dark": "Dark", "light": "Light"}
@@ -57,27 +21,6 @@ type wcagRGB struct {
 	r, g, b float64
 }
 
-func parseHexColor(value interface{}) (wcagRGB, bool) {
-	s, ok := value.(string)
-	if !ok {
-		return wcagRGB{}, false
-	}
-	if len(s) != 7 || s[0] != '#' {
-		return wcagRGB{}, false
-	}
-
-	n, err := strconv.ParseUint(s[1:], 16, 32)
-	if err != nil {
-		return wcagRGB{}, false
-	}
-
-	return wcagRGB{
-		r: float64(n >> 16 & 0xff),
-		g: float64(n >> 8 & 0xff),
-		b: float64(n & 0xff),
-	}, true
-}
-
 // https://www.w3.org/TR/WCAG22/#dfn-relative-luminance
 func relativeLuminance(c wcagRGB) float64 {
 	linearize := func(channel float64) float64 {
@@ -109,15 +52,11 @@ func wcagLevel(ratio float64) string {
 	return "fail"
 }
 
-func worstRatio(scheme map[string]interface{}, pairs [][2]string) (float64, []string) {
+func (r *WCAGRules) worstRatio(scheme map[string]interface{}, pairs [][2]string) (float64, []string) {
 	minRatio := math.Inf(1)
 	var worstPair []string
 	for _, pair := range pairs {
-		fg, ok := parseHexColor(scheme[pair[0]])
-		if !ok {
-			continue
-		}
-		bg, ok := parseHexColor(scheme[pair[1]])
+		fg, bg, ok := r.schemePair(scheme, pair)
 		if !ok {
 			continue
 		}
@@ -133,8 +72,8 @@ func worstRatio(scheme map[string]interface{}, pairs [][2]string) (float64, []st
 	return minRatio, worstPair
 }
 
-func groupWCAG(scheme map[string]interface{}, pairs [][2]string, level func(float64) string) *models.ThemeWCAGGroup {
-	ratio, pair := worstRatio(scheme, pairs)
+func (r *WCAGRules) groupWCAG(scheme map[string]interface{}, pairs [][2]string, level func(float64) string) *models.ThemeWCAGGroup {
+	ratio, pair := r.worstRatio(scheme, pairs)
 	if pair == nil {
 		return nil
 	}
@@ -146,26 +85,50 @@ func groupWCAG(scheme map[string]interface{}, pairs [][2]string, level func(floa
 	}
 }
 
-func nonTextLevel(ratio float64) string {
-	if ratio >= wcagNonTextRatio {
+func (r *WCAGRules) nonTextLevel(ratio float64) string {
+	if ratio >= r.NonTextRatio {
 		return "AA"
 	}
 	return "fail"
 }
 
-func schemeWCAG(scheme map[string]interface{}) *models.ThemeWCAGMode {
-	minRatio, worstPair := worstRatio(scheme, wcagTextPairs)
+// SchemeWCAG reports on a single flat token map, such as one mode of a generated theme,
+// under the newest rule set.
+func SchemeWCAG(scheme map[string]interface{}) *models.ThemeWCAGMode {
+	return activeWCAGRules().SchemeWCAG(scheme)
+}
+
+// SchemeWCAG reports on a single flat token map under these rules.
+func (r *WCAGRules) SchemeWCAG(scheme map[string]interface{}) *models.ThemeWCAGMode {
+	report := r.schemeWCAG(scheme)
+	if report != nil {
+		report.Rules = r.Version
+	}
+	return report
+}
+
+func (r *WCAGRules) schemeWCAG(scheme map[string]interface{}) *models.ThemeWCAGMode {
+	minRatio, worstPair := r.worstRatio(scheme, r.textPairs())
+	unevaluated := r.unevaluatedTokens(scheme)
 	if worstPair == nil {
-		return nil
+		if len(unevaluated) == 0 {
+			return nil
+		}
+		// Nothing could be measured, which must not read as a theme with no colors.
+		return &models.ThemeWCAGMode{Level: "fail", Unevaluated: unevaluated}
 	}
 
 	report := &models.ThemeWCAGMode{
 		Level:     wcagLevel(minRatio),
 		MinRatio:  math.Round(minRatio*100) / 100,
 		WorstPair: worstPair,
-		Body:      groupWCAG(scheme, wcagBodyPairs, wcagLevel),
-		Accent:    groupWCAG(scheme, wcagAccentPairs, wcagLevel),
-		NonText:   groupWCAG(scheme, wcagNonTextPairs, nonTextLevel),
+		Body:      r.groupWCAG(scheme, r.Body, wcagLevel),
+		Accent:    r.groupWCAG(scheme, r.Accent, wcagLevel),
+		NonText:   r.groupWCAG(scheme, r.NonText, r.nonTextLevel),
+		APCA:      r.schemeAPCA(scheme),
+		CVD:       r.schemeCVD(scheme),
+
+		Unevaluated: unevaluated,
 	}
 
 	// SC 1.4.11 is itself a Level AA criterion, so failing it fails AA outright.
@@ -196,10 +159,7 @@ type wcagConfig struct {
 }
 
 func modeConfigs(theme *models.Theme, mode string) ([]wcagConfig, string) {
-	base := theme.Dark
-	if mode == "light" {
-		base = theme.Light
-	}
+	base := modeColors(theme.Dark, theme.Light, mode)
 
 	plain := []wcagConfig{{label: wcagModeLabels[mode], scheme: base}}
 	variants := theme.Variants
@@ -218,15 +178,11 @@ func modeConfigs(theme *models.Theme, mode string) ([]wcagConfig, string) {
 		if option.ID == "" {
 			continue
 		}
-		override := option.Dark
-		if mode == "light" {
-			override = option.Light
-		}
 		configs = append(configs, wcagConfig{
 			key:    option.ID,
 			group:  option.ID,
 			label:  wcagLabel(option.Name, option.ID),
-			scheme: mergeSchemes(base, override),
+			scheme: mergeSchemes(base, modeColors(option.Dark, option.Light, mode)),
 		})
 	}
 	return configs, variants.Default
@@ -237,10 +193,7 @@ func modeConfigs(theme *models.Theme, mode string) ([]wcagConfig, string) {
 func multiVariantConfigs(variants *models.ThemeVariants, base map[string]interface{}, mode string) ([]wcagConfig, string) {
 	configs := []wcagConfig{}
 	for _, flavor := range variants.Flavors {
-		flavorColors := flavor.Dark
-		if mode == "light" {
-			flavorColors = flavor.Light
-		}
+		flavorColors := modeColors(flavor.Dark, flavor.Light, mode)
 		if flavor.ID == "" || flavorColors == nil {
 			continue
 		}
@@ -274,22 +227,23 @@ func wcagLabel(name, id string) string {
 	return id
 }
 
-func worstModeWCAG(reports map[string]*models.ThemeWCAGMode) *models.ThemeWCAGMode {
+func worstModeKey(reports map[string]*models.ThemeWCAGMode) string {
+	var worstKey string
 	var worst *models.ThemeWCAGMode
-	for _, report := range reports {
+	for key, report := range reports {
 		if worst == nil {
-			worst = report
+			worstKey, worst = key, report
 			continue
 		}
 		if wcagLevelRank[report.Level] < wcagLevelRank[worst.Level] {
-			worst = report
+			worstKey, worst = key, report
 			continue
 		}
 		if report.Level == worst.Level && report.MinRatio < worst.MinRatio {
-			worst = report
+			worstKey, worst = key, report
 		}
 	}
-	return worst
+	return worstKey
 }
 
 type wcagGroupLevels struct {
@@ -298,18 +252,22 @@ type wcagGroupLevels struct {
 	bodyLevel string
 }
 
-func modeWCAG(theme *models.Theme, mode string) *models.ThemeWCAGMode {
+func (r *WCAGRules) modeWCAG(theme *models.Theme, mode string) *models.ThemeWCAGMode {
 	configs, defaultKey := modeConfigs(theme, mode)
 	reports := map[string]*models.ThemeWCAGMode{}
+	schemes := map[string]map[string]interface{}{}
+	keys := []string{}
 	order := []string{}
 	groups := map[string]*wcagGroupLevels{}
 
 	for _, config := range configs {
-		report := schemeWCAG(config.scheme)
+		report := r.schemeWCAG(config.scheme)
 		if report == nil {
 			continue
 		}
 		reports[config.key] = report
+		schemes[config.key] = config.scheme
+		keys = append(keys, config.key)
 
 		bodyLevel := "fail"
 		if report.Body != nil {
@@ -340,17 +298,23 @@ func modeWCAG(theme *models.Theme, mode string) *models.ThemeWCAGMode {
 
 	// The headline stays the default config, which is what a user gets on first
 	// apply; breakdown carries every other config so nothing is over-promised.
-	primary := reports[defaultKey]
-	if primary == nil {
-		primary = worstModeWCAG(reports)
+	primaryKey := defaultKey
+	if reports[primaryKey] == nil {
+		primaryKey = worstModeKey(reports)
 	}
 
-	result := *primary
+	result := *reports[primaryKey]
+	// Suggestions follow the headline config only; fixes for the others are a resolve
+	// away, and computing them all would multiply refresh time by the config count.
+	result.Suggestions = r.SuggestWCAGFixes(schemes[primaryKey], "AA")
 	if len(reports) > 1 {
 		result.Variants = make(map[string]string, len(reports))
 		for key, report := range reports {
 			result.Variants[key] = report.Level
 		}
+		// Unlike the contrast headline, color-vision checks cover every config: a
+		// collision in any flavor is worth knowing before publishing.
+		result.CVD = mergeCVD(keys, reports)
 	}
 
 	result.Breakdown = make([]models.ThemeWCAGBreakdown, 0, len(order))
@@ -366,9 +330,19 @@ func modeWCAG(theme *models.Theme, mode string) *models.ThemeWCAGMode {
 	return &result
 }
 
+// ComputeThemeWCAG is the report the registry attaches to every theme, for callers
+// checking a theme that is not in the registry yet.
+func ComputeThemeWCAG(theme *models.Theme) *models.ThemeWCAG {
+	return computeThemeWCAG(theme)
+}
+
 func computeThemeWCAG(theme *models.Theme) *models.ThemeWCAG {
-	dark := modeWCAG(theme, "dark")
-	light := modeWCAG(theme, "light")
+	return activeWCAGRules().computeThemeWCAG(theme)
+}
+
+func (r *WCAGRules) computeThemeWCAG(theme *models.Theme) *models.ThemeWCAG {
+	dark := r.modeWCAG(theme, "dark")
+	light := r.modeWCAG(theme, "light")
 	if dark == nil && light == nil {
 		return nil
 	}
@@ -383,5 +357,5 @@ func computeThemeWCAG(theme *models.Theme) *models.ThemeWCAG {
 		}
 	}
 
-	return &models.ThemeWCAG{Level: level, Dark: dark, Light: light}
+	return &models.ThemeWCAG{Level: level, Rules: r.Version, Dark: dark, Light: light, APCA: themeAPCA(dark, light)}
 }
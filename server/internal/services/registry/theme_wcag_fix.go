@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"math"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+// fixMargin aims suggestions a hair above the threshold so rounding to #RRGGBB cannot
+// land them back under it.
+const fixMargin = 0.02
+
+// SuggestWCAGFixes proposes a replacement for the foreground of every pair that misses
+// its threshold: text pairs against the ratio for level ("AA" or "AAA"), status colors
+// against the 3:1 non-text minimum. Each suggestion is the smallest OKLCH lightness
+// change that gets there, keeping hue and chroma, and lists the other pairs sharing the
+// token that the change would push under their own threshold. Translucent foregrounds
+// are fixed as composited, so their suggestion is the opaque color to use instead.
+func SuggestWCAGFixes(scheme map[string]interface{}, level string) []models.ThemeWCAGSuggestion {
+	return activeWCAGRules().SuggestWCAGFixes(scheme, level)
+}
+
+// SuggestWCAGFixes proposes fixes for the pairs these rules check.
+func (rs *WCAGRules) SuggestWCAGFixes(scheme map[string]interface{}, level string) []models.ThemeWCAGSuggestion {
+	textTarget := wcagAARatio
+	if level == "AAA" {
+		textTarget = wcagAAARatio
+	}
+
+	type rule struct {
+		pair   [2]string
+		target float64
+	}
+	textPairs := rs.textPairs()
+	rules := make([]rule, 0, len(textPairs)+len(rs.NonText))
+	for _, pair := range textPairs {
+		rules = append(rules, rule{pair, textTarget})
+	}
+	for _, pair := range rs.NonText {
+		rules = append(rules, rule{pair, rs.NonTextRatio})
+	}
+
+	ratioOf := func(s map[string]interface{}, pair [2]string) (float64, bool) {
+		fg, bg, ok := rs.schemePair(s, pair)
+		if !ok {
+			return 0, false
+		}
+		return contrastRatio(fg, bg), true
+	}
+
+	var suggestions []models.ThemeWCAGSuggestion
+	for _, r := range rules {
+		ratio, ok := ratioOf(scheme, r.pair)
+		if !ok || ratio >= r.target {
+			continue
+		}
+
+		current := scheme[r.pair[0]].(string)
+		fg, bg, _ := rs.schemePair(scheme, r.pair)
+		fixed, reachable := colors.EnsureContrast(fg.colorsRGB(), bg.colorsRGB(), r.target+fixMargin)
+
+		patched := mergeSchemes(scheme, map[string]interface{}{r.pair[0]: fixed.Hex()})
+		newRatio, _ := ratioOf(patched, r.pair)
+
+		suggestion := models.ThemeWCAGSuggestion{
+			Pair:      []string{r.pair[0], r.pair[1]},
+			Token:     r.pair[0],
+			Current:   current,
+			Suggested: fixed.Hex(),
+			Ratio:     roundRatio(ratio),
+			NewRatio:  roundRatio(newRatio),
+			Target:    r.target,
+			Reachable: reachable,
+		}
+
+		for _, other := range rules {
+			if other.pair == r.pair || (other.pair[0] != r.pair[0] && other.pair[1] != r.pair[0]) {
+				continue
+			}
+			before, ok := ratioOf(scheme, other.pair)
+			if !ok || before < other.target {
+				continue
+			}
+			if after, _ := ratioOf(patched, other.pair); after < other.target {
+				suggestion.Breaks = append(suggestion.Breaks, models.ThemeWCAGPairRatio{
+					Pair:  []string{other.pair[0], other.pair[1]},
+					Ratio: roundRatio(after),
+				})
+			}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions
+}
+
+func roundRatio(ratio float64) float64 {
+	return math.Round(ratio*100) / 100
+}
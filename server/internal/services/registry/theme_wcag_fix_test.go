@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
+)
+
+func fixScheme() map[string]interface{} {
+	return map[string]interface{}{
+		"surface":                 "#141218",
+		"surfaceContainer":        "#211F26",
+		"surfaceContainerHigh":    "#2B2930",
+		"surfaceContainerHighest": "#36343B",
+		"surfaceText":             "#E6E0E9",
+		"surfaceVariantText":      "#6F6A75",
+		"primary":                 "#D0BCFF",
+		"primaryText":             "#381E72",
+		"error":                   "#F2B8B5",
+		"warning":                 "#E8C06A",
+		"info":                    "#9ECAFF",
+	}
+}
+
+func suggestionFor(suggestions []models.ThemeWCAGSuggestion, fg, bg string) *models.ThemeWCAGSuggestion {
+	for i := range suggestions {
+		if suggestions[i].Pair[0] == fg && suggestions[i].Pair[1] == bg {
+			return &suggestions[i]
+		}
+	}
+	return nil
+}
+
+func TestSuggestWCAGFixesKeepsHueAndReachesTarget(t *testing.T) {
+	scheme := fixScheme()
+	suggestions := SuggestWCAGFixes(scheme, "AA")
+
+	s := suggestionFor(suggestions, "surfaceVariantText", "surfaceContainerHigh")
+	if s == nil {
+		t.Fatalf("expected a suggestion for the failing pair, got %+v", suggestions)
+	}
+	if !s.Reachable || s.NewRatio < 4.5 || s.Ratio >= 4.5 {
+		t.Fatalf("unexpected suggestion %+v", s)
+	}
+
+	before, _ := colors.ParseHex(s.Current)
+	after, _ := colors.ParseHex(s.Suggested)
+	if d := before.OKLCH().H - after.OKLCH().H; d > 3 || d < -3 {
+		t.Fatalf("hue moved by %.1f degrees", d)
+	}
+	// Minimal: the fix should land just over the line, not at white.
+	if s.NewRatio > 4.8 {
+		t.Fatalf("suggestion overshoots at %.2f:1", s.NewRatio)
+	}
+
+	if suggestionFor(suggestions, "surfaceText", "surface") != nil {
+		t.Fatal("passing pairs should not get suggestions")
+	}
+}
+
+func TestSuggestWCAGFixesAAA(t *testing.T) {
+	// surfaceText clears 4.5:1 everywhere but the highest container, and 7:1 nowhere.
+	scheme := fixScheme()
+	scheme["surfaceText"] = "#A09AA6"
+
+	aa := SuggestWCAGFixes(scheme, "AA")
+	aaa := SuggestWCAGFixes(scheme, "AAA")
+	if len(aaa) <= len(aa) {
+		t.Fatalf("expected AAA to flag more pairs: AA %d, AAA %d", len(aa), len(aaa))
+	}
+	for _, s := range aaa {
+		if s.Target != wcagAAARatio && s.Target != activeWCAGRules().NonTextRatio {
+			t.Fatalf("unexpected target %v", s.Target)
+		}
+	}
+}
+
+func TestSuggestWCAGFixesReportsBrokenPairs(t *testing.T) {
+	// primary fails on surfaceContainer, and lifting it far enough to pass costs
+	// primaryText its own contrast against it.
+	scheme := fixScheme()
+	scheme["primary"] = "#5A4A8A"
+	scheme["primaryText"] = "#FFFFFF"
+
+	s := suggestionFor(SuggestWCAGFixes(scheme, "AA"), "primary", "surfaceContainer")
+	if s == nil {
+		t.Fatal("expected a suggestion for primary")
+	}
+	if len(s.Breaks) != 1 || s.Breaks[0].Pair[0] != "primaryText" || s.Breaks[0].Ratio >= 4.5 {
+		t.Fatalf("expected primaryText/primary to be reported broken, got %+v", s.Breaks)
+	}
+}
+
+func TestComputeThemeWCAGAttachesSuggestions(t *testing.T) {
+	wcag := computeThemeWCAG(&models.Theme{Dark: fixScheme()})
+	if wcag == nil || wcag.Dark == nil || len(wcag.Dark.Suggestions) == 0 {
+		t.Fatalf("expected suggestions on the dark headline, got %+v", wcag)
+	}
+}
@@ -0,0 +1,217 @@
+package registry
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// WCAGRules are the pairs the accessibility report checks, which mirror what one DMS
+// release actually renders. They live in JSON, one file per DMS version, so a Theme.qml
+// change can be tracked by dropping a file into the rules directory rather than
+// shipping a new server.
+type WCAGRules struct {
+	Version string `json:"version"`
+	// Body is the text read constantly: bars, popouts and modals fill with
+	// surfaceContainer, nested cards with surfaceContainerHigh (Theme.qml
+	// nestedSurface), window bases with surface.
+	Body [][2]string `json:"body"`
+	// Accent is primary, which DMS draws as bar text (Clock widget) and as filled
+	// button labels.
+	Accent [][2]string `json:"accent"`
+	// NonText pairs are status colors drawn as standalone icons and badges, held to the
+	// WCAG 2.2 SC 1.4.11 minimum rather than the text ratio. Outline stays out: DMS
+	// draws it at 12% alpha as a divider, which SC 1.4.11 exempts as decorative.
+	// https://www.w3.org/TR/WCAG22/#non-text-contrast
+	NonText      [][2]string `json:"nonText"`
+	NonTextRatio float64     `json:"nonTextRatio"`
+	// CVD pairs are the colors DMS relies on hue alone to tell apart, compared as drawn
+	// on CVDBackdrop.
+	CVD         [][2]string `json:"cvd"`
+	CVDBackdrop string      `json:"cvdBackdrop"`
+	// Backdrops names the token each background is drawn over, for compositing
+	// translucent ones. background has no entry; beneath it is the wallpaper.
+	Backdrops map[string]string `json:"backdrops"`
+}
+
+func (r *WCAGRules) textPairs() [][2]string {
+	return append(append([][2]string{}, r.Body...), r.Accent...)
+}
+
+func (r *WCAGRules) validate() error {
+	if r.Version == "" {
+		return errors.New("version is required")
+	}
+	if _, ok := parseRulesVersion(r.Version); !ok {
+		return fmt.Errorf("version %q is not dotted numbers", r.Version)
+	}
+	if len(r.Body) == 0 {
+		return errors.New("body pairs are required")
+	}
+	for _, group := range [][][2]string{r.Body, r.Accent, r.NonText, r.CVD} {
+		for _, pair := range group {
+			if pair[0] == "" || pair[1] == "" {
+				return fmt.Errorf("pair %v names an empty token", pair)
+			}
+		}
+	}
+	if r.NonTextRatio <= 0 && len(r.NonText) > 0 {
+		return errors.New("nonTextRatio is required with nonText pairs")
+	}
+	if r.CVDBackdrop == "" && len(r.CVD) > 0 {
+		return errors.New("cvdBackdrop is required with cvd pairs")
+	}
+	return nil
+}
+
+//go:embed wcag_rules/*.json
+var embeddedWCAGRules embed.FS
+
+type wcagRuleSet struct {
+	byVersion map[string]*WCAGRules
+	latest    *WCAGRules
+}
+
+var wcagRuleSets atomic.Pointer[wcagRuleSet]
+
+func init() {
+	set, err := readBuiltinWCAGRules()
+	if err != nil {
+		panic(fmt.Sprintf("embedded WCAG rules: %v", err))
+	}
+	wcagRuleSets.Store(set)
+}
+
+// LoadWCAGRules reloads the rule sets: the ones built in, then every *.json file in dir,
+// which add DMS versions or replace built-in ones of the same version. Nothing changes
+// if any file is invalid. Reports already computed keep the rules they were made with
+// until the next cache refresh.
+func LoadWCAGRules(dir string) error {
+	set, err := readBuiltinWCAGRules()
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		overrides, err := readWCAGRules(os.DirFS(dir))
+		if err != nil {
+			return err
+		}
+		for version, rules := range overrides.byVersion {
+			set.byVersion[version] = rules
+		}
+		set.latest = latestWCAGRules(set.byVersion)
+	}
+	wcagRuleSets.Store(set)
+	return nil
+}
+
+func readBuiltinWCAGRules() (*wcagRuleSet, error) {
+	sub, err := fs.Sub(embeddedWCAGRules, "wcag_rules")
+	if err != nil {
+		return nil, err
+	}
+	return readWCAGRules(sub)
+}
+
+func readWCAGRules(fsys fs.FS) (*wcagRuleSet, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	set := &wcagRuleSet{byVersion: map[string]*WCAGRules{}}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var rules WCAGRules
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := rules.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if _, dup := set.byVersion[rules.Version]; dup {
+			return nil, fmt.Errorf("%s: version %s is defined twice", file, rules.Version)
+		}
+		set.byVersion[rules.Version] = &rules
+	}
+	set.latest = latestWCAGRules(set.byVersion)
+	return set, nil
+}
+
+// WCAGRulesFor returns the rule set for a DMS version, or the newest when version is
+// empty.
+func WCAGRulesFor(version string) (*WCAGRules, bool) {
+	set := wcagRuleSets.Load()
+	if version == "" {
+		return set.latest, set.latest != nil
+	}
+	rules, ok := set.byVersion[version]
+	return rules, ok
+}
+
+// WCAGRuleVersions lists the DMS versions with rule sets, oldest first.
+func WCAGRuleVersions() []string {
+	set := wcagRuleSets.Load()
+	versions := make([]string, 0, len(set.byVersion))
+	for version := range set.byVersion {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareRulesVersions(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// activeWCAGRules are the rules the registry's own reports use.
+func activeWCAGRules() *WCAGRules {
+	return wcagRuleSets.Load().latest
+}
+
+func latestWCAGRules(byVersion map[string]*WCAGRules) *WCAGRules {
+	var latest *WCAGRules
+	for _, rules := range byVersion {
+		if latest == nil || compareRulesVersions(rules.Version, latest.Version) > 0 {
+			latest = rules
+		}
+	}
+	return latest
+}
+
+func parseRulesVersion(version string) ([]int, bool) {
+	parts := strings.Split(version, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
+
+func compareRulesVersions(a, b string) int {
+	pa, _ := parseRulesVersion(a)
+	pb, _ := parseRulesVersion(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
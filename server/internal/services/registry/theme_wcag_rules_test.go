@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinWCAGRulesLoad(t *testing.T) {
+	rules, ok := WCAGRulesFor("")
+	if !ok || rules.Version == "" {
+		t.Fatal("expected a built-in rule set")
+	}
+	if len(rules.Body) == 0 || rules.NonTextRatio != 3 || rules.Backdrops["surface"] != "background" {
+		t.Fatalf("built-in rules look incomplete: %+v", rules)
+	}
+}
+
+func TestLoadWCAGRulesAddsVersions(t *testing.T) {
+	t.Cleanup(func() { _ = LoadWCAGRules("") })
+
+	dir := t.TempDir()
+	rules := `{"version": "99.0", "body": [["surfaceText", "surfaceContainer"]], "backdrops": {}}`
+	if err := os.WriteFile(filepath.Join(dir, "99.0.json"), []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadWCAGRules(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, _ := WCAGRulesFor("")
+	if latest.Version != "99.0" {
+		t.Fatalf("expected 99.0 to become the newest rule set, got %s", latest.Version)
+	}
+	if versions := WCAGRuleVersions(); versions[len(versions)-1] != "99.0" || len(versions) < 2 {
+		t.Fatalf("expected built-in versions to stay, got %v", versions)
+	}
+
+	// Only the one body pair is checked, so a failing surface pair no longer counts.
+	report := latest.SchemeWCAG(map[string]interface{}{
+		"surfaceText":      "#FFFFFF",
+		"surface":          "#FFFFFF",
+		"surfaceContainer": "#000000",
+	})
+	if report == nil || report.Level != "AAA" || report.Rules != "99.0" {
+		t.Fatalf("expected the loaded rules to grade the scheme, got %+v", report)
+	}
+}
+
+func TestLoadWCAGRulesRejectsInvalid(t *testing.T) {
+	t.Cleanup(func() { _ = LoadWCAGRules("") })
+	before := WCAGRuleVersions()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"version": "2.0", "body": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadWCAGRules(dir); err == nil {
+		t.Fatal("expected rules without body pairs to be rejected")
+	}
+	if after := WCAGRuleVersions(); len(after) != len(before) {
+		t.Fatalf("expected a failed load to keep the current rules, got %v", after)
+	}
+}
+
+func TestCompareRulesVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		less bool
+	}{
+		{"1.4", "1.5", true},
+		{"1.10", "1.9", false},
+		{"1.5", "1.5.1", true},
+	}
+	for _, tc := range cases {
+		if got := compareRulesVersions(tc.a, tc.b) < 0; got != tc.less {
+			t.Fatalf("%s < %s: expected %v", tc.a, tc.b, tc.less)
+		}
+	}
+}
+
+func TestContrastMatrix(t *testing.T) {
+	scheme := map[string]interface{}{
+		"surface":          "#000000",
+		"surfaceContainer": "#000000",
+		"surfaceText":      "#FFFFFF",
+		"background":       "#80FFFFFF",
+		"hexAlpha":         "argb",
+		"matugen_type":     "scheme-tonal-spot",
+	}
+
+	matrix := activeWCAGRules().ContrastMatrix(scheme)
+	want := []string{"background", "surface", "surfaceContainer", "surfaceText"}
+	if len(matrix.Tokens) != len(want) {
+		t.Fatalf("expected tokens %v, got %v", want, matrix.Tokens)
+	}
+	for i, token := range want {
+		if matrix.Tokens[i] != token {
+			t.Fatalf("expected tokens %v, got %v", want, matrix.Tokens)
+		}
+	}
+
+	// surfaceText (row 3) on surface (column 1) is the full 21:1.
+	if cell := matrix.Ratios[3][1]; cell == nil || *cell != 21 {
+		t.Fatalf("expected 21 for surfaceText on surface, got %v", cell)
+	}
+	if matrix.Lc[3][1] == nil || *matrix.Lc[3][1] >= 0 || matrix.Lc[1][3] == nil || *matrix.Lc[1][3] <= 0 {
+		t.Fatal("expected Lc to be signed by direction")
+	}
+	// background is translucent with nothing beneath it, so its column is empty.
+	for i := range matrix.Tokens {
+		if matrix.Ratios[i][0] != nil {
+			t.Fatalf("expected no ratio over the translucent background, row %d", i)
+		}
+	}
+	if len(matrix.Checked) != 2 || matrix.Checked[0].Group != "body" {
+		t.Fatalf("expected the two defined body pairs to be checked, got %+v", matrix.Checked)
+	}
+}
@@ -7,9 +7,19 @@ import (
 	"github.com/AvengeMedia/DankLinux-Docs/server/internal/models"
 )
 
+// mustSchemeColor reads value as a scheme token would be read.
+func mustSchemeColor(t *testing.T, value string) wcagRGB {
+	t.Helper()
+	c, err := SchemeColor(map[string]interface{}{"token": value}, "token")
+	if err != nil {
+		t.Fatalf("%s: %v", value, err)
+	}
+	return toWCAGRGB(c.RGB)
+}
+
 func TestContrastRatioBlackWhite(t *testing.T) {
-	black, _ := parseHexColor("#000000")
-	white, _ := parseHexColor("#FFFFFF")
+	black := mustSchemeColor(t, "#000000")
+	white := mustSchemeColor(t, "#FFFFFF")
 
 	ratio := contrastRatio(black, white)
 	if math.Abs(ratio-21.0) > 0.01 {
@@ -19,8 +29,8 @@ func TestContrastRatioBlackWhite(t *testing.T) {
 
 func TestContrastRatioAABoundaryGray(t *testing.T) {
 	// #767676 on white is the canonical 4.54:1 AA boundary gray
-	gray, _ := parseHexColor("#767676")
-	white, _ := parseHexColor("#FFFFFF")
+	gray := mustSchemeColor(t, "#767676")
+	white := mustSchemeColor(t, "#FFFFFF")
 
 	ratio := contrastRatio(gray, white)
 	if math.Abs(ratio-4.54) > 0.01 {
@@ -28,10 +38,10 @@ func TestContrastRatioAABoundaryGray(t *testing.T) {
 	}
 }
 
-func TestParseHexColorRejectsInvalid(t *testing.T) {
-	invalid := []interface{}{nil, 42, "", "#fff", "#GGGGGG", "#+12345", "123456#"}
+func TestSchemeColorRejectsInvalid(t *testing.T) {
+	invalid := []interface{}{nil, 42, "", "#GGGGGG", "#+12345", "123456#", "#80FFFFFF", "rgb(0, 0)"}
 	for _, value := range invalid {
-		if _, ok := parseHexColor(value); ok {
+		if _, err := SchemeColor(map[string]interface{}{"token": value}, "token"); err == nil {
 			t.Fatalf("expected %v to be rejected", value)
 		}
 	}
@@ -164,7 +174,7 @@ func TestComputeThemeWCAGNonTextGate(t *testing.T) {
 		"error":            "#1a1a1a",
 	}
 
-	report := schemeWCAG(scheme)
+	report := activeWCAGRules().schemeWCAG(scheme)
 	if report == nil {
 		t.Fatal("expected report, got nil")
 	}
@@ -190,7 +200,7 @@ func TestSchemeWCAGSplitsBodyFromAccent(t *testing.T) {
 		"error":            "#FF5555",
 	}
 
-	report := schemeWCAG(scheme)
+	report := activeWCAGRules().schemeWCAG(scheme)
 	if report == nil {
 		t.Fatal("expected report, got nil")
 	}
@@ -0,0 +1,74 @@
+package themeexport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+// base16TextStep is how much darker than surfaceText a light theme's base07 is, in
+// OKLCH lightness.
+const base16TextStep = 0.08
+
+var base16Format = Format{
+	ID:          "base16",
+	Name:        "base16 scheme",
+	Extension:   "yaml",
+	ContentType: "application/yaml",
+	Render:      renderBase16,
+}
+
+// renderBase16 writes a tinted-theming scheme (spec 0.11). The grays run from surface to
+// the strongest text; the accents come from the terminal palette:
+//
+//	base00  surface                  base08  red (error)
+//	base01  surfaceContainer         base09  orange, between red and yellow
+//	base02  surfaceContainerHigh     base0A  yellow (warning)
+//	base03  outline                  base0B  green
+//	base04  surfaceVariantText       base0C  cyan
+//	base05  surfaceText              base0D  blue (info)
+//	base06  backgroundText           base0E  primary
+//	base07  strongest text           base0F  red mixed 40% into surface
+//
+// base07 ends the ramp, so it is bright white on dark themes. Bright white on a light
+// theme is a pale surface tone, so there it is surfaceText pushed darker instead.
+func renderBase16(p *Palette) ([]byte, error) {
+	base07 := p.ANSI[15]
+	if !p.Dark() {
+		base07 = brighten(p.Color("surfaceText"), -base16TextStep)
+	}
+
+	slots := [16]string{
+		p.Hex("surface"),
+		p.Hex("surfaceContainer"),
+		p.Hex("surfaceContainerHigh"),
+		p.Hex("outline"),
+		p.Hex("surfaceVariantText"),
+		p.Hex("surfaceText"),
+		p.Hex("backgroundText"),
+		base07.Hex(),
+		p.ANSI[ansiRed].Hex(),
+		colors.Mix(p.ANSI[ansiRed], p.ANSI[ansiYellow], 0.5).Hex(),
+		p.ANSI[ansiYellow].Hex(),
+		p.ANSI[ansiGreen].Hex(),
+		p.ANSI[ansiCyan].Hex(),
+		p.ANSI[ansiBlue].Hex(),
+		p.Hex("primary"),
+		colors.Mix(p.ANSI[ansiRed], p.Color("surface"), 0.4).Hex(),
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", header(p))
+	b.WriteString("system: \"base16\"\n")
+	fmt.Fprintf(&b, "name: %q\n", p.Name)
+	if p.Author != "" {
+		fmt.Fprintf(&b, "author: %q\n", p.Author)
+	}
+	fmt.Fprintf(&b, "variant: %q\n", p.Mode)
+	b.WriteString("palette:\n")
+	for i, hex := range slots {
+		fmt.Fprintf(&b, "  base0%X: %q\n", i, strings.ToLower(hex))
+	}
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,68 @@
+package themeexport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is one export target. Render must be deterministic: the same palette always
+// produces the same bytes, which is what the golden tests pin down.
+type Format struct {
+	ID          string
+	Name        string
+	Extension   string
+	ContentType string
+	Render      func(p *Palette) ([]byte, error)
+}
+
+// formats is every export target, in the order they are documented.
+var formats = []Format{
+	kittyFormat,
+	footFormat,
+	alacrittyFormat,
+	gtkFormat,
+	qtFormat,
+	vscodeFormat,
+	base16Format,
+}
+
+func Formats() []Format {
+	return formats
+}
+
+// FormatIDs lists the accepted values for the export endpoint's format parameter.
+func FormatIDs() []string {
+	ids := make([]string, len(formats))
+	for i, f := range formats {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+func LookupFormat(id string) (Format, bool) {
+	for _, f := range formats {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Filename is the download name for a theme exported in this format.
+func (f Format) Filename(themeID, mode string) string {
+	return fmt.Sprintf("%s-%s.%s", themeID, mode, f.Extension)
+}
+
+// header is the comment line each text format opens with.
+func header(p *Palette) string {
+	title := p.Name
+	if p.Author != "" {
+		title += " by " + p.Author
+	}
+	return fmt.Sprintf("%s (%s), exported from the DankMaterialShell theme registry", title, p.Mode)
+}
+
+// bareHex drops the leading # for formats that want plain RRGGBB.
+func bareHex(hex string) string {
+	return strings.TrimPrefix(hex, "#")
+}
@@ -0,0 +1,199 @@
+package themeexport
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// exportFixtures are a full dark scheme and a light one with only the required tokens,
+// which exercises every derivation in NewPalette.
+var exportFixtures = []struct {
+	name   string
+	mode   string
+	scheme map[string]interface{}
+}{
+	{
+		name: "mocha",
+		mode: "dark",
+		scheme: map[string]interface{}{
+			"primary":                 "#CBA6F7",
+			"primaryText":             "#11111B",
+			"primaryContainer":        "#45385A",
+			"secondary":               "#F5C2E7",
+			"surface":                 "#1E1E2E",
+			"surfaceText":             "#CDD6F4",
+			"surfaceVariant":          "#313244",
+			"surfaceVariantText":      "#A6ADC8",
+			"surfaceTint":             "#CBA6F7",
+			"background":              "#11111B",
+			"backgroundText":          "#CDD6F4",
+			"outline":                 "#6C7086",
+			"surfaceContainerLowest":  "#11111B",
+			"surfaceContainerLow":     "#181825",
+			"surfaceContainer":        "#1E1E2E",
+			"surfaceContainerHigh":    "#313244",
+			"surfaceContainerHighest": "#45475A",
+			"error":                   "#F38BA8",
+			"warning":                 "#F9E2AF",
+			"info":                    "#89B4FA",
+			"matugen_type":            "scheme-tonal-spot",
+		},
+	},
+	{
+		name: "sparse",
+		mode: "light",
+		scheme: map[string]interface{}{
+			"primary":     "#1E66F5",
+			"surface":     "#EFF1F5",
+			"surfaceText": "#4C4F69",
+		},
+	},
+}
+
+func TestFormatsMatchGoldenFiles(t *testing.T) {
+	for _, fixture := range exportFixtures {
+		p, err := NewPalette("Fixture "+fixture.name, "dms", fixture.mode, fixture.scheme)
+		if err != nil {
+			t.Fatalf("%s: %v", fixture.name, err)
+		}
+
+		for _, format := range Formats() {
+			t.Run(fixture.name+"/"+format.ID, func(t *testing.T) {
+				got, err := format.Render(p)
+				if err != nil {
+					t.Fatalf("render: %v", err)
+				}
+				if format.ID == "base16" {
+					checkBase16Ramp(t, got)
+				}
+
+				golden := filepath.Join("testdata", fixture.name+"."+format.ID+"."+format.Extension)
+				if *update {
+					if err := os.WriteFile(golden, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run go test -update to create it)", err)
+				}
+				if string(got) != string(want) {
+					t.Fatalf("%s is out of date; diff against go test -update output:\n%s", golden, got)
+				}
+			})
+		}
+	}
+}
+
+// checkBase16Ramp fails unless base00 to base07 each stand out from base00 at least as
+// much as the slot before, in light themes as in dark ones.
+func checkBase16Ramp(t *testing.T, scheme []byte) {
+	t.Helper()
+	var ramp []colors.RGB
+	for i := range 8 {
+		prefix := fmt.Sprintf("  base0%d: \"", i)
+		for _, line := range strings.Split(string(scheme), "\n") {
+			if hex, ok := strings.CutPrefix(line, prefix); ok {
+				c, _ := colors.ParseHex(strings.TrimSuffix(hex, "\""))
+				ramp = append(ramp, c)
+			}
+		}
+	}
+	if len(ramp) != 8 {
+		t.Fatalf("expected base00 to base07, found %d", len(ramp))
+	}
+	for i := 1; i < len(ramp); i++ {
+		if colors.Contrast(ramp[i], ramp[0]) < colors.Contrast(ramp[i-1], ramp[0]) {
+			t.Fatalf("base0%d %s stands out from base00 less than base0%d %s", i, ramp[i].Hex(), i-1, ramp[i-1].Hex())
+		}
+	}
+}
+
+func TestNewPaletteRequiresCoreTokens(t *testing.T) {
+	_, err := NewPalette("x", "", "dark", map[string]interface{}{
+		"surface":     "#000000",
+		"surfaceText": "#FFFFFF",
+	})
+	if err == nil {
+		t.Fatal("expected an error without primary")
+	}
+}
+
+func TestPaletteKeepsThemeTokens(t *testing.T) {
+	p, err := NewPalette("x", "", "dark", exportFixtures[0].scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Hex("primaryContainer") != "#45385A" || p.Hex("error") != "#F38BA8" {
+		t.Fatalf("theme tokens were overwritten: %s %s", p.Hex("primaryContainer"), p.Hex("error"))
+	}
+	if p.ANSI[ansiRed].Hex() != "#F38BA8" || p.ANSI[ansiBlue].Hex() != "#89B4FA" {
+		t.Fatalf("expected red and blue to come from error and info, got %s %s", p.ANSI[ansiRed].Hex(), p.ANSI[ansiBlue].Hex())
+	}
+}
+
+func TestPaletteReadsEveryRegistryNotation(t *testing.T) {
+	p, err := NewPalette("x", "", "dark", map[string]interface{}{
+		"background":       "#000000",
+		"surface":          "rgb(30, 30, 46)",
+		"surfaceText":      "#FFCDD6F4",
+		"primary":          "rgba(203 166 247 / 100%)",
+		"surfaceContainer": "#80FFFFFF",
+		"outline":          "rgba(255, 255, 255, 0.5)",
+		"hexAlpha":         "argb",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Hex("surface") != "#1E1E2E" || p.Hex("surfaceText") != "#CDD6F4" || p.Hex("primary") != "#CBA6F7" {
+		t.Fatalf("expected core tokens to be read, got %s %s %s", p.Hex("surface"), p.Hex("surfaceText"), p.Hex("primary"))
+	}
+	// surfaceContainer sits on surface; outline has no backdrop and lands on surface too.
+	if p.Hex("surfaceContainer") != "#8F8F97" || p.Hex("outline") != "#8F8F97" {
+		t.Fatalf("expected translucent tokens composited onto surface, got %s %s", p.Hex("surfaceContainer"), p.Hex("outline"))
+	}
+}
+
+func TestFormatIDsAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, id := range FormatIDs() {
+		if seen[id] {
+			t.Fatalf("duplicate format id %s", id)
+		}
+		seen[id] = true
+		if _, ok := LookupFormat(id); !ok {
+			t.Fatalf("LookupFormat(%s) failed", id)
+		}
+	}
+}
+
+func TestBrightANSIKeepsHue(t *testing.T) {
+	for _, fx := range exportFixtures {
+		p, err := NewPalette(fx.name, "", fx.mode, fx.scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for slot := ansiRed; slot <= ansiCyan; slot++ {
+			normal, bright := p.ANSI[slot].OKLCH(), p.ANSI[slot+8].OKLCH()
+			if bright.C < normal.C*0.7 {
+				t.Errorf("%s color%d %s lost the chroma of color%d %s", fx.name, slot+8, p.ANSI[slot+8].Hex(), slot, p.ANSI[slot].Hex())
+				continue
+			}
+			dh := math.Abs(bright.H - normal.H)
+			if dh = math.Min(dh, 360-dh); dh > 10 {
+				t.Errorf("%s color%d %s is %.0f° off the hue of color%d %s", fx.name, slot+8, p.ANSI[slot+8].Hex(), dh, slot, p.ANSI[slot].Hex())
+			}
+		}
+	}
+}
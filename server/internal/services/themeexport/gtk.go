@@ -0,0 +1,64 @@
+package themeexport
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+var gtkFormat = Format{
+	ID:          "gtk",
+	Name:        "GTK CSS",
+	Extension:   "css",
+	ContentType: "text/css; charset=utf-8",
+	Render:      renderGTK,
+}
+
+// gtkColors maps libadwaita's named colors, which adw-gtk3 reads too, onto DMS tokens.
+// Foregrounds on the status colors are picked for contrast since DMS has no token for
+// them; success has no DMS counterpart either and takes the terminal palette's green.
+var gtkColors = []struct {
+	name  string
+	token string
+}{
+	{"accent_color", "primary"},
+	{"accent_bg_color", "primary"},
+	{"accent_fg_color", "primaryText"},
+	{"window_bg_color", "surface"},
+	{"window_fg_color", "surfaceText"},
+	{"view_bg_color", "background"},
+	{"view_fg_color", "backgroundText"},
+	{"headerbar_bg_color", "surfaceContainer"},
+	{"headerbar_fg_color", "surfaceText"},
+	{"headerbar_backdrop_color", "surface"},
+	{"sidebar_bg_color", "surfaceContainerLow"},
+	{"sidebar_fg_color", "surfaceText"},
+	{"card_bg_color", "surfaceContainerHigh"},
+	{"card_fg_color", "surfaceText"},
+	{"popover_bg_color", "surfaceContainer"},
+	{"popover_fg_color", "surfaceText"},
+	{"dialog_bg_color", "surfaceContainerHigh"},
+	{"dialog_fg_color", "surfaceText"},
+	{"borders", "outline"},
+	{"error_color", "error"},
+	{"error_bg_color", "error"},
+	{"warning_color", "warning"},
+	{"warning_bg_color", "warning"},
+}
+
+func renderGTK(p *Palette) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "/* %s */\n\n", header(p))
+
+	define := func(name, hex string) { fmt.Fprintf(&b, "@define-color %s %s;\n", name, hex) }
+	for _, c := range gtkColors {
+		define(c.name, p.Hex(c.token))
+	}
+	define("error_fg_color", colors.ReadableOn(p.Color("error")).Hex())
+	define("warning_fg_color", colors.ReadableOn(p.Color("warning")).Hex())
+	define("success_color", p.ANSI[ansiGreen].Hex())
+	define("success_bg_color", p.ANSI[ansiGreen].Hex())
+	define("success_fg_color", colors.ReadableOn(p.ANSI[ansiGreen]).Hex())
+	return []byte(b.String()), nil
+}
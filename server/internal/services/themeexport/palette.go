@@ -0,0 +1,241 @@
+// Package themeexport renders a resolved DMS color scheme into the config formats of
+// other applications: terminals, toolkits and editors.
+//
+// Every format reads from a Palette rather than the raw token map, so the mapping from
+// DMS tokens to each target lives in one place per format and missing optional tokens
+// are derived the same way everywhere.
+package themeexport
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+)
+
+// Palette is a resolved scheme with every token an exporter reads filled in.
+//
+// Tokens a theme leaves out are derived from the ones it has:
+//
+//	primaryText              black or white, whichever reads on primary
+//	primaryContainer         primary mixed 35% into surface
+//	secondary                primary
+//	surfaceVariantText       surfaceText mixed 25% toward surface
+//	surfaceContainer         surface mixed 5% toward surfaceText
+//	surfaceContainerLow      halfway between surface and surfaceContainer
+//	surfaceContainerHigh     surfaceContainer mixed 5% toward surfaceText
+//	surfaceContainerHighest  surfaceContainerHigh mixed 5% toward surfaceText
+//	outline                  surface mixed 40% toward surfaceText
+//	background               surface
+//	backgroundText           surfaceText
+//	error, warning, info     red, yellow and blue of the terminal palette
+type Palette struct {
+	Name   string
+	Author string
+	Mode   string
+
+	tokens map[string]colors.RGB
+
+	// ANSI is the 16-color terminal palette, see buildANSI.
+	ANSI [16]colors.RGB
+}
+
+// requiredTokens are the tokens nothing sensible can be derived without.
+var requiredTokens = []string{"surface", "surfaceText", "primary"}
+
+// NewPalette reads a resolved token map, as returned by registry.ResolveScheme, in
+// every notation the registry reads. Exported formats have no alpha, so a translucent
+// token is exported as the color it shows: composited down its backdrops as the
+// registry's contrast report does, or, for tokens drawn over content such as text,
+// onto surface.
+func NewPalette(name, author, mode string, scheme map[string]interface{}) (*Palette, error) {
+	p := &Palette{Name: name, Author: author, Mode: mode, tokens: map[string]colors.RGB{}}
+	overSurface := map[string]colors.RGBA{}
+	for token := range scheme {
+		c, err := registry.SchemeColor(scheme, token)
+		if err != nil {
+			continue
+		}
+		if opaque, err := registry.SchemeOpaqueColor(scheme, token); err == nil {
+			p.tokens[token] = opaque
+		} else {
+			overSurface[token] = c
+		}
+	}
+	if surface, ok := p.tokens["surface"]; ok {
+		for token, c := range overSurface {
+			p.tokens[token] = c.Over(surface)
+		}
+	}
+	for _, token := range requiredTokens {
+		if _, ok := p.tokens[token]; !ok {
+			return nil, fmt.Errorf("scheme is missing %s", token)
+		}
+	}
+
+	p.derive("primaryText", func() colors.RGB { return colors.ReadableOn(p.tokens["primary"]) })
+	p.derive("primaryContainer", func() colors.RGB { return colors.Mix(p.tokens["surface"], p.tokens["primary"], 0.35) })
+	p.derive("secondary", func() colors.RGB { return p.tokens["primary"] })
+	p.derive("surfaceVariantText", func() colors.RGB { return p.towardSurface("surfaceText", 0.25) })
+	p.derive("surfaceContainer", func() colors.RGB { return p.towardText("surface", 0.05) })
+	p.derive("surfaceContainerLow", func() colors.RGB {
+		return colors.Mix(p.tokens["surface"], p.tokens["surfaceContainer"], 0.5)
+	})
+	p.derive("surfaceContainerHigh", func() colors.RGB { return p.towardText("surfaceContainer", 0.05) })
+	p.derive("surfaceContainerHighest", func() colors.RGB { return p.towardText("surfaceContainerHigh", 0.05) })
+	p.derive("outline", func() colors.RGB { return p.towardText("surface", 0.4) })
+	p.derive("background", func() colors.RGB { return p.tokens["surface"] })
+	p.derive("backgroundText", func() colors.RGB { return p.tokens["surfaceText"] })
+
+	p.ANSI = buildANSI(p)
+	p.derive("error", func() colors.RGB { return p.ANSI[1] })
+	p.derive("warning", func() colors.RGB { return p.ANSI[3] })
+	p.derive("info", func() colors.RGB { return p.ANSI[4] })
+	return p, nil
+}
+
+func (p *Palette) derive(token string, fn func() colors.RGB) {
+	if _, ok := p.tokens[token]; !ok {
+		p.tokens[token] = fn()
+	}
+}
+
+func (p *Palette) towardText(token string, t float64) colors.RGB {
+	return colors.Mix(p.tokens[token], p.tokens["surfaceText"], t)
+}
+
+func (p *Palette) towardSurface(token string, t float64) colors.RGB {
+	return colors.Mix(p.tokens[token], p.tokens["surface"], t)
+}
+
+// Color returns a token, including derived ones. Unknown tokens are black so a typo in
+// a format shows up in its golden file rather than as a panic.
+func (p *Palette) Color(token string) colors.RGB {
+	return p.tokens[token]
+}
+
+func (p *Palette) Hex(token string) string {
+	return p.tokens[token].Hex()
+}
+
+func (p *Palette) Dark() bool {
+	return p.Mode != "light"
+}
+
+// ANSI color slots.
+const (
+	ansiBlack = iota
+	ansiRed
+	ansiGreen
+	ansiYellow
+	ansiBlue
+	ansiMagenta
+	ansiCyan
+	ansiWhite
+)
+
+// ansiNames are the slot names used by formats that key colors by name.
+var ansiNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ansiHues are OKLCH hues for slots with no DMS token behind them, and for red, yellow
+// and blue when a theme leaves out error, warning or info.
+var ansiHues = [8]float64{
+	ansiRed:     25,
+	ansiGreen:   145,
+	ansiYellow:  90,
+	ansiBlue:    255,
+	ansiMagenta: 330,
+	ansiCyan:    200,
+}
+
+// buildANSI maps a palette onto the 16 terminal colors:
+//
+//	0  black          dark: surfaceContainerHighest   light: surfaceVariantText
+//	1  red            error
+//	2  green          derived
+//	3  yellow         warning
+//	4  blue           info
+//	5  magenta        derived
+//	6  cyan           derived
+//	7  white          dark: surfaceVariantText        light: surfaceContainerHighest
+//	8  bright black   outline
+//	15 bright white   dark: surfaceText               light: surfaceContainerHigh
+//	9-14              the normal color pushed up to 0.08 OKLCH lightness away from the surface
+//
+// Derived colors take the mean lightness and chroma of the status colors the theme does
+// define, so generated greens and cyans sit at the same weight as its own reds.
+func buildANSI(p *Palette) [16]colors.RGB {
+	var ansi [16]colors.RGB
+
+	anchors := map[int]string{ansiRed: "error", ansiYellow: "warning", ansiBlue: "info"}
+	var sumL, sumC float64
+	var n int
+	for _, slot := range []int{ansiRed, ansiYellow, ansiBlue} {
+		if c, ok := p.tokens[anchors[slot]]; ok {
+			lch := c.OKLCH()
+			sumL += lch.L
+			sumC += lch.C
+			n++
+		}
+	}
+	l, c := 0.72, 0.12
+	if !p.Dark() {
+		l = 0.52
+	}
+	if n > 0 {
+		l, c = sumL/float64(n), max(sumC/float64(n), 0.08)
+	}
+
+	for slot := ansiRed; slot <= ansiCyan; slot++ {
+		if token, ok := anchors[slot]; ok {
+			if color, ok := p.tokens[token]; ok {
+				ansi[slot] = color
+				continue
+			}
+		}
+		ansi[slot] = colors.OKLCH{L: l, C: c, H: ansiHues[slot]}.RGB()
+	}
+
+	if p.Dark() {
+		ansi[ansiBlack] = p.tokens["surfaceContainerHighest"]
+		ansi[ansiWhite] = p.tokens["surfaceVariantText"]
+		ansi[8] = p.tokens["outline"]
+		ansi[15] = p.tokens["surfaceText"]
+	} else {
+		ansi[ansiBlack] = p.tokens["surfaceVariantText"]
+		ansi[ansiWhite] = p.tokens["surfaceContainerHighest"]
+		ansi[8] = p.tokens["outline"]
+		ansi[15] = p.tokens["surfaceContainerHigh"]
+	}
+
+	step := 0.08
+	if !p.Dark() {
+		step = -step
+	}
+	for slot := ansiRed; slot <= ansiCyan; slot++ {
+		ansi[slot+8] = brighten(ansi[slot], step)
+	}
+	return ansi
+}
+
+// brighten moves c by step OKLCH lightness, halving the step while sRGB can't hold c's
+// chroma at the new lightness. A pale yellow pushed to the top of the gamut would
+// otherwise come back white; one with no room left at all is kept as it is.
+func brighten(c colors.RGB, step float64) colors.RGB {
+	base := c.OKLCH()
+	for range 3 {
+		moved := colors.OKLCH{L: base.L + step, C: base.C, H: base.H}
+		if moved.L < 0 || moved.L > 1 {
+			step /= 2
+			continue
+		}
+		if out := moved.RGB(); out.OKLCH().C >= base.C*brightChromaKept {
+			return out
+		}
+		step /= 2
+	}
+	return c
+}
+
+// brightChromaKept is the share of a normal color's chroma its bright slot must keep.
+const brightChromaKept = 0.8
@@ -0,0 +1,80 @@
+package themeexport
+
+import (
+	"fmt"
+	"strings"
+)
+
+var qtFormat = Format{
+	ID:          "qt",
+	Name:        "Qt color scheme (qt5ct/qt6ct)",
+	Extension:   "conf",
+	ContentType: "text/plain; charset=utf-8",
+	Render:      renderQt,
+}
+
+// qtRoles are the QPalette color roles in the order qt5ct and qt6ct read them, with the
+// DMS token each takes. Shadow has no token and is always black.
+var qtRoles = []struct {
+	role  string
+	token string
+}{
+	{"WindowText", "surfaceText"},
+	{"Button", "surfaceContainerHigh"},
+	{"Light", "surfaceContainerHighest"},
+	{"Midlight", "surfaceContainerHigh"},
+	{"Dark", "surfaceContainerLow"},
+	{"Mid", "surfaceContainer"},
+	{"Text", "surfaceText"},
+	{"BrightText", "primaryText"},
+	{"ButtonText", "surfaceText"},
+	{"Base", "surfaceContainerLow"},
+	{"Window", "surface"},
+	{"Shadow", ""},
+	{"Highlight", "primary"},
+	{"HighlightedText", "primaryText"},
+	{"Link", "primary"},
+	{"LinkVisited", "secondary"},
+	{"AlternateBase", "surfaceContainer"},
+	{"NoRole", "surface"},
+	{"ToolTipBase", "surfaceContainerHighest"},
+	{"ToolTipText", "surfaceText"},
+	{"PlaceholderText", "surfaceVariantText"},
+}
+
+// qtDisabledText are the roles that fade to outline in the disabled group.
+var qtDisabledText = map[string]bool{
+	"WindowText":      true,
+	"Text":            true,
+	"ButtonText":      true,
+	"HighlightedText": true,
+	"PlaceholderText": true,
+}
+
+// renderQt writes a qt5ct/qt6ct color scheme. Active and inactive share one palette;
+// the disabled group swaps text roles for outline. Qt wants colors as #AARRGGBB.
+func renderQt(p *Palette) ([]byte, error) {
+	group := func(disabled bool) string {
+		values := make([]string, len(qtRoles))
+		for i, r := range qtRoles {
+			token := r.token
+			if disabled && qtDisabledText[r.role] {
+				token = "outline"
+			}
+			hex := "#000000"
+			if token != "" {
+				hex = p.Hex(token)
+			}
+			values[i] = "#ff" + strings.ToLower(bareHex(hex))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", header(p))
+	b.WriteString("[ColorScheme]\n")
+	fmt.Fprintf(&b, "active_colors=%s\n", group(false))
+	fmt.Fprintf(&b, "disabled_colors=%s\n", group(true))
+	fmt.Fprintf(&b, "inactive_colors=%s\n", group(false))
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,123 @@
+package themeexport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The terminal formats share one mapping on top of the ANSI palette from buildANSI:
+//
+//	foreground            surfaceText
+//	background            surface
+//	cursor                primary
+//	cursor text           primaryText
+//	selection background  primaryContainer
+//	selection foreground  surfaceText
+//	urls                  primary
+
+var kittyFormat = Format{
+	ID:          "kitty",
+	Name:        "kitty",
+	Extension:   "conf",
+	ContentType: "text/plain; charset=utf-8",
+	Render:      renderKitty,
+}
+
+// renderKitty writes a kitty.conf include. Beyond the shared terminal mapping, tab and
+// window borders follow DMS's bar: the active tab is primary on primaryText, inactive
+// tabs are surfaceVariantText on surfaceContainer, and borders are primary and outline.
+func renderKitty(p *Palette) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", header(p))
+
+	kv := func(key, value string) { fmt.Fprintf(&b, "%-24s %s\n", key, value) }
+	kv("foreground", p.Hex("surfaceText"))
+	kv("background", p.Hex("surface"))
+	kv("selection_foreground", p.Hex("surfaceText"))
+	kv("selection_background", p.Hex("primaryContainer"))
+	kv("cursor", p.Hex("primary"))
+	kv("cursor_text_color", p.Hex("primaryText"))
+	kv("url_color", p.Hex("primary"))
+	b.WriteString("\n")
+	kv("active_border_color", p.Hex("primary"))
+	kv("inactive_border_color", p.Hex("outline"))
+	kv("bell_border_color", p.Hex("warning"))
+	kv("active_tab_foreground", p.Hex("primaryText"))
+	kv("active_tab_background", p.Hex("primary"))
+	kv("inactive_tab_foreground", p.Hex("surfaceVariantText"))
+	kv("inactive_tab_background", p.Hex("surfaceContainer"))
+	kv("tab_bar_background", p.Hex("surfaceContainerLow"))
+	b.WriteString("\n")
+	for i, c := range p.ANSI {
+		kv(fmt.Sprintf("color%d", i), c.Hex())
+	}
+	return []byte(b.String()), nil
+}
+
+var footFormat = Format{
+	ID:          "foot",
+	Name:        "foot",
+	Extension:   "ini",
+	ContentType: "text/plain; charset=utf-8",
+	Render:      renderFoot,
+}
+
+// renderFoot writes the [colors] and [cursor] sections of foot.ini. foot takes colors as
+// bare RRGGBB and the cursor as "text cursor".
+func renderFoot(p *Palette) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", header(p))
+
+	b.WriteString("[cursor]\n")
+	fmt.Fprintf(&b, "color=%s %s\n\n", bareHex(p.Hex("primaryText")), bareHex(p.Hex("primary")))
+
+	b.WriteString("[colors]\n")
+	fmt.Fprintf(&b, "foreground=%s\n", bareHex(p.Hex("surfaceText")))
+	fmt.Fprintf(&b, "background=%s\n", bareHex(p.Hex("surface")))
+	fmt.Fprintf(&b, "selection-foreground=%s\n", bareHex(p.Hex("surfaceText")))
+	fmt.Fprintf(&b, "selection-background=%s\n", bareHex(p.Hex("primaryContainer")))
+	fmt.Fprintf(&b, "urls=%s\n", bareHex(p.Hex("primary")))
+	for i := range 8 {
+		fmt.Fprintf(&b, "regular%d=%s\n", i, bareHex(p.ANSI[i].Hex()))
+	}
+	for i := range 8 {
+		fmt.Fprintf(&b, "bright%d=%s\n", i, bareHex(p.ANSI[i+8].Hex()))
+	}
+	return []byte(b.String()), nil
+}
+
+var alacrittyFormat = Format{
+	ID:          "alacritty",
+	Name:        "Alacritty",
+	Extension:   "toml",
+	ContentType: "application/toml",
+	Render:      renderAlacritty,
+}
+
+// renderAlacritty writes the [colors] tables of alacritty.toml, naming the ANSI slots
+// black through white under normal and bright.
+func renderAlacritty(p *Palette) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", header(p))
+
+	table := func(name string, pairs ...string) {
+		fmt.Fprintf(&b, "\n[colors.%s]\n", name)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			fmt.Fprintf(&b, "%s = %q\n", pairs[i], pairs[i+1])
+		}
+	}
+	table("primary", "background", p.Hex("surface"), "foreground", p.Hex("surfaceText"))
+	table("cursor", "text", p.Hex("primaryText"), "cursor", p.Hex("primary"))
+	table("selection", "text", p.Hex("surfaceText"), "background", p.Hex("primaryContainer"))
+	table("hints.start", "foreground", p.Hex("primaryText"), "background", p.Hex("primary"))
+
+	normal := make([]string, 0, 16)
+	bright := make([]string, 0, 16)
+	for i, name := range ansiNames {
+		normal = append(normal, name, p.ANSI[i].Hex())
+		bright = append(bright, name, p.ANSI[i+8].Hex())
+	}
+	table("normal", normal...)
+	table("bright", bright...)
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,130 @@
+package themeexport
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var vscodeFormat = Format{
+	ID:          "vscode",
+	Name:        "VS Code color theme",
+	Extension:   "json",
+	ContentType: "application/json",
+	Render:      renderVSCode,
+}
+
+// vscodeColors maps workbench color ids onto DMS tokens. The editor sits on surface and
+// the chrome around it on the container tones, as DMS layers its bar over the desktop.
+var vscodeColors = map[string]string{
+	"focusBorder":                       "primary",
+	"foreground":                        "surfaceText",
+	"descriptionForeground":             "surfaceVariantText",
+	"errorForeground":                   "error",
+	"editor.background":                 "surface",
+	"editor.foreground":                 "surfaceText",
+	"editor.lineHighlightBackground":    "surfaceContainer",
+	"editor.selectionBackground":        "primaryContainer",
+	"editorCursor.foreground":           "primary",
+	"editorLineNumber.foreground":       "outline",
+	"editorLineNumber.activeForeground": "surfaceText",
+	"editorError.foreground":            "error",
+	"editorWarning.foreground":          "warning",
+	"editorInfo.foreground":             "info",
+	"activityBar.background":            "surfaceContainer",
+	"activityBar.foreground":            "surfaceText",
+	"activityBar.inactiveForeground":    "surfaceVariantText",
+	"activityBarBadge.background":       "primary",
+	"activityBarBadge.foreground":       "primaryText",
+	"sideBar.background":                "surfaceContainerLow",
+	"sideBar.foreground":                "surfaceVariantText",
+	"sideBarSectionHeader.background":   "surfaceContainer",
+	"statusBar.background":              "surfaceContainer",
+	"statusBar.foreground":              "surfaceVariantText",
+	"titleBar.activeBackground":         "surfaceContainer",
+	"titleBar.activeForeground":         "surfaceText",
+	"titleBar.inactiveBackground":       "surfaceContainerLow",
+	"titleBar.inactiveForeground":       "surfaceVariantText",
+	"tab.activeBackground":              "surface",
+	"tab.activeForeground":              "surfaceText",
+	"tab.inactiveBackground":            "surfaceContainer",
+	"tab.inactiveForeground":            "surfaceVariantText",
+	"editorGroupHeader.tabsBackground":  "surfaceContainer",
+	"panel.background":                  "surfaceContainer",
+	"panel.border":                      "outline",
+	"input.background":                  "surfaceContainerHigh",
+	"input.foreground":                  "surfaceText",
+	"input.placeholderForeground":       "surfaceVariantText",
+	"dropdown.background":               "surfaceContainerHigh",
+	"dropdown.foreground":               "surfaceText",
+	"button.background":                 "primary",
+	"button.foreground":                 "primaryText",
+	"badge.background":                  "primary",
+	"badge.foreground":                  "primaryText",
+	"list.activeSelectionBackground":    "primaryContainer",
+	"list.activeSelectionForeground":    "surfaceText",
+	"list.hoverBackground":              "surfaceContainerHigh",
+	"editorWidget.background":           "surfaceContainerHigh",
+	"terminal.background":               "surface",
+	"terminal.foreground":               "surfaceText",
+	"terminalCursor.foreground":         "primary",
+}
+
+// vscodeANSINames are the terminal.ansi* suffixes in palette order.
+var vscodeANSINames = [8]string{"Black", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "White"}
+
+type vscodeTokenColor struct {
+	Name     string            `json:"name"`
+	Scope    []string          `json:"scope"`
+	Settings map[string]string `json:"settings"`
+}
+
+type vscodeTheme struct {
+	Schema      string             `json:"$schema"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	Colors      map[string]string  `json:"colors"`
+	TokenColors []vscodeTokenColor `json:"tokenColors"`
+}
+
+// renderVSCode writes a color theme for a VS Code extension's contributes.themes.
+// Syntax colors come from the accents: keywords primary, storage and types secondary,
+// functions blue, strings green, numbers and constants magenta, comments outline.
+func renderVSCode(p *Palette) ([]byte, error) {
+	theme := vscodeTheme{
+		Schema: "vscode://schemas/color-theme",
+		Name:   fmt.Sprintf("%s (%s)", p.Name, p.Mode),
+		Type:   p.Mode,
+		Colors: make(map[string]string, len(vscodeColors)+16),
+	}
+	for id, token := range vscodeColors {
+		theme.Colors[id] = p.Hex(token)
+	}
+	for i, name := range vscodeANSINames {
+		theme.Colors["terminal.ansi"+name] = p.ANSI[i].Hex()
+		theme.Colors["terminal.ansiBright"+name] = p.ANSI[i+8].Hex()
+	}
+
+	scope := func(name, hex, style string, scopes ...string) vscodeTokenColor {
+		settings := map[string]string{"foreground": hex}
+		if style != "" {
+			settings["fontStyle"] = style
+		}
+		return vscodeTokenColor{Name: name, Scope: scopes, Settings: settings}
+	}
+	theme.TokenColors = []vscodeTokenColor{
+		scope("Comments", p.Hex("outline"), "italic", "comment", "punctuation.definition.comment"),
+		scope("Keywords", p.Hex("primary"), "", "keyword", "keyword.control", "storage.modifier"),
+		scope("Storage and types", p.Hex("secondary"), "", "storage.type", "entity.name.type", "support.type"),
+		scope("Functions", p.ANSI[ansiBlue].Hex(), "", "entity.name.function", "support.function"),
+		scope("Strings", p.ANSI[ansiGreen].Hex(), "", "string", "string.quoted"),
+		scope("Numbers and constants", p.ANSI[ansiMagenta].Hex(), "", "constant.numeric", "constant.language", "constant.character"),
+		scope("Variables", p.Hex("surfaceText"), "", "variable", "meta.definition.variable"),
+		scope("Invalid", p.Hex("error"), "", "invalid", "invalid.illegal"),
+	}
+
+	out, err := json.MarshalIndent(theme, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,270 @@
+package themeimport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+// Document is the shape of a theme.json in the registry repo.
+type Document struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version"`
+	Author      string                 `json:"author"`
+	Description string                 `json:"description"`
+	Dark        map[string]interface{} `json:"dark"`
+	Light       map[string]interface{} `json:"light"`
+}
+
+type Options struct {
+	ID     string
+	Name   string
+	Author string
+	// Accent is the ANSI color name to use as primary; blue when empty.
+	Accent string
+}
+
+// Result is a converted theme plus how the source was read.
+type Result struct {
+	Theme      Document
+	SourceMode string
+	// Derived lists the tokens of the source mode that had no direct counterpart in
+	// the input and were computed, so authors know what to review first.
+	Derived []string
+}
+
+var idUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Convert builds both modes of a theme from a parsed palette. The source's own mode is
+// taken as-is, derived tokens aside, so its WCAG report reflects the original palette.
+// The opposite mode is a mirror: neutrals have their lightness reflected across the
+// range and accents are nudged until they read on the new surfaces.
+func Convert(src *Source, opts Options) (*Result, error) {
+	accent := opts.Accent
+	if accent == "" {
+		accent = "blue"
+	}
+	slot, ok := ansiSlots[accent]
+	if !ok || slot == 0 || slot == 7 {
+		return nil, fmt.Errorf("unknown accent %q", accent)
+	}
+
+	name := firstNonEmpty(opts.Name, src.Name, "Converted Theme")
+	id := opts.ID
+	if id == "" {
+		id = strings.Trim(idUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	}
+
+	sourceMode := "dark"
+	if src.Background.Luminance() > src.Foreground.Luminance() {
+		sourceMode = "light"
+	}
+
+	base := sourceRoles(src, slot)
+	mirrored := mirrorRoles(base, sourceMode)
+
+	description := fmt.Sprintf("Converted from a %s color scheme", src.Format)
+	if src.Name != "" {
+		description = fmt.Sprintf("Converted from the %s scheme %q", src.Format, src.Name)
+	}
+	doc := Document{
+		ID:          id,
+		Name:        name,
+		Version:     "1.0.0",
+		Author:      firstNonEmpty(opts.Author, src.Author),
+		Description: description,
+	}
+	sourceScheme, derived := buildScheme(base)
+	mirroredScheme, _ := buildScheme(mirrored)
+	if sourceMode == "dark" {
+		doc.Dark, doc.Light = sourceScheme, mirroredScheme
+	} else {
+		doc.Dark, doc.Light = mirroredScheme, sourceScheme
+	}
+
+	return &Result{Theme: doc, SourceMode: sourceMode, Derived: derived}, nil
+}
+
+// roles are the palette colors a DMS scheme is built from. Optional roles are nil when
+// the source has nothing that maps onto them.
+type roles struct {
+	bg, fg                   colors.RGB
+	container, containerHigh *colors.RGB
+	variantText, outline     *colors.RGB
+	primary, secondary       colors.RGB
+	red, yellow, blue        colors.RGB
+	dark                     bool
+}
+
+// sourceRoles reads roles off the palette. base16 names its UI grays, so those map
+// directly: base01 and base02 become the container tones, base04 the secondary text
+// and base03 the outline. Terminal schemes only offer bright black for the outline, and
+// only when they set one apart from black.
+func sourceRoles(src *Source, accent int) roles {
+	r := roles{
+		bg:      src.Background,
+		fg:      src.Foreground,
+		primary: src.ANSI[accent],
+		red:     src.ANSI[1],
+		yellow:  src.ANSI[3],
+		blue:    src.ANSI[4],
+		dark:    src.Background.Luminance() <= src.Foreground.Luminance(),
+	}
+
+	secondary := 5
+	if accent == 5 {
+		secondary = 6
+	}
+	r.secondary = src.ANSI[secondary]
+
+	if src.Base != nil {
+		r.container = ptr(src.Base["base01"])
+		r.containerHigh = ptr(src.Base["base02"])
+		r.outline = ptr(src.Base["base03"])
+		r.variantText = ptr(src.Base["base04"])
+	} else if src.ANSI[8] != src.ANSI[0] {
+		r.outline = ptr(src.ANSI[8])
+	}
+	return r
+}
+
+// mirrorRoles builds the opposite mode. Neutrals keep hue and chroma while their
+// lightness is mapped linearly from the source's background-to-foreground range onto
+// the target mode's, so tinted grays stay tinted.
+func mirrorRoles(src roles, sourceMode string) roles {
+	bgL, fgL := src.bg.OKLCH().L, src.fg.OKLCH().L
+	targetBg, targetFg := 0.97, 0.32
+	if sourceMode == "light" {
+		targetBg, targetFg = 0.2, 0.92
+	}
+
+	mirror := func(c colors.RGB) colors.RGB {
+		lch := c.OKLCH()
+		t := 0.0
+		if fgL != bgL {
+			t = (lch.L - bgL) / (fgL - bgL)
+		}
+		lch.L = targetBg + t*(targetFg-targetBg)
+		return lch.RGB()
+	}
+	mirrorOpt := func(c *colors.RGB) *colors.RGB {
+		if c == nil {
+			return nil
+		}
+		return ptr(mirror(*c))
+	}
+
+	out := roles{
+		bg:            mirror(src.bg),
+		fg:            mirror(src.fg),
+		container:     mirrorOpt(src.container),
+		containerHigh: mirrorOpt(src.containerHigh),
+		variantText:   mirrorOpt(src.variantText),
+		outline:       mirrorOpt(src.outline),
+		dark:          !src.dark,
+	}
+
+	// Accents are checked against the container tone DMS draws them on.
+	surface := colors.Mix(out.bg, out.fg, 0.06)
+	if out.container != nil {
+		surface = *out.container
+	}
+	out.primary, _ = colors.EnsureContrast(src.primary, surface, 4.5)
+	out.secondary, _ = colors.EnsureContrast(src.secondary, surface, 4.5)
+	out.red, _ = colors.EnsureContrast(src.red, surface, 3)
+	out.yellow, _ = colors.EnsureContrast(src.yellow, surface, 3)
+	out.blue, _ = colors.EnsureContrast(src.blue, surface, 3)
+	return out
+}
+
+// buildScheme fills every DMS token from the roles, returning the tokens that were
+// computed rather than read from the source.
+//
+//	surface, background            bg
+//	surfaceText, backgroundText    fg
+//	surfaceContainer               base01, or bg mixed 6% toward fg
+//	surfaceContainerHigh           base02, or bg mixed 12% toward fg
+//	surfaceContainerHighest        surfaceContainerHigh mixed 8% toward fg
+//	surfaceContainerLow            halfway between bg and surfaceContainer
+//	surfaceContainerLowest         bg moved 0.03 OKLCH lightness away from fg
+//	surfaceVariant                 surfaceContainerHigh
+//	surfaceVariantText             base04, or fg mixed 25% toward bg
+//	outline                        base03 or bright black, else bg mixed 40% toward fg
+//	primary, surfaceTint           the chosen accent
+//	primaryText                    bg if it reaches 4.5:1 on primary, else black or white
+//	primaryContainer               primary mixed 30% into bg
+//	secondary                      magenta, or cyan when magenta is the accent
+//	error, warning, info           red, yellow, blue
+func buildScheme(r roles) (map[string]interface{}, []string) {
+	var derived []string
+	pick := func(token string, c *colors.RGB, fallback func() colors.RGB) colors.RGB {
+		if c != nil {
+			return *c
+		}
+		derived = append(derived, token)
+		return fallback()
+	}
+
+	container := pick("surfaceContainer", r.container, func() colors.RGB { return colors.Mix(r.bg, r.fg, 0.06) })
+	containerHigh := pick("surfaceContainerHigh", r.containerHigh, func() colors.RGB { return colors.Mix(r.bg, r.fg, 0.12) })
+	variantText := pick("surfaceVariantText", r.variantText, func() colors.RGB { return colors.Mix(r.fg, r.bg, 0.25) })
+	outline := pick("outline", r.outline, func() colors.RGB { return colors.Mix(r.bg, r.fg, 0.4) })
+
+	lowest := r.bg.OKLCH()
+	if r.dark {
+		lowest.L -= 0.03
+	} else {
+		lowest.L += 0.03
+	}
+
+	primaryText := r.bg
+	if colors.Contrast(primaryText, r.primary) < 4.5 {
+		primaryText = colors.ReadableOn(r.primary)
+	}
+
+	derived = append(derived,
+		"surfaceContainerHighest", "surfaceContainerLow", "surfaceContainerLowest",
+		"primaryText", "primaryContainer",
+	)
+
+	scheme := map[string]interface{}{
+		"primary":                 r.primary.Hex(),
+		"primaryText":             primaryText.Hex(),
+		"primaryContainer":        colors.Mix(r.bg, r.primary, 0.3).Hex(),
+		"secondary":               r.secondary.Hex(),
+		"surface":                 r.bg.Hex(),
+		"surfaceText":             r.fg.Hex(),
+		"surfaceVariant":          containerHigh.Hex(),
+		"surfaceVariantText":      variantText.Hex(),
+		"surfaceTint":             r.primary.Hex(),
+		"background":              r.bg.Hex(),
+		"backgroundText":          r.fg.Hex(),
+		"outline":                 outline.Hex(),
+		"surfaceContainerLowest":  lowest.RGB().Hex(),
+		"surfaceContainerLow":     colors.Mix(r.bg, container, 0.5).Hex(),
+		"surfaceContainer":        container.Hex(),
+		"surfaceContainerHigh":    containerHigh.Hex(),
+		"surfaceContainerHighest": colors.Mix(containerHigh, r.fg, 0.08).Hex(),
+		"error":                   r.red.Hex(),
+		"warning":                 r.yellow.Hex(),
+		"info":                    r.blue.Hex(),
+		"matugen_type":            "scheme-tonal-spot",
+	}
+	return scheme, derived
+}
+
+func ptr(c colors.RGB) *colors.RGB {
+	return &c
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
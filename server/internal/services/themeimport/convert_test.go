@@ -0,0 +1,120 @@
+package themeimport
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+// dmsTokens is every token a registry theme.json sets per mode.
+var dmsTokens = []string{
+	"primary", "primaryText", "primaryContainer", "secondary",
+	"surface", "surfaceText", "surfaceVariant", "surfaceVariantText", "surfaceTint",
+	"background", "backgroundText", "outline",
+	"surfaceContainerLowest", "surfaceContainerLow", "surfaceContainer",
+	"surfaceContainerHigh", "surfaceContainerHighest",
+	"error", "warning", "info", "matugen_type",
+}
+
+func convertFixture(t *testing.T, file string, opts Options) *Result {
+	t.Helper()
+	src, err := Parse(readFixture(t, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Convert(src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func tokenColor(t *testing.T, scheme map[string]interface{}, token string) colors.RGB {
+	t.Helper()
+	c, ok := colors.ParseHex(scheme[token].(string))
+	if !ok {
+		t.Fatalf("%s is not a color: %v", token, scheme[token])
+	}
+	return c
+}
+
+func TestConvertFillsEveryToken(t *testing.T) {
+	for _, file := range []string{"mocha.yaml", "legacy-light.yaml", "kitty.conf", "alacritty.toml", "foot.ini", "Xresources"} {
+		result := convertFixture(t, file, Options{})
+		for mode, scheme := range map[string]map[string]interface{}{"dark": result.Theme.Dark, "light": result.Theme.Light} {
+			for _, token := range dmsTokens {
+				if _, ok := scheme[token]; !ok {
+					t.Fatalf("%s %s: missing %s", file, mode, token)
+				}
+			}
+			surface := tokenColor(t, scheme, "surface")
+			text := tokenColor(t, scheme, "surfaceText")
+			if (mode == "dark") != (surface.Luminance() < text.Luminance()) {
+				t.Fatalf("%s %s: surface %s and text %s are the wrong way round", file, mode, surface.Hex(), text.Hex())
+			}
+		}
+	}
+}
+
+func TestConvertKeepsSourceModeColors(t *testing.T) {
+	result := convertFixture(t, "mocha.yaml", Options{Accent: "magenta"})
+	if result.SourceMode != "dark" || result.Theme.ID != "catppuccin-mocha" {
+		t.Fatalf("got mode %s id %s", result.SourceMode, result.Theme.ID)
+	}
+
+	dark := result.Theme.Dark
+	want := map[string]string{
+		"surface":              "#1E1E2E",
+		"surfaceText":          "#CDD6F4",
+		"surfaceContainer":     "#181825",
+		"surfaceContainerHigh": "#313244",
+		"outline":              "#45475A",
+		"primary":              "#CBA6F7",
+		"secondary":            "#94E2D5",
+		"error":                "#F38BA8",
+	}
+	for token, hex := range want {
+		if dark[token] != hex {
+			t.Fatalf("dark %s: expected %s, got %v", token, hex, dark[token])
+		}
+	}
+	if slices.Contains(result.Derived, "surfaceContainer") || !slices.Contains(result.Derived, "primaryText") {
+		t.Fatalf("unexpected derived list %v", result.Derived)
+	}
+}
+
+func TestConvertNudgesMirroredAccents(t *testing.T) {
+	result := convertFixture(t, "mocha.yaml", Options{})
+	light := result.Theme.Light
+
+	container := tokenColor(t, light, "surfaceContainer")
+	if ratio := colors.Contrast(tokenColor(t, light, "primary"), container); ratio < 4.5 {
+		t.Fatalf("mirrored primary only reaches %.2f:1", ratio)
+	}
+	for _, token := range []string{"error", "warning", "info"} {
+		if ratio := colors.Contrast(tokenColor(t, light, token), container); ratio < 3 {
+			t.Fatalf("mirrored %s only reaches %.2f:1", token, ratio)
+		}
+	}
+}
+
+func TestConvertRejectsNeutralAccent(t *testing.T) {
+	src, err := Parse(readFixture(t, "kitty.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Convert(src, Options{Accent: "white"}); err == nil {
+		t.Fatal("expected an error for a neutral accent")
+	}
+}
+
+func TestConvertDerivesOutlineWithoutBrightBlack(t *testing.T) {
+	result := convertFixture(t, "foot.ini", Options{})
+	if !slices.Contains(result.Derived, "outline") {
+		t.Fatalf("expected outline to be derived, got %v", result.Derived)
+	}
+	if result.Theme.Dark["outline"] == "#45475A" {
+		t.Fatal("outline should not fall back to black")
+	}
+}
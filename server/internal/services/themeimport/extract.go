@@ -0,0 +1,197 @@
+package themeimport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"sort"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// maxWallpaperDimension bounds a decoded upload at 64 MB of RGBA. Only a 128px
+	// sample is clustered, so larger images would add memory and nothing else.
+	maxWallpaperDimension = 4096
+	sampleSide            = 128
+	clusterCount          = 8
+	clusterIterations     = 12
+	// minSeedChroma keeps near-grays out of the seed candidates unless an image has
+	// nothing more colorful, since a gray seed makes a flat theme.
+	minSeedChroma = 0.03
+	// minSeedShare drops clusters of stray pixels, which farthest-point seeding is
+	// quick to pick up.
+	minSeedShare = 0.01
+)
+
+var ErrNoColors = errors.New("image has no opaque pixels")
+
+// DominantColor is one cluster of an image's palette.
+type DominantColor struct {
+	Color colors.RGB
+	// Share is the fraction of sampled pixels in the cluster.
+	Share float64
+	Score float64
+}
+
+// DecodeWallpaper decodes a PNG, JPEG, GIF or WebP upload, checking its dimensions
+// before allocating pixels.
+func DecodeWallpaper(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image config: %w", err)
+	}
+	if cfg.Width > maxWallpaperDimension || cfg.Height > maxWallpaperDimension {
+		return nil, fmt.Errorf("image dimensions %dx%d exceed limit", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// DominantColors clusters an image's pixels in OKLab and ranks the clusters as seed
+// candidates, best first. Ranking weighs how much of the image a cluster covers against
+// how colorful it is, like Material's wallpaper scoring: a small vivid accent can beat
+// a large gray sky.
+func DominantColors(img image.Image) ([]DominantColor, error) {
+	samples := samplePixels(img)
+	if len(samples) == 0 {
+		return nil, ErrNoColors
+	}
+
+	centers := initialCenters(samples, clusterCount)
+	assignment := make([]int, len(samples))
+	for range clusterIterations {
+		for i, s := range samples {
+			assignment[i] = nearestCenter(centers, s)
+		}
+
+		sums := make([]colors.OKLab, len(centers))
+		counts := make([]int, len(centers))
+		for i, s := range samples {
+			k := assignment[i]
+			sums[k].L += s.L
+			sums[k].A += s.A
+			sums[k].B += s.B
+			counts[k]++
+		}
+		for k := range centers {
+			if counts[k] == 0 {
+				continue
+			}
+			n := float64(counts[k])
+			centers[k] = colors.OKLab{L: sums[k].L / n, A: sums[k].A / n, B: sums[k].B / n}
+		}
+	}
+
+	counts := make([]int, len(centers))
+	for _, k := range assignment {
+		counts[k]++
+	}
+
+	result := make([]DominantColor, 0, len(centers))
+	for k, center := range centers {
+		if counts[k] == 0 {
+			continue
+		}
+		share := float64(counts[k]) / float64(len(samples))
+		chroma := center.OKLCH().C
+		result = append(result, DominantColor{
+			Color: center.RGB().Clamp(),
+			Share: share,
+			Score: 0.6*math.Sqrt(share) + 0.4*math.Min(chroma/0.15, 1),
+		})
+	}
+
+	colorful := make([]DominantColor, 0, len(result))
+	for _, c := range result {
+		if c.Color.OKLCH().C >= minSeedChroma && c.Share >= minSeedShare {
+			colorful = append(colorful, c)
+		}
+	}
+	if len(colorful) > 0 {
+		result = colorful
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	return result, nil
+}
+
+// samplePixels reads at most sampleSide x sampleSide evenly spaced opaque pixels.
+func samplePixels(img image.Image) []colors.OKLab {
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/sampleSide)
+	stepY := max(1, bounds.Dy()/sampleSide)
+
+	samples := make([]colors.OKLab, 0, sampleSide*sampleSide)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0xF000 {
+				continue
+			}
+			c := colors.RGB{R: float64(r) / float64(a), G: float64(g) / float64(a), B: float64(b) / float64(a)}
+			samples = append(samples, c.OKLab())
+		}
+	}
+	return samples
+}
+
+// initialCenters seeds k-means deterministically with farthest-point sampling from the
+// mean, so the same image always yields the same palette.
+func initialCenters(samples []colors.OKLab, k int) []colors.OKLab {
+	var mean colors.OKLab
+	for _, s := range samples {
+		mean.L += s.L
+		mean.A += s.A
+		mean.B += s.B
+	}
+	n := float64(len(samples))
+	mean = colors.OKLab{L: mean.L / n, A: mean.A / n, B: mean.B / n}
+
+	centers := []colors.OKLab{mean}
+	dist := make([]float64, len(samples))
+	for i, s := range samples {
+		dist[i] = labDistance(s, mean)
+	}
+	for len(centers) < k {
+		farthest := 0
+		for i := range samples {
+			if dist[i] > dist[farthest] {
+				farthest = i
+			}
+		}
+		if dist[farthest] == 0 {
+			break
+		}
+		next := samples[farthest]
+		centers = append(centers, next)
+		for i, s := range samples {
+			dist[i] = math.Min(dist[i], labDistance(s, next))
+		}
+	}
+	return centers
+}
+
+func nearestCenter(centers []colors.OKLab, s colors.OKLab) int {
+	best, bestDist := 0, math.Inf(1)
+	for k, c := range centers {
+		if d := labDistance(s, c); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func labDistance(a, b colors.OKLab) float64 {
+	return (a.L-b.L)*(a.L-b.L) + (a.A-b.A)*(a.A-b.A) + (a.B-b.B)*(a.B-b.B)
+}
@@ -0,0 +1,68 @@
+package themeimport
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestDominantColorsPrefersColorOverGray(t *testing.T) {
+	// A mostly gray wallpaper with an orange band across the bottom third.
+	img := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	for y := range 300 {
+		for x := range 300 {
+			c := color.RGBA{R: 0x40, G: 0x42, B: 0x46, A: 0xFF}
+			if y >= 200 {
+				c = color.RGBA{R: 0xE8, G: 0x7A, B: 0x1E, A: 0xFF}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeWallpaper(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dominant, err := DominantColors(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dominant[0].Color.Hex(); got != "#E87A1E" {
+		t.Fatalf("expected the orange band to seed the theme, got %s", got)
+	}
+	if math.Abs(dominant[0].Share-1.0/3) > 0.02 {
+		t.Fatalf("expected about a third of the image, got %.3f", dominant[0].Share)
+	}
+}
+
+func TestDominantColorsNeedsOpaquePixels(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	if _, err := DominantColors(img); !errors.Is(err, ErrNoColors) {
+		t.Fatalf("expected ErrNoColors, got %v", err)
+	}
+}
+
+func TestDecodeWallpaperRejectsGarbage(t *testing.T) {
+	if _, err := DecodeWallpaper([]byte("not an image")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDecodeWallpaperRejectsOversizedImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, maxWallpaperDimension+1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeWallpaper(buf.Bytes()); err == nil {
+		t.Fatal("expected an image wider than the limit to be refused")
+	}
+}
@@ -0,0 +1,161 @@
+package themeimport
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+)
+
+// Chroma of each tonal palette, in OKLCH units, following Material 3's tonal-spot
+// scheme: a moderately colorful primary over near-neutral surfaces tinted by the seed.
+const (
+	minPrimaryChroma   = 0.08
+	maxPrimaryChroma   = 0.16
+	secondaryChroma    = 0.045
+	neutralChroma      = 0.012
+	neutralVarChroma   = 0.025
+	maxHarmonizeDegree = 15
+	// bodyTarget sits just above AA so hex rounding cannot drop a nudged pair back under.
+	bodyTarget = 4.52
+)
+
+// Fixed hues for the status colors before harmonizing toward the seed.
+var (
+	errorPalette   = colors.TonalPalette{Hue: 25, Chroma: 0.16}
+	warningPalette = colors.TonalPalette{Hue: 80, Chroma: 0.13}
+	infoPalette    = colors.TonalPalette{Hue: 240, Chroma: 0.11}
+)
+
+// schemeTone names the palette and tone each DMS token takes, per mode. The tones are
+// Material 3's: primary 80 on 20 in dark, 40 on 100 in light, surfaces stepping up
+// from neutral 6 or down from neutral 98.
+type schemeTone struct {
+	palette string
+	dark    float64
+	light   float64
+}
+
+var generatedTones = map[string]schemeTone{
+	"primary":                 {"primary", 80, 40},
+	"primaryText":             {"primary", 20, 100},
+	"primaryContainer":        {"primary", 30, 90},
+	"surfaceTint":             {"primary", 80, 40},
+	"secondary":               {"secondary", 80, 40},
+	"surface":                 {"neutral", 6, 98},
+	"surfaceText":             {"neutral", 90, 10},
+	"background":              {"neutral", 6, 98},
+	"backgroundText":          {"neutral", 90, 10},
+	"surfaceContainerLowest":  {"neutral", 4, 100},
+	"surfaceContainerLow":     {"neutral", 10, 96},
+	"surfaceContainer":        {"neutral", 12, 94},
+	"surfaceContainerHigh":    {"neutral", 17, 92},
+	"surfaceContainerHighest": {"neutral", 22, 90},
+	"surfaceVariant":          {"neutralVariant", 30, 90},
+	"surfaceVariantText":      {"neutralVariant", 80, 30},
+	"outline":                 {"neutralVariant", 60, 50},
+	"error":                   {"error", 80, 40},
+	"warning":                 {"warning", 80, 40},
+	"info":                    {"info", 80, 40},
+}
+
+// Generated is a theme built from a seed color.
+type Generated struct {
+	Theme Document
+	Seed  colors.RGB
+	// Nudged lists "mode.token" entries whose tone was moved to get body text to AA.
+	Nudged []string
+}
+
+// Generate builds a two-mode theme from a seed color. The seed's hue drives every
+// palette; its chroma sets the primary's within the tonal-spot range, so a muted brand
+// color still yields a usable accent and a neon one is tamed.
+func Generate(seed colors.RGB, opts Options) *Generated {
+	lch := seed.OKLCH()
+	palettes := map[string]colors.TonalPalette{
+		"primary":        {Hue: lch.H, Chroma: math.Max(minPrimaryChroma, math.Min(lch.C, maxPrimaryChroma))},
+		"secondary":      {Hue: lch.H, Chroma: secondaryChroma},
+		"neutral":        {Hue: lch.H, Chroma: neutralChroma},
+		"neutralVariant": {Hue: lch.H, Chroma: neutralVarChroma},
+		"error":          harmonize(errorPalette, lch.H),
+		"warning":        harmonize(warningPalette, lch.H),
+		"info":           harmonize(infoPalette, lch.H),
+	}
+
+	name := firstNonEmpty(opts.Name, "Generated "+strings.TrimPrefix(seed.Hex(), "#"))
+	id := opts.ID
+	if id == "" {
+		id = strings.Trim(idUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	}
+
+	gen := &Generated{
+		Seed: seed,
+		Theme: Document{
+			ID:          id,
+			Name:        name,
+			Version:     "1.0.0",
+			Author:      opts.Author,
+			Description: fmt.Sprintf("Generated from seed color %s", seed.Hex()),
+		},
+	}
+
+	for _, mode := range []string{"dark", "light"} {
+		scheme := map[string]interface{}{"matugen_type": "scheme-tonal-spot"}
+		for token, tone := range generatedTones {
+			t := tone.dark
+			if mode == "light" {
+				t = tone.light
+			}
+			scheme[token] = palettes[tone.palette].Tone(t).Hex()
+		}
+
+		for _, token := range nudgeBody(scheme) {
+			gen.Nudged = append(gen.Nudged, mode+"."+token)
+		}
+		if mode == "dark" {
+			gen.Theme.Dark = scheme
+		} else {
+			gen.Theme.Light = scheme
+		}
+	}
+	return gen
+}
+
+// harmonize rotates a status hue up to 15 degrees toward the seed, as Material does
+// for custom colors, so red and amber sit with the theme without losing their meaning.
+func harmonize(p colors.TonalPalette, seedHue float64) colors.TonalPalette {
+	diff := math.Mod(seedHue-p.Hue+540, 360) - 180
+	rotation := math.Copysign(math.Min(math.Abs(diff)*0.5, maxHarmonizeDegree), diff)
+	p.Hue = math.Mod(p.Hue+rotation+360, 360)
+	return p
+}
+
+// nudgeBody moves the text side of the worst body pair until registry.SchemeWCAG no
+// longer fails the body group, returning the tokens it changed. Tonal-spot tones
+// already clear AA for every body pair, so this only fires for extreme seeds where the
+// gamut squeezes a palette.
+func nudgeBody(scheme map[string]interface{}) []string {
+	var nudged []string
+	for range 16 {
+		report := registry.SchemeWCAG(scheme)
+		if report == nil || report.Body == nil || report.Body.Level != "fail" {
+			break
+		}
+
+		fgToken, bgToken := report.Body.WorstPair[0], report.Body.WorstPair[1]
+		fg, _ := colors.ParseHex(scheme[fgToken].(string))
+		bg, _ := colors.ParseHex(scheme[bgToken].(string))
+		fixed, ok := colors.EnsureContrast(fg, bg, bodyTarget)
+		scheme[fgToken] = fixed.Hex()
+		if !slices.Contains(nudged, fgToken) {
+			nudged = append(nudged, fgToken)
+		}
+		if !ok {
+			break
+		}
+	}
+	return nudged
+}
@@ -0,0 +1,75 @@
+package themeimport
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/services/registry"
+)
+
+func TestGenerateReachesBodyAA(t *testing.T) {
+	// Muted, saturated, very light and very dark seeds across the hue circle.
+	for _, hex := range []string{"#6750A4", "#FF00FF", "#FFFF00", "#00FF00", "#808080", "#0A0A14", "#FFF8E1"} {
+		seed, _ := colors.ParseHex(hex)
+		gen := Generate(seed, Options{})
+
+		for mode, scheme := range map[string]map[string]interface{}{"dark": gen.Theme.Dark, "light": gen.Theme.Light} {
+			for _, token := range dmsTokens {
+				if _, ok := scheme[token]; !ok {
+					t.Fatalf("%s %s: missing %s", hex, mode, token)
+				}
+			}
+			report := registry.SchemeWCAG(scheme)
+			if report == nil || report.Body == nil || report.Body.Level == "fail" {
+				t.Fatalf("%s %s: body does not reach AA: %+v", hex, mode, report)
+			}
+		}
+	}
+}
+
+func TestGenerateFollowsSeedHue(t *testing.T) {
+	seed, _ := colors.ParseHex("#1E66F5")
+	gen := Generate(seed, Options{Name: "Brand Blue"})
+	if gen.Theme.ID != "brand-blue" {
+		t.Fatalf("got id %q", gen.Theme.ID)
+	}
+
+	primary, _ := colors.ParseHex(gen.Theme.Light["primary"].(string))
+	if diff := math.Abs(primary.OKLCH().H - seed.OKLCH().H); diff > 3 {
+		t.Fatalf("primary hue drifted by %.1f degrees", diff)
+	}
+	if tone := primary.Tone(); math.Abs(tone-40) > 1 {
+		t.Fatalf("light primary should sit at tone 40, got %.1f", tone)
+	}
+}
+
+func TestNudgeBodyFixesFailingText(t *testing.T) {
+	scheme := map[string]interface{}{
+		"surface":                 "#141218",
+		"surfaceContainer":        "#211F26",
+		"surfaceContainerHigh":    "#2B2930",
+		"surfaceContainerHighest": "#36343B",
+		"surfaceText":             "#E6E0E9",
+		"surfaceVariantText":      "#5A5560",
+		"primary":                 "#D0BCFF",
+		"primaryText":             "#381E72",
+	}
+
+	nudged := nudgeBody(scheme)
+	if len(nudged) != 1 || nudged[0] != "surfaceVariantText" {
+		t.Fatalf("expected only surfaceVariantText to move, got %v", nudged)
+	}
+	if report := registry.SchemeWCAG(scheme); report.Body.Level == "fail" {
+		t.Fatalf("body still fails at %.2f:1", report.Body.MinRatio)
+	}
+}
+
+func TestHarmonizeCapsRotation(t *testing.T) {
+	if got := harmonize(errorPalette, 35).Hue; math.Abs(got-30) > 1e-9 {
+		t.Fatalf("expected half the 10 degree gap, got %v", got)
+	}
+	if got := harmonize(errorPalette, 200).Hue; math.Abs(got-40) > 1e-9 {
+		t.Fatalf("expected a 15 degree cap, got %v", got)
+	}
+}
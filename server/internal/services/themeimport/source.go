@@ -0,0 +1,247 @@
+// Package themeimport turns palettes written for other tools, base16/base24 schemes
+// and terminal color configs, into DMS theme.json documents.
+package themeimport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankLinux-Docs/server/internal/colors"
+)
+
+// ErrUnrecognized is returned for input that is neither a complete base16/base24
+// scheme nor a terminal config with a background, foreground and the 8 normal colors.
+var ErrUnrecognized = errors.New("unrecognized color scheme")
+
+const (
+	SourceBase16   = "base16"
+	SourceBase24   = "base24"
+	SourceTerminal = "terminal"
+)
+
+// Source is a parsed input palette. ANSI is always filled in; Base only for base16 and
+// base24 input, keyed base00 through base17.
+type Source struct {
+	Format     string
+	Name       string
+	Author     string
+	Background colors.RGB
+	Foreground colors.RGB
+	ANSI       [16]colors.RGB
+	Base       map[string]colors.RGB
+}
+
+var (
+	hexValuePattern = regexp.MustCompile(`(?:#|0x|\b)([0-9A-Fa-f]{6})\b`)
+	sectionPattern  = regexp.MustCompile(`^\[([^\]]+)\]$`)
+	basePattern     = regexp.MustCompile(`^base[0-1][0-9a-f]$`)
+	indexedPattern  = regexp.MustCompile(`^(color|regular|bright)(\d{1,2})$`)
+)
+
+// ansiSlots are the color names alacritty and ghostty-style configs key by.
+var ansiSlots = map[string]int{
+	"black": 0, "red": 1, "green": 2, "yellow": 3,
+	"blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+}
+
+// Parse reads one of:
+//
+//   - a tinted-theming base16 or base24 scheme, flat or under palette:
+//   - kitty.conf, foot.ini or Xresources color lines
+//   - alacritty.toml [colors.primary], [colors.normal] and [colors.bright] tables
+//   - ghostty palette = N=#RRGGBB lines
+//
+// It is a line scanner rather than a YAML or TOML parser: every format above keeps one
+// color per line, and accepting them all through one path keeps the endpoint simple.
+func Parse(data []byte) (*Source, error) {
+	src := &Source{Base: map[string]colors.RGB{}}
+	var ansi [16]*colors.RGB
+	var bg, fg *colors.RGB
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' || line[0] == ';' {
+			continue
+		}
+		if m := sectionPattern.FindStringSubmatch(line); m != nil {
+			section = strings.ToLower(m[1])
+			continue
+		}
+
+		key, value, ok := splitKeyValue(line)
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "name", "scheme":
+			src.Name = unquote(value)
+			continue
+		case "author":
+			src.Author = unquote(value)
+			continue
+		}
+
+		if key == "palette" {
+			// ghostty: palette = 4=#89b4fa
+			index, rest, found := strings.Cut(value, "=")
+			n, err := strconv.Atoi(strings.TrimSpace(index))
+			if !found || err != nil || n < 0 || n > 15 {
+				continue
+			}
+			if c, ok := parseValue(rest); ok {
+				ansi[n] = &c
+			}
+			continue
+		}
+
+		c, ok := parseValue(value)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case basePattern.MatchString(key):
+			src.Base[key] = c
+		case key == "background":
+			if section == "" || strings.HasSuffix(section, "primary") || section == "colors" {
+				bg = &c
+			}
+		case key == "foreground":
+			if section == "" || strings.HasSuffix(section, "primary") || section == "colors" {
+				fg = &c
+			}
+		default:
+			if slot, ok := ansiSlots[key]; ok {
+				switch {
+				case strings.HasSuffix(section, "normal"):
+					ansi[slot] = &c
+				case strings.HasSuffix(section, "bright"):
+					ansi[slot+8] = &c
+				}
+				continue
+			}
+			if m := indexedPattern.FindStringSubmatch(key); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				switch {
+				case m[1] == "color" && n < 16:
+					ansi[n] = &c
+				case m[1] == "regular" && n < 8:
+					ansi[n] = &c
+				case m[1] == "bright" && n < 8:
+					ansi[n+8] = &c
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(src.Base) > 0 {
+		return src, src.fromBase()
+	}
+
+	if bg == nil || fg == nil {
+		return nil, fmt.Errorf("%w: no background and foreground", ErrUnrecognized)
+	}
+	for i := range 8 {
+		if ansi[i] == nil {
+			return nil, fmt.Errorf("%w: color %d is missing", ErrUnrecognized, i)
+		}
+	}
+	src.Format = SourceTerminal
+	src.Background, src.Foreground = *bg, *fg
+	for i := range 16 {
+		switch {
+		case ansi[i] != nil:
+			src.ANSI[i] = *ansi[i]
+		default:
+			src.ANSI[i] = *ansi[i-8]
+		}
+	}
+	src.Base = nil
+	return src, nil
+}
+
+// base16ANSI is the base16-shell assignment of scheme slots to terminal colors; base24
+// schemes replace the bright colors with base12 through base17.
+var base16ANSI = [16]string{
+	"base00", "base08", "base0b", "base0a", "base0d", "base0e", "base0c", "base05",
+	"base03", "base08", "base0b", "base0a", "base0d", "base0e", "base0c", "base07",
+}
+
+var base24Bright = map[int]string{9: "base12", 10: "base14", 11: "base13", 12: "base16", 13: "base17", 14: "base15"}
+
+func (src *Source) fromBase() error {
+	for i := range 16 {
+		key := fmt.Sprintf("base0%x", i)
+		if _, ok := src.Base[key]; !ok {
+			return fmt.Errorf("%w: %s is missing", ErrUnrecognized, key)
+		}
+	}
+
+	src.Format = SourceBase16
+	_, hasBase24 := src.Base["base10"]
+	if hasBase24 {
+		for i := 0x10; i <= 0x17; i++ {
+			key := fmt.Sprintf("base%x", i)
+			if _, ok := src.Base[key]; !ok {
+				return fmt.Errorf("%w: base24 scheme is missing %s", ErrUnrecognized, key)
+			}
+		}
+		src.Format = SourceBase24
+	}
+
+	for i, key := range base16ANSI {
+		src.ANSI[i] = src.Base[key]
+		if bright, ok := base24Bright[i]; ok && hasBase24 {
+			src.ANSI[i] = src.Base[bright]
+		}
+	}
+	src.Background = src.Base["base00"]
+	src.Foreground = src.Base["base05"]
+	return nil
+}
+
+// splitKeyValue accepts "key: value", "key = value", "key=value" and "key value", and
+// strips Xresources prefixes like "*." and "URxvt*" from the key.
+func splitKeyValue(line string) (string, string, bool) {
+	i := strings.IndexAny(line, ":= \t")
+	if i <= 0 {
+		return "", "", false
+	}
+	key := strings.ToLower(strings.TrimSpace(line[:i]))
+	value := strings.TrimSpace(strings.TrimLeft(line[i:], ":= \t"))
+	if j := strings.LastIndexAny(key, ".*"); j >= 0 {
+		key = key[j+1:]
+	}
+	key = strings.Trim(key, `"'`)
+	return key, value, key != ""
+}
+
+func parseValue(value string) (colors.RGB, bool) {
+	m := hexValuePattern.FindStringSubmatch(value)
+	if m == nil {
+		return colors.RGB{}, false
+	}
+	return colors.ParseHex("#" + m[1])
+}
+
+func unquote(value string) string {
+	if i := strings.Index(value, " #"); i >= 0 && !strings.HasPrefix(value, `"`) {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return strings.Trim(value, `'"`)
+}
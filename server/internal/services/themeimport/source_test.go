@@ -0,0 +1,89 @@
+package themeimport
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		file       string
+		format     string
+		name       string
+		background string
+		foreground string
+		red        string
+		brightRed  string
+	}{
+		{"mocha.yaml", SourceBase16, "Catppuccin Mocha", "#1E1E2E", "#CDD6F4", "#F38BA8", "#F38BA8"},
+		{"legacy-light.yaml", SourceBase16, "One Light", "#FAFAFA", "#383A42", "#CA1243", "#CA1243"},
+		{"kitty.conf", SourceTerminal, "", "#1A1B26", "#C0CAF5", "#F7768E", "#FF899D"},
+		{"alacritty.toml", SourceTerminal, "", "#282828", "#EBDBB2", "#CC241D", "#FB4934"},
+		{"foot.ini", SourceTerminal, "", "#1E1E2E", "#CDD6F4", "#F38BA8", "#F38BA8"},
+		{"Xresources", SourceTerminal, "", "#FDF6E3", "#657B83", "#DC322F", "#DC322F"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			src, err := Parse(readFixture(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.Format != tt.format || src.Name != tt.name {
+				t.Fatalf("got format %q name %q", src.Format, src.Name)
+			}
+			if src.Background.Hex() != tt.background || src.Foreground.Hex() != tt.foreground {
+				t.Fatalf("got background %s foreground %s", src.Background.Hex(), src.Foreground.Hex())
+			}
+			if src.ANSI[1].Hex() != tt.red || src.ANSI[9].Hex() != tt.brightRed {
+				t.Fatalf("got red %s bright red %s", src.ANSI[1].Hex(), src.ANSI[9].Hex())
+			}
+		})
+	}
+}
+
+func TestParseBase24UsesItsBrightColors(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("system: \"base24\"\nname: \"Test\"\npalette:\n")
+	for i := 0; i <= 0x17; i++ {
+		fmt.Fprintf(&b, "  base%02X: \"#0000%02x\"\n", i, i)
+	}
+
+	src, err := Parse([]byte(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Format != SourceBase24 {
+		t.Fatalf("expected base24, got %s", src.Format)
+	}
+	if src.ANSI[9].Hex() != "#000012" || src.ANSI[12].Hex() != "#000016" {
+		t.Fatalf("expected base12/base16 brights, got %s %s", src.ANSI[9].Hex(), src.ANSI[12].Hex())
+	}
+}
+
+func TestParseRejectsIncompleteInput(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":           "",
+		"prose":           "hello world",
+		"partial base16":  "base00: \"#000000\"\nbase01: \"#111111\"",
+		"no ansi colors":  "background #000000\nforeground #ffffff",
+		"missing color 5": "background #000000\nforeground #ffffff\ncolor0 #000000\ncolor1 #ff0000\ncolor2 #00ff00\ncolor3 #ffff00\ncolor4 #0000ff\ncolor6 #00ffff\ncolor7 #ffffff",
+	} {
+		if _, err := Parse([]byte(input)); !errors.Is(err, ErrUnrecognized) {
+			t.Fatalf("%s: expected ErrUnrecognized, got %v", name, err)
+		}
+	}
+}
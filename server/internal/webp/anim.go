@@ -0,0 +1,306 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+
+	xwebp "golang.org/x/image/webp"
+)
+
+const (
+	vp8xAnimation = 1 << 1
+	vp8xAlpha     = 1 << 4
+
+	anmfDispose = 1 << 0
+	anmfNoBlend = 1 << 1
+
+	// maxDuration is the longest a frame can be shown for: 24 bits of milliseconds.
+	maxDuration = 1<<24 - 1
+)
+
+// ErrNotAnimated is returned by AnimationConfig and DecodeAll for a still WebP.
+var ErrNotAnimated = errors.New("webp: not an animated image")
+
+// Animation is a sequence of frames that each cover the whole canvas.
+type Animation struct {
+	Frames    []image.Image
+	Durations []int // milliseconds each frame is shown for
+	LoopCount int   // 0 loops forever
+}
+
+// EncodeAll writes a as an animated lossless WebP. Frames must share one size. Each is
+// stored as the even-aligned rectangle that changed since the one before, and a frame
+// identical to the one before extends its duration instead.
+func EncodeAll(w io.Writer, a *Animation) error {
+	if len(a.Frames) == 0 || len(a.Durations) != len(a.Frames) {
+		return errors.New("webp: animation needs one duration per frame")
+	}
+	canvas := a.Frames[0].Bounds().Size()
+
+	var (
+		frames   bytes.Buffer
+		flags    byte = vp8xAnimation
+		prev     *image.NRGBA
+		header   []byte // the last frame's ANMF header, while its duration may still grow
+		duration int
+		pending  []byte
+	)
+	flush := func() {
+		if header == nil {
+			return
+		}
+		putUint24(header[12:], min(duration, maxDuration))
+		writeChunk(&frames, "ANMF", append(header, pending...))
+	}
+	for i, img := range a.Frames {
+		if img.Bounds().Size() != canvas {
+			return fmt.Errorf("webp: frame %d is %v, want %v", i, img.Bounds().Size(), canvas)
+		}
+		cur := toNRGBA(img)
+		rect := cur.Rect
+		if prev != nil {
+			rect = changedRect(prev, cur)
+			if rect.Empty() {
+				duration += a.Durations[i]
+				continue
+			}
+		}
+		prev = cur
+
+		data, hasAlpha, err := encodeVP8L(cur.SubImage(rect))
+		if err != nil {
+			return err
+		}
+		if hasAlpha {
+			flags |= vp8xAlpha
+		}
+		flush()
+		header = make([]byte, 16)
+		putUint24(header[0:], rect.Min.X/2)
+		putUint24(header[3:], rect.Min.Y/2)
+		putUint24(header[6:], rect.Dx()-1)
+		putUint24(header[9:], rect.Dy()-1)
+		header[15] = anmfNoBlend
+		duration = a.Durations[i]
+		var chunk bytes.Buffer
+		writeChunk(&chunk, "VP8L", data)
+		pending = chunk.Bytes()
+	}
+	flush()
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	vp8x := make([]byte, 10)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], canvas.X-1)
+	putUint24(vp8x[7:], canvas.Y-1)
+	writeChunk(&body, "VP8X", vp8x)
+	anim := make([]byte, 6)
+	binary.LittleEndian.PutUint16(anim[4:], uint16(a.LoopCount))
+	writeChunk(&body, "ANIM", anim)
+	body.Write(frames.Bytes())
+	return writeRIFF(w, body.Bytes())
+}
+
+// changedRect bounds the pixels that differ between two frames, its corner moved to
+// even coordinates since that is all a frame offset can express.
+func changedRect(prev, cur *image.NRGBA) image.Rectangle {
+	b := cur.Rect
+	r := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := cur.Pix[y*cur.Stride : y*cur.Stride+4*b.Dx()]
+		prevRow := prev.Pix[y*prev.Stride : y*prev.Stride+4*b.Dx()]
+		if bytes.Equal(row, prevRow) {
+			continue
+		}
+		first, last := 0, b.Dx()-1
+		for bytes.Equal(row[4*first:4*first+4], prevRow[4*first:4*first+4]) {
+			first++
+		}
+		for bytes.Equal(row[4*last:4*last+4], prevRow[4*last:4*last+4]) {
+			last--
+		}
+		r.Min.X = min(r.Min.X, first)
+		r.Max.X = max(r.Max.X, last+1)
+		r.Min.Y = min(r.Min.Y, y)
+		r.Max.Y = y + 1
+	}
+	if r.Empty() {
+		return image.Rectangle{}
+	}
+	r.Min.X &^= 1
+	r.Min.Y &^= 1
+	return r
+}
+
+// anmfFrame is one frame's placement and its image chunks.
+type anmfFrame struct {
+	rect     image.Rectangle
+	duration int
+	flags    byte
+	data     []byte
+}
+
+// AnimationConfig reports an animated WebP's canvas and how many frames it has,
+// without decoding any of them.
+func AnimationConfig(data []byte) (image.Config, int, error) {
+	canvas, _, frames, err := parseAnimation(data)
+	if err != nil {
+		return image.Config{}, 0, err
+	}
+	return image.Config{Width: canvas.X, Height: canvas.Y}, len(frames), nil
+}
+
+// DecodeAll decodes the first maxFrames frames of an animated WebP, each composited
+// onto the canvas as a player would show it.
+func DecodeAll(data []byte, maxFrames int) (*Animation, error) {
+	size, loops, frames, err := parseAnimation(data)
+	if err != nil {
+		return nil, err
+	}
+	frames = frames[:min(len(frames), maxFrames)]
+
+	canvas := image.NewNRGBA(image.Rectangle{Max: size})
+	out := &Animation{LoopCount: loops}
+	var dispose image.Rectangle
+	for i, f := range frames {
+		draw.Draw(canvas, dispose, image.Transparent, image.Point{}, draw.Src)
+		img, err := decodeFrame(f)
+		if err != nil {
+			return nil, fmt.Errorf("webp: frame %d: %w", i, err)
+		}
+		if img.Bounds().Size() != f.rect.Size() {
+			return nil, fmt.Errorf("webp: frame %d does not match its placement", i)
+		}
+		op := draw.Over
+		if f.flags&anmfNoBlend != 0 {
+			op = draw.Src
+		}
+		draw.Draw(canvas, f.rect, img, img.Bounds().Min, op)
+
+		frame := image.NewNRGBA(canvas.Rect)
+		copy(frame.Pix, canvas.Pix)
+		out.Frames = append(out.Frames, frame)
+		out.Durations = append(out.Durations, f.duration)
+
+		dispose = image.Rectangle{}
+		if f.flags&anmfDispose != 0 {
+			dispose = f.rect
+		}
+	}
+	return out, nil
+}
+
+// decodeFrame decodes one frame's chunks by giving them a file of their own.
+func decodeFrame(f anmfFrame) (image.Image, error) {
+	var flags byte
+	for chunks := f.data; len(chunks) >= 8; {
+		id, payload, rest, ok := nextChunk(chunks)
+		if !ok {
+			break
+		}
+		if id == "ALPH" && len(payload) > 0 {
+			flags |= vp8xAlpha
+		}
+		chunks = rest
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	vp8x := make([]byte, 10)
+	vp8x[0] = flags
+	putUint24(vp8x[4:], f.rect.Dx()-1)
+	putUint24(vp8x[7:], f.rect.Dy()-1)
+	writeChunk(&body, "VP8X", vp8x)
+	body.Write(f.data)
+
+	var file bytes.Buffer
+	if err := writeRIFF(&file, body.Bytes()); err != nil {
+		return nil, err
+	}
+	return xwebp.Decode(&file)
+}
+
+func parseAnimation(data []byte) (image.Point, int, []anmfFrame, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return image.Point{}, 0, nil, errors.New("webp: not a WebP file")
+	}
+	if size := int(binary.LittleEndian.Uint32(data[4:])); size+8 < len(data) {
+		data = data[:size+8]
+	}
+
+	var (
+		canvas   image.Point
+		loops    int
+		animated bool
+		frames   []anmfFrame
+	)
+	for chunks := data[12:]; len(chunks) > 0; {
+		id, payload, rest, ok := nextChunk(chunks)
+		if !ok {
+			return image.Point{}, 0, nil, errors.New("webp: truncated chunk")
+		}
+		chunks = rest
+		switch id {
+		case "VP8X":
+			if len(payload) < 10 {
+				return image.Point{}, 0, nil, errors.New("webp: short VP8X chunk")
+			}
+			animated = payload[0]&vp8xAnimation != 0
+			canvas = image.Pt(uint24(payload[4:])+1, uint24(payload[7:])+1)
+			if canvas.X > maxDimension || canvas.Y > maxDimension {
+				return image.Point{}, 0, nil, errors.New("webp: canvas dimensions out of range")
+			}
+		case "ANIM":
+			if len(payload) < 6 {
+				return image.Point{}, 0, nil, errors.New("webp: short ANIM chunk")
+			}
+			loops = int(binary.LittleEndian.Uint16(payload[4:]))
+		case "ANMF":
+			if len(payload) < 16 {
+				return image.Point{}, 0, nil, errors.New("webp: short ANMF chunk")
+			}
+			x, y := 2*uint24(payload[0:]), 2*uint24(payload[3:])
+			rect := image.Rect(x, y, x+uint24(payload[6:])+1, y+uint24(payload[9:])+1)
+			if !rect.In(image.Rectangle{Max: canvas}) {
+				return image.Point{}, 0, nil, errors.New("webp: frame outside the canvas")
+			}
+			frames = append(frames, anmfFrame{
+				rect:     rect,
+				duration: uint24(payload[12:]),
+				flags:    payload[15],
+				data:     payload[16:],
+			})
+		}
+	}
+	if !animated || len(frames) == 0 {
+		return image.Point{}, 0, nil, ErrNotAnimated
+	}
+	return canvas, loops, frames, nil
+}
+
+// nextChunk splits the first RIFF chunk off data.
+func nextChunk(data []byte) (id string, payload, rest []byte, ok bool) {
+	if len(data) < 8 {
+		return "", nil, nil, false
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if size < 0 || size > len(data)-8 {
+		return "", nil, nil, false
+	}
+	end := 8 + size + size&1
+	return string(data[:4]), data[8 : 8+size], data[min(end, len(data)):], true
+}
+
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
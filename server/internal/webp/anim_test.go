@@ -0,0 +1,85 @@
+package webp
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestEncodeAllRoundTripsChangedRegions(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for i := range base.Pix {
+		base.Pix[i] = 0x40
+	}
+	var frames []image.Image
+	for i := range 4 {
+		frame := image.NewNRGBA(base.Rect)
+		copy(frame.Pix, base.Pix)
+		// A translucent square that moves, then changes color where it stopped.
+		x := 5 + 10*min(i, 2)
+		for y := 7; y < 12; y++ {
+			for dx := range 5 {
+				frame.SetNRGBA(x+dx, y, color.NRGBA{0xff, uint8(40 * i), 0, 0x80})
+			}
+		}
+		frames = append(frames, frame)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeAll(&buf, &Animation{Frames: frames, Durations: []int{100, 100, 100, 250}, LoopCount: 3}); err != nil {
+		t.Fatal(err)
+	}
+	config, count, err := AnimationConfig(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 64 || config.Height != 48 || count != 4 {
+		t.Fatalf("expected 4 frames on a 64x48 canvas, got %+v and %d", config, count)
+	}
+
+	decoded, err := DecodeAll(buf.Bytes(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.LoopCount != 3 || !slices.Equal(decoded.Durations, []int{100, 100, 100, 250}) {
+		t.Fatalf("unexpected timing: loops %d, durations %v", decoded.LoopCount, decoded.Durations)
+	}
+	for i, frame := range decoded.Frames {
+		want := frames[i].(*image.NRGBA)
+		if got := frame.(*image.NRGBA); !bytes.Equal(got.Pix, want.Pix) {
+			t.Fatalf("frame %d differs after a round trip", i)
+		}
+	}
+
+	if truncated, err := DecodeAll(buf.Bytes(), 2); err != nil || len(truncated.Frames) != 2 {
+		t.Fatalf("expected decoding to stop at 2 frames, got %v", err)
+	}
+}
+
+func TestEncodeAllMergesRepeatedFrames(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	var buf bytes.Buffer
+	if err := EncodeAll(&buf, &Animation{Frames: []image.Image{img, img, img}, Durations: []int{50, 60, 70}}); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeAll(buf.Bytes(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(decoded.Durations, []int{180}) {
+		t.Fatalf("expected one frame shown for 180ms, got %v", decoded.Durations)
+	}
+}
+
+func TestAnimationConfigRejectsStillImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AnimationConfig(buf.Bytes()); !errors.Is(err, ErrNotAnimated) {
+		t.Fatalf("expected ErrNotAnimated, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package webp writes lossless WebP (VP8L) images. It covers what preview renditions
+// need and no more: the subtract-green transform, LZ77 backward references and a
+// single set of prefix codes, without a color cache or predictors. Animations are
+// written from those frames, and read by handing each frame to x/image/webp.
+// https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+const (
+	maxDimension = 1 << 14
+
+	literalCodes  = 256
+	lengthCodes   = 24
+	distanceCodes = 40
+
+	// distanceMapCodes are the short codes for nearby pixels; plain distances follow.
+	distanceMapCodes = 120
+
+	minMatch    = 3
+	maxMatch    = 4096
+	hashBits    = 16
+	chainLength = 32
+	// maxDistance is the farthest reference the 40 distance prefixes can express.
+	maxDistance = 1<<20 - distanceMapCodes
+)
+
+// Encode writes img as a lossless WebP.
+func Encode(w io.Writer, img image.Image) error {
+	data, _, err := encodeVP8L(img)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeChunk(&body, "VP8L", data)
+	return writeRIFF(w, body.Bytes())
+}
+
+// encodeVP8L writes img as a VP8L bitstream, the payload of a VP8L chunk, and reports
+// whether any pixel is less than opaque.
+func encodeVP8L(img image.Image) ([]byte, bool, error) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return nil, false, errors.New("webp: image dimensions out of range")
+	}
+	rgba := toNRGBA(img)
+
+	argb := make([]uint32, width*height)
+	hasAlpha := false
+	for i := range argb {
+		p := rgba.Pix[4*i : 4*i+4]
+		r, g, bl, a := uint32(p[0]), uint32(p[1]), uint32(p[2]), uint32(p[3])
+		if a != 0xff {
+			hasAlpha = true
+		}
+		// Subtract green: red and blue track green closely in most images, so their
+		// differences cluster near zero and code shorter.
+		r, bl = (r-g)&0xff, (bl-g)&0xff
+		argb[i] = a<<24 | r<<16 | g<<8 | bl
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3)
+
+	bw.write(1, 1) // transform present
+	bw.write(2, 2) // subtract green
+	bw.write(0, 1) // no more transforms
+
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // one prefix code group for the whole image
+
+	encodePixels(bw, argb, width)
+	return bw.bytes(), hasAlpha, nil
+}
+
+// toNRGBA returns img as an NRGBA whose rows start at offset zero, copying it unless
+// it already is one.
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	if rgba, ok := img.(*image.NRGBA); ok && rgba.Rect.Min == (image.Point{}) && rgba.Stride == 4*b.Dx() {
+		return rgba
+	}
+	rgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
+
+// writeChunk appends a RIFF chunk, padded to an even length.
+func writeChunk(buf *bytes.Buffer, fourCC string, data []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(data)))
+	buf.WriteString(fourCC)
+	buf.Write(size[:])
+	buf.Write(data)
+	if len(data)&1 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+// writeRIFF writes body, which starts with the form type, as a RIFF file.
+func writeRIFF(w io.Writer, body []byte) error {
+	header := make([]byte, 8)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(body)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// token is one literal pixel or one backward reference.
+type token struct {
+	length   int // 0 for a literal
+	argb     uint32
+	distCode int
+}
+
+func encodePixels(bw *bitWriter, argb []uint32, width int) {
+	tokens := findMatches(argb, width)
+
+	var freq [5][]uint32
+	freq[0] = make([]uint32, literalCodes+lengthCodes)
+	for i := 1; i < 4; i++ {
+		freq[i] = make([]uint32, literalCodes)
+	}
+	freq[4] = make([]uint32, distanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			freq[0][t.argb>>8&0xff]++
+			freq[1][t.argb>>16&0xff]++
+			freq[2][t.argb&0xff]++
+			freq[3][t.argb>>24]++
+			continue
+		}
+		lp, _, _ := prefixEncode(t.length)
+		dp, _, _ := prefixEncode(t.distCode)
+		freq[0][literalCodes+lp]++
+		freq[4][dp]++
+	}
+
+	var codes [5]prefixCode
+	for i := range codes {
+		codes[i] = buildPrefixCode(freq[i], 15)
+		codes[i].writeTo(bw)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].put(bw, int(t.argb>>8&0xff))
+			codes[1].put(bw, int(t.argb>>16&0xff))
+			codes[2].put(bw, int(t.argb&0xff))
+			codes[3].put(bw, int(t.argb>>24))
+			continue
+		}
+		lp, lbits, lextra := prefixEncode(t.length)
+		codes[0].put(bw, literalCodes+lp)
+		bw.write(lextra, lbits)
+		dp, dbits, dextra := prefixEncode(t.distCode)
+		codes[4].put(bw, dp)
+		bw.write(dextra, dbits)
+	}
+}
+
+// findMatches greedily replaces runs that repeat earlier pixels with backward
+// references, trying the pixel to the left and the one above before the hash chain
+// since flat fills and repeated rows make up most of a rendered card.
+func findMatches(argb []uint32, width int) []token {
+	n := len(argb)
+	head := make([]int32, 1<<hashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, n)
+
+	hash := func(i int) uint32 {
+		h := argb[i]*0x1e35a7bd ^ argb[i+1]*0x9e3779b1 ^ argb[i+2]*0x85ebca6b
+		return h >> (32 - hashBits)
+	}
+	insert := func(i int) {
+		if i+minMatch > n {
+			return
+		}
+		h := hash(i)
+		prev[i] = head[h]
+		head[h] = int32(i)
+	}
+	matchLen := func(i, j int) int {
+		limit := min(maxMatch, n-i)
+		l := 0
+		for l < limit && argb[i+l] == argb[j+l] {
+			l++
+		}
+		return l
+	}
+
+	var tokens []token
+	for i := 0; i < n; {
+		bestLen, bestDist := 0, 0
+		if i+minMatch <= n {
+			for _, d := range [2]int{1, width} {
+				if d <= i {
+					if l := matchLen(i, i-d); l > bestLen {
+						bestLen, bestDist = l, d
+					}
+				}
+			}
+			for j, steps := head[hash(i)], 0; j >= 0 && steps < chainLength && bestLen < maxMatch; j, steps = prev[j], steps+1 {
+				if i-int(j) > maxDistance {
+					break
+				}
+				if l := matchLen(i, int(j)); l > bestLen {
+					bestLen, bestDist = l, i-int(j)
+				}
+			}
+		}
+
+		if bestLen < minMatch {
+			tokens = append(tokens, token{argb: argb[i]})
+			insert(i)
+			i++
+			continue
+		}
+
+		tokens = append(tokens, token{length: bestLen, distCode: distanceCode(bestDist, width)})
+		for k := i; k < i+bestLen; k++ {
+			insert(k)
+		}
+		i += bestLen
+	}
+	return tokens
+}
+
+// distanceCode maps a pixel distance to its code: the two neighbours that matter most
+// have short codes in the spec's distance map, everything else is offset past it.
+func distanceCode(dist, width int) int {
+	switch dist {
+	case width:
+		return 1
+	case 1:
+		return 2
+	}
+	return dist + distanceMapCodes
+}
+
+// prefixEncode splits a length or distance code into its prefix symbol and extra bits.
+func prefixEncode(value int) (symbol int, extraBits uint, extra uint32) {
+	v := value - 1
+	if v < 4 {
+		return v, 0, 0
+	}
+	highest := 0
+	for 1<<(highest+1) <= v {
+		highest++
+	}
+	second := v >> (highest - 1) & 1
+	extraBits = uint(highest - 1)
+	return 2*highest + second, extraBits, uint32(v) & (1<<extraBits - 1)
+}
@@ -0,0 +1,102 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	xwebp "golang.org/x/image/webp"
+)
+
+func roundTrip(t *testing.T, img *image.NRGBA) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := xwebp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("bounds %v, want %v", decoded.Bounds(), img.Bounds())
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			want := img.NRGBAAt(x, y)
+			got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+			if want.A == 0 {
+				if got.A != 0 {
+					t.Fatalf("pixel %d,%d: got %v, want transparent", x, y, got)
+				}
+				continue
+			}
+			if got != want {
+				t.Fatalf("pixel %d,%d: got %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodeRoundTripsFlatCard(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 320, 180))
+	for y := 0; y < 180; y++ {
+		for x := 0; x < 320; x++ {
+			c := color.NRGBA{0x1e, 0x1e, 0x2e, 0xff}
+			if x > 20 && x < 300 && y > 40 && y < 60 {
+				c = color.NRGBA{0xcb, 0xa6, 0xf7, 0xff}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	roundTrip(t, img)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 2048 {
+		t.Fatalf("expected a flat card to compress well, got %d bytes", buf.Len())
+	}
+}
+
+func TestEncodeRoundTripsNoiseAndAlpha(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, 97, 61))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	roundTrip(t, img)
+}
+
+func TestEncodeRoundTripsTinyImages(t *testing.T) {
+	for _, size := range []image.Point{{1, 1}, {2, 1}, {1, 5}, {3, 3}} {
+		img := image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		for i := range img.Pix {
+			img.Pix[i] = 0xff
+		}
+		img.SetNRGBA(0, 0, color.NRGBA{1, 2, 3, 255})
+		roundTrip(t, img)
+	}
+}
+
+func TestPrefixEncode(t *testing.T) {
+	for value := 1; value < 1<<20; value += 7 {
+		symbol, bits, extra := prefixEncode(value)
+		decoded := symbol + 1
+		if symbol >= 4 {
+			extraBits := (symbol - 2) >> 1
+			offset := (2 + symbol&1) << extraBits
+			if uint(extraBits) != bits {
+				t.Fatalf("value %d: %d extra bits, want %d", value, bits, extraBits)
+			}
+			decoded = offset + int(extra) + 1
+		}
+		if decoded != value {
+			t.Fatalf("value %d decodes as %d", value, decoded)
+		}
+	}
+}
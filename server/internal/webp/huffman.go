@@ -0,0 +1,271 @@
+package webp
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// bitWriter packs values least significant bit first, as VP8L reads them.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// codeLengthOrder is the order the code length code's own lengths are sent in.
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// prefixCode is a canonical Huffman code over one alphabet. lengths are what the
+// decoder is told; codes and bits are what put writes, which differ for a code with a
+// single symbol since the decoder reads no bits for it.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+	bits    []uint8
+	simple  []int
+}
+
+func (c *prefixCode) put(w *bitWriter, symbol int) {
+	w.write(uint32(c.codes[symbol]), uint(c.bits[symbol]))
+}
+
+func buildPrefixCode(freq []uint32, maxBits int) prefixCode {
+	var used []int
+	for symbol, f := range freq {
+		if f > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	c := prefixCode{
+		lengths: make([]uint8, len(freq)),
+		codes:   make([]uint16, len(freq)),
+		bits:    make([]uint8, len(freq)),
+	}
+
+	// One or two symbols that fit in a byte go out as a simple code: the symbols
+	// themselves, coded in zero bits or one bit each in the order sent.
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		c.simple = used
+		if len(used) == 2 {
+			c.bits[used[0]], c.bits[used[1]] = 1, 1
+			c.codes[used[1]] = 1
+		}
+		return c
+	}
+
+	if len(used) == 1 {
+		c.lengths[used[0]] = 1
+		return c
+	}
+
+	c.lengths = huffmanLengths(freq, maxBits)
+	c.assignCodes()
+	return c
+}
+
+// assignCodes numbers symbols canonically, shorter codes first and by symbol within a
+// length, then reverses each code since the decoder walks it from the first bit read.
+func (c *prefixCode) assignCodes() {
+	var count [16]uint16
+	for _, l := range c.lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [16]uint16
+	code := uint16(0)
+	for l := 1; l < len(next); l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	for symbol, l := range c.lengths {
+		if l == 0 {
+			continue
+		}
+		c.codes[symbol] = reverse(next[l], l)
+		c.bits[symbol] = l
+		next[l]++
+	}
+}
+
+func reverse(code uint16, n uint8) uint16 {
+	var out uint16
+	for i := uint8(0); i < n; i++ {
+		out = out<<1 | code&1
+		code >>= 1
+	}
+	return out
+}
+
+func (c *prefixCode) writeTo(w *bitWriter) {
+	if c.simple != nil {
+		w.write(1, 1)
+		w.write(uint32(len(c.simple)-1), 1)
+		if first := c.simple[0]; first < 2 {
+			w.write(0, 1)
+			w.write(uint32(first), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(first), 8)
+		}
+		if len(c.simple) == 2 {
+			w.write(uint32(c.simple[1]), 8)
+		}
+		return
+	}
+	w.write(0, 1)
+
+	// Code lengths are themselves prefix coded: 0-15 literally, 17 and 18 for runs of
+	// zeros, which unused stretches of the 280-symbol green alphabet are full of.
+	type clToken struct {
+		symbol    int
+		extra     uint32
+		extraBits uint
+	}
+	var tokens []clToken
+	clFreq := make([]uint32, len(codeLengthOrder))
+	for i := 0; i < len(c.lengths); {
+		l := c.lengths[i]
+		run := 1
+		for i+run < len(c.lengths) && c.lengths[i+run] == l {
+			run++
+		}
+		if l != 0 || run < 3 {
+			tokens = append(tokens, clToken{symbol: int(l)})
+			clFreq[l]++
+			i++
+			continue
+		}
+		switch {
+		case run >= 11:
+			run = min(run, 138)
+			tokens = append(tokens, clToken{symbol: 18, extra: uint32(run - 11), extraBits: 7})
+			clFreq[18]++
+		default:
+			run = min(run, 10)
+			tokens = append(tokens, clToken{symbol: 17, extra: uint32(run - 3), extraBits: 3})
+			clFreq[17]++
+		}
+		i += run
+	}
+
+	// The code length code has no simple form, so one or two symbols are sent as
+	// length 1; the decoder then reads no bits or one bit, as put already writes.
+	cl := buildPrefixCode(clFreq, 7)
+	for _, symbol := range cl.simple {
+		cl.lengths[symbol] = 1
+	}
+
+	n := len(codeLengthOrder)
+	for n > 4 && cl.lengths[codeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	w.write(uint32(n-4), 4)
+	for _, symbol := range codeLengthOrder[:n] {
+		w.write(uint32(cl.lengths[symbol]), 3)
+	}
+	w.write(0, 1) // code lengths run to the end of the alphabet
+
+	for _, t := range tokens {
+		cl.put(w, t.symbol)
+		w.write(t.extra, t.extraBits)
+	}
+}
+
+type hnode struct {
+	weight uint64
+	depth  int
+	index  int
+}
+
+type hqueue []*hnode
+
+func (q hqueue) Len() int { return len(q) }
+func (q hqueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight < q[j].weight
+	}
+	return q[i].depth < q[j].depth
+}
+func (q hqueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *hqueue) Push(x any)   { *q = append(*q, x.(*hnode)) }
+func (q *hqueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// huffmanLengths builds optimal code lengths for two or more used symbols, flattening
+// the frequencies until no code is longer than maxBits.
+func huffmanLengths(freq []uint32, maxBits int) []uint8 {
+	weights := slices.Clone(freq)
+	for {
+		lengths := huffmanDepths(weights)
+		if int(slices.Max(lengths)) <= maxBits {
+			return lengths
+		}
+		for i, f := range weights {
+			if f > 0 {
+				weights[i] = f/2 + 1
+			}
+		}
+	}
+}
+
+func huffmanDepths(freq []uint32) []uint8 {
+	parent := make([]int, 0, 2*len(freq))
+	q := hqueue{}
+	for _, f := range freq {
+		if f == 0 {
+			continue
+		}
+		q = append(q, &hnode{weight: uint64(f), index: len(parent)})
+		parent = append(parent, -1)
+	}
+	heap.Init(&q)
+	for q.Len() > 1 {
+		a := heap.Pop(&q).(*hnode)
+		b := heap.Pop(&q).(*hnode)
+		merged := &hnode{weight: a.weight + b.weight, depth: max(a.depth, b.depth) + 1, index: len(parent)}
+		parent = append(parent, -1)
+		parent[a.index], parent[b.index] = merged.index, merged.index
+		heap.Push(&q, merged)
+	}
+
+	lengths := make([]uint8, len(freq))
+	leaf := 0
+	for symbol, f := range freq {
+		if f == 0 {
+			continue
+		}
+		depth := 0
+		for n := leaf; parent[n] != -1; n = parent[n] {
+			depth++
+		}
+		lengths[symbol] = uint8(depth)
+		leaf++
+	}
+	return lengths
+}